@@ -0,0 +1,297 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/account/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	accountModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/account"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// ReconciliationController provides methods to reconcile user accounts against bank statements.
+type ReconciliationController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	startReconciliation(ctx *gin.Context)
+	markTransactions(ctx *gin.Context)
+	completeReconciliation(ctx *gin.Context)
+	cancelReconciliation(ctx *gin.Context)
+	getReconciliation(ctx *gin.Context)
+	getReconciliations(ctx *gin.Context)
+}
+
+// reconciliationController.
+type reconciliationController struct {
+	service service.ReconciliationService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewReconciliationController create new ReconciliationController
+func NewReconciliationController(ser service.ReconciliationService, log log.Logger,
+	auth *security.Authentication) ReconciliationController {
+	return &reconciliationController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for reconciliation controller.
+func (c *reconciliationController) RegisterRoutes(router *gin.RouterGroup) {
+
+	guarded := router.Group("/users", c.auth.ScopedMiddleware(), c.auth.AuthorizeUser())
+
+	guarded.POST("/:userID/budgets/:budgetID/accounts/:accountID/reconciliations", c.auth.RequireScope(userModel.ScopeAccountsWrite), c.startReconciliation)
+	guarded.GET("/:userID/budgets/:budgetID/accounts/:accountID/reconciliations", c.auth.RequireScope(userModel.ScopeRead), c.getReconciliations)
+	guarded.GET("/:userID/budgets/:budgetID/accounts/:accountID/reconciliations/:reconciliationID", c.auth.RequireScope(userModel.ScopeRead), c.getReconciliation)
+	guarded.PUT("/:userID/budgets/:budgetID/accounts/:accountID/reconciliations/:reconciliationID/transactions", c.auth.RequireScope(userModel.ScopeAccountsWrite), c.markTransactions)
+	guarded.POST("/:userID/budgets/:budgetID/accounts/:accountID/reconciliations/:reconciliationID/complete", c.auth.RequireScope(userModel.ScopeAccountsWrite), c.completeReconciliation)
+	guarded.DELETE("/:userID/budgets/:budgetID/accounts/:accountID/reconciliations/:reconciliationID", c.auth.RequireScope(userModel.ScopeAccountsWrite), c.cancelReconciliation)
+}
+
+// startReconciliation will start reconciliation of account with statement end date and balance.
+func (c *reconciliationController) startReconciliation(ctx *gin.Context) {
+
+	reconciliation := accountModel.Reconciliation{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &reconciliation)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reconciliation.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reconciliation.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reconciliation.AccountID, err = parser.GetUUID("accountID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = reconciliation.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.StartReconciliation(ctx.Request.Context(), &reconciliation)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusCreated, reconciliation)
+}
+
+// markTransactions will tick off transactions as cleared in the reconciliation.
+func (c *reconciliationController) markTransactions(ctx *gin.Context) {
+
+	transactions := accountModel.ReconciliationTransactions{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &transactions)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = transactions.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reconciliation, err := c.parseReconciliation(parser)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.MarkTransactions(ctx.Request.Context(), &reconciliation, &transactions)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, reconciliation)
+}
+
+// completeReconciliation will complete the reconciliation and lock reconciled transactions.
+func (c *reconciliationController) completeReconciliation(ctx *gin.Context) {
+
+	parser := web.NewParser(ctx)
+
+	reconciliation, err := c.parseReconciliation(parser)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.CompleteReconciliation(ctx.Request.Context(), &reconciliation)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, reconciliation)
+}
+
+// cancelReconciliation will discard reconciliation which is in progress.
+func (c *reconciliationController) cancelReconciliation(ctx *gin.Context) {
+
+	reconciliation := accountModel.Reconciliation{}
+	parser := web.NewParser(ctx)
+	var err error
+
+	reconciliation.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reconciliation.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reconciliation.AccountID, err = parser.GetUUID("accountID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reconciliation.ID, err = parser.GetUUID("reconciliationID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.CancelReconciliation(ctx.Request.Context(), &reconciliation)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// getReconciliation will fetch specified reconciliation with current cleared balance and difference.
+func (c *reconciliationController) getReconciliation(ctx *gin.Context) {
+
+	parser := web.NewParser(ctx)
+
+	reconciliation, err := c.parseReconciliation(parser)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetReconciliation(ctx.Request.Context(), &reconciliation)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, reconciliation)
+}
+
+// getReconciliations will fetch all reconciliations of specified account.
+func (c *reconciliationController) getReconciliations(ctx *gin.Context) {
+
+	var reconciliations []accountModel.ReconciliationDTO
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	budgetID, err := parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	accountID, err := parser.GetUUID("accountID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetReconciliations(ctx.Request.Context(), &reconciliations, userID, budgetID, accountID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, reconciliations)
+}
+
+// parseReconciliation will parse user, account and reconciliation IDs from URL params.
+func (c *reconciliationController) parseReconciliation(parser *web.Parser) (accountModel.ReconciliationDTO, error) {
+
+	reconciliation := accountModel.ReconciliationDTO{}
+	var err error
+
+	reconciliation.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		return reconciliation, err
+	}
+
+	reconciliation.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		return reconciliation, err
+	}
+
+	reconciliation.AccountID, err = parser.GetUUID("accountID")
+	if err != nil {
+		return reconciliation, err
+	}
+
+	reconciliation.ID, err = parser.GetUUID("reconciliationID")
+	if err != nil {
+		return reconciliation, err
+	}
+
+	return reconciliation, nil
+}
@@ -0,0 +1,302 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/account/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	accountModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/account"
+	auditModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/audit"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// AccountController service provides methods to update, delete, add, get method for AccountController.
+type AccountController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	addAccount(ctx *gin.Context)
+	updateAccount(ctx *gin.Context)
+	deleteAccount(ctx *gin.Context)
+	getAccounts(ctx *gin.Context)
+	getAccountHistory(ctx *gin.Context)
+	revertAccount(ctx *gin.Context)
+}
+
+// accountController.
+type accountController struct {
+	service service.AccountService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewAccountController create new AccountController
+func NewAccountController(ser service.AccountService, log log.Logger,
+	auth *security.Authentication) AccountController {
+	return &accountController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for account controller.
+func (c *accountController) RegisterRoutes(router *gin.RouterGroup) {
+
+	guarded := router.Group("/users", c.auth.ScopedMiddleware(), c.auth.AuthorizeUser())
+
+	guarded.POST("/:userID/budgets/:budgetID/accounts", c.auth.RequireScope(userModel.ScopeAccountsWrite), c.addAccount)
+	guarded.PUT("/:userID/budgets/:budgetID/accounts/:accountID", c.auth.RequireScope(userModel.ScopeAccountsWrite), c.updateAccount)
+	guarded.DELETE("/:userID/budgets/:budgetID/accounts/:accountID", c.auth.RequireScope(userModel.ScopeAccountsWrite), c.deleteAccount)
+	guarded.GET("/:userID/budgets/:budgetID/accounts", c.auth.RequireScope(userModel.ScopeRead), c.getAccounts)
+	guarded.GET("/:userID/budgets/:budgetID/accounts/:accountID/history", c.auth.RequireScope(userModel.ScopeRead), c.getAccountHistory)
+	guarded.POST("/:userID/budgets/:budgetID/accounts/:accountID/history/:version/revert", c.auth.RequireScope(userModel.ScopeAccountsWrite), c.revertAccount)
+}
+
+// addAccount will add new account for specified user.
+func (c *accountController) addAccount(ctx *gin.Context) {
+
+	account := accountModel.Account{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &account)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	account.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	account.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = account.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.AddAccount(ctx.Request.Context(), &account)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusCreated, nil)
+}
+
+// updateAccount will update specified account.
+func (c *accountController) updateAccount(ctx *gin.Context) {
+
+	account := accountModel.Account{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &account)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	account.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	account.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	account.ID, err = parser.GetUUID("accountID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = account.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.UpdateAccount(ctx.Request.Context(), &account)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// deleteAccount will delete specified account.
+func (c *accountController) deleteAccount(ctx *gin.Context) {
+
+	account := accountModel.Account{}
+	parser := web.NewParser(ctx)
+	var err error
+
+	account.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	account.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	account.ID, err = parser.GetUUID("accountID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.DeleteAccount(ctx.Request.Context(), &account)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// getAccounts will fetch all the accounts for specifed user.
+func (c *accountController) getAccounts(ctx *gin.Context) {
+
+	var accounts []accountModel.AccountDTO
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	budgetID, err := parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetAccounts(ctx.Request.Context(), &accounts, userID, budgetID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, accounts)
+}
+
+// getAccountHistory will fetch all versions of specified account.
+func (c *accountController) getAccountHistory(ctx *gin.Context) {
+
+	var history []auditModel.ChangeLogDTO
+	account := accountModel.Account{}
+	parser := web.NewParser(ctx)
+	var err error
+
+	account.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	account.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	account.ID, err = parser.GetUUID("accountID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetAccountHistory(ctx.Request.Context(), &history, &account)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, history)
+}
+
+// revertAccount will revert specified account to the specified version.
+func (c *accountController) revertAccount(ctx *gin.Context) {
+
+	account := accountModel.Account{}
+	parser := web.NewParser(ctx)
+	var err error
+
+	account.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	account.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	account.ID, err = parser.GetUUID("accountID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	version, err := parser.GetInt("version")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.RevertAccount(ctx.Request.Context(), &account, version)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
@@ -0,0 +1,451 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	auditService "github.com/shaileshhb/budget-planner-go/budgetplanner/audit/service"
+	budgetService "github.com/shaileshhb/budget-planner-go/budgetplanner/budget/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	accountModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/account"
+	auditModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/audit"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
+	envelopModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/envelop"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	userService "github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/util"
+	"gorm.io/gorm"
+)
+
+// ReconciliationService service provides methods to reconcile an account against bank statement.
+type ReconciliationService interface {
+	StartReconciliation(ctx context.Context, reconciliation *accountModel.Reconciliation) error
+	MarkTransactions(ctx context.Context, reconciliation *accountModel.ReconciliationDTO,
+		transactions *accountModel.ReconciliationTransactions) error
+	CompleteReconciliation(ctx context.Context, reconciliation *accountModel.ReconciliationDTO) error
+	CancelReconciliation(ctx context.Context, reconciliation *accountModel.Reconciliation) error
+	GetReconciliation(ctx context.Context, reconciliation *accountModel.ReconciliationDTO) error
+	GetReconciliations(ctx context.Context, reconciliations *[]accountModel.ReconciliationDTO,
+		userID, budgetID, accountID uuid.UUID) error
+}
+
+// reconciliationService
+type reconciliationService struct {
+	db           *gorm.DB
+	repo         repository.Repository
+	auth         *security.Authentication
+	changeLogger auditService.ChangeLogger
+	membership   budgetService.MembershipService
+	preferences  userService.PreferenceService
+}
+
+// NewReconciliationService create new reconciliation service.
+func NewReconciliationService(db *gorm.DB, repo repository.Repository, auth *security.Authentication,
+	changeLogger auditService.ChangeLogger, membership budgetService.MembershipService,
+	preferences userService.PreferenceService) ReconciliationService {
+	return &reconciliationService{
+		db:           db,
+		repo:         repo,
+		auth:         auth,
+		changeLogger: changeLogger,
+		membership:   membership,
+		preferences:  preferences,
+	}
+}
+
+// StartReconciliation will start new reconciliation for specified account.
+// Only one reconciliation can be in progress for an account.
+func (ser *reconciliationService) StartReconciliation(ctx context.Context, reconciliation *accountModel.Reconciliation) error {
+
+	err := ser.membership.Authorize(ctx, reconciliation.UserID, reconciliation.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	err = ser.validateAccountID(ctx, reconciliation.BudgetID, reconciliation.AccountID)
+	if err != nil {
+		return err
+	}
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), accountModel.Reconciliation{},
+		repository.Filter("reconciliations.`account_id` = ? AND reconciliations.`status` = ? AND reconciliations.`deleted_at` IS NULL",
+			reconciliation.AccountID, accountModel.ReconciliationStatusInProgress))
+	if err != nil {
+		return err
+	}
+	if exist {
+		return errors.NewValidationError("Reconciliation already in progress for this account")
+	}
+
+	preference, err := ser.preferences.GetPreference(ctx, reconciliation.UserID)
+	if err != nil {
+		return err
+	}
+
+	// statement covers the whole statement day in timezone of the user.
+	reconciliation.StatementDate.Localize(preference.Location())
+	reconciliation.StatementDate.Time = preference.StartOfDay(reconciliation.StatementDate.Time)
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	reconciliation.Status = accountModel.ReconciliationStatusInProgress
+	reconciliation.ClearedBalance, err = ser.calculateClearedBalance(uow, reconciliation.AccountID,
+		reconciliation.StatementDate.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+	reconciliation.Difference = roundAmount(reconciliation.StatementBalance - reconciliation.ClearedBalance)
+
+	err = ser.repo.Add(uow, reconciliation)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// MarkTransactions will tick off (or untick) transactions of the account as cleared
+// and return the recalculated reconciliation.
+func (ser *reconciliationService) MarkTransactions(ctx context.Context, reconciliation *accountModel.ReconciliationDTO,
+	transactions *accountModel.ReconciliationTransactions) error {
+
+	err := ser.membership.Authorize(ctx, reconciliation.UserID, reconciliation.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.getInProgressReconciliation(uow, reconciliation)
+	if err != nil {
+		return err
+	}
+
+	// same transaction may be sent more than once, it must be counted only once.
+	transactions.TransactionIDs = util.UniqueUUIDs(transactions.TransactionIDs)
+
+	var tempTransactions []envelopModel.Transaction
+
+	err = ser.repo.GetAll(uow, &tempTransactions,
+		repository.Filter("transactions.`id` IN (?) AND transactions.`account_id` = ? AND transactions.`budget_id` = ?"+
+			" AND transactions.`status` != ? AND transactions.`deleted_at` IS NULL",
+			transactions.TransactionIDs, reconciliation.AccountID, reconciliation.BudgetID,
+			envelopModel.TransactionStatusReconciled))
+	if err != nil {
+		return err
+	}
+
+	if len(tempTransactions) != len(transactions.TransactionIDs) {
+		return errors.NewValidationError("Transactions must belong to the account and must not be reconciled")
+	}
+
+	status := envelopModel.TransactionStatusPending
+	if transactions.Cleared {
+		status = envelopModel.TransactionStatusCleared
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &envelopModel.Transaction{}, map[string]interface{}{
+		"Status": status,
+	}, repository.Filter("transactions.`id` IN (?)", transactions.TransactionIDs))
+	if err != nil {
+		return err
+	}
+
+	for index := range tempTransactions {
+		after := tempTransactions[index]
+		after.Status = status
+		err = ser.recordTransactionChange(uow, &tempTransactions[index], &after)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = ser.updateBalance(ctx, uow, reconciliation)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// CompleteReconciliation will lock all cleared transactions till end of the statement day.
+// Reconciliation can be completed only when the cleared balance matches the statement balance.
+func (ser *reconciliationService) CompleteReconciliation(ctx context.Context, reconciliation *accountModel.ReconciliationDTO) error {
+
+	err := ser.membership.Authorize(ctx, reconciliation.UserID, reconciliation.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.getInProgressReconciliation(uow, reconciliation)
+	if err != nil {
+		return err
+	}
+
+	err = ser.updateBalance(ctx, uow, reconciliation)
+	if err != nil {
+		return err
+	}
+
+	if reconciliation.Difference != 0 {
+		return errors.NewValidationError(fmt.Sprintf("Statement balance differs from cleared balance by %.2f",
+			reconciliation.Difference))
+	}
+
+	statementEnd, err := ser.statementEnd(ctx, reconciliation.UserID, reconciliation.StatementDate)
+	if err != nil {
+		return err
+	}
+
+	var tempTransactions []envelopModel.Transaction
+
+	err = ser.repo.GetAll(uow, &tempTransactions,
+		repository.Filter("transactions.`account_id` = ? AND transactions.`status` = ? AND transactions.`date` < ?"+
+			" AND transactions.`deleted_at` IS NULL", reconciliation.AccountID, envelopModel.TransactionStatusCleared,
+			statementEnd))
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &envelopModel.Transaction{}, map[string]interface{}{
+		"Status":           envelopModel.TransactionStatusReconciled,
+		"ReconciliationID": reconciliation.ID,
+	}, repository.Filter("transactions.`account_id` = ? AND transactions.`status` = ? AND transactions.`date` < ?"+
+		" AND transactions.`deleted_at` IS NULL", reconciliation.AccountID, envelopModel.TransactionStatusCleared,
+		statementEnd))
+	if err != nil {
+		return err
+	}
+
+	for index := range tempTransactions {
+		after := tempTransactions[index]
+		after.Status = envelopModel.TransactionStatusReconciled
+		after.ReconciliationID = &reconciliation.ID
+		err = ser.recordTransactionChange(uow, &tempTransactions[index], &after)
+		if err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	reconciliation.Status = accountModel.ReconciliationStatusCompleted
+	reconciliation.CompletedAt = &now
+
+	err = ser.repo.UpdateWithMap(uow, &accountModel.Reconciliation{}, map[string]interface{}{
+		"Status":      reconciliation.Status,
+		"CompletedAt": reconciliation.CompletedAt,
+	}, repository.Filter("reconciliations.`id` = ?", reconciliation.ID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// CancelReconciliation will discard the reconciliation which is in progress.
+// Cleared status of transactions is retained.
+func (ser *reconciliationService) CancelReconciliation(ctx context.Context, reconciliation *accountModel.Reconciliation) error {
+
+	err := ser.membership.Authorize(ctx, reconciliation.UserID, reconciliation.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	tempReconciliation := accountModel.ReconciliationDTO{}
+	tempReconciliation.ID = reconciliation.ID
+	tempReconciliation.UserID = reconciliation.UserID
+	tempReconciliation.AccountID = reconciliation.AccountID
+	tempReconciliation.BudgetID = reconciliation.BudgetID
+
+	err = ser.getInProgressReconciliation(uow, &tempReconciliation)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Delete(uow, &accountModel.Reconciliation{}, "`id` = ?", reconciliation.ID)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// GetReconciliation will fetch specified reconciliation.
+// Balance of reconciliation in progress is recalculated.
+func (ser *reconciliationService) GetReconciliation(ctx context.Context, reconciliation *accountModel.ReconciliationDTO) error {
+
+	err := ser.membership.Authorize(ctx, reconciliation.UserID, reconciliation.BudgetID, budgetModel.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.GetRecord(uow, reconciliation,
+		repository.Filter("reconciliations.`id` = ? AND reconciliations.`account_id` = ? AND reconciliations.`budget_id` = ?"+
+			" AND reconciliations.`deleted_at` IS NULL", reconciliation.ID, reconciliation.AccountID, reconciliation.BudgetID))
+	if err != nil {
+		return errors.NewValidationError("Reconciliation not found")
+	}
+
+	if reconciliation.Status == accountModel.ReconciliationStatusInProgress {
+		err = ser.updateBalance(ctx, uow, reconciliation)
+		if err != nil {
+			return err
+		}
+	}
+
+	return uow.Commit()
+}
+
+// GetReconciliations will fetch all reconciliations of specified account.
+func (ser *reconciliationService) GetReconciliations(ctx context.Context, reconciliations *[]accountModel.ReconciliationDTO,
+	userID, budgetID, accountID uuid.UUID) error {
+
+	err := ser.membership.Authorize(ctx, userID, budgetID, budgetModel.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	err = ser.validateAccountID(ctx, budgetID, accountID)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.GetAllInOrder(uow, reconciliations, "reconciliations.`statement_date` DESC",
+		repository.Filter("reconciliations.`account_id` = ? AND reconciliations.`budget_id` = ?"+
+			" AND reconciliations.`deleted_at` IS NULL", accountID, budgetID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// getInProgressReconciliation will fetch the reconciliation and verify it is still in progress.
+func (ser *reconciliationService) getInProgressReconciliation(uow *repository.UnitOfWork,
+	reconciliation *accountModel.ReconciliationDTO) error {
+
+	err := ser.repo.GetRecord(uow, reconciliation,
+		repository.Filter("reconciliations.`id` = ? AND reconciliations.`account_id` = ? AND reconciliations.`budget_id` = ?"+
+			" AND reconciliations.`deleted_at` IS NULL", reconciliation.ID, reconciliation.AccountID, reconciliation.BudgetID))
+	if err != nil {
+		return errors.NewValidationError("Reconciliation not found")
+	}
+
+	if reconciliation.Status != accountModel.ReconciliationStatusInProgress {
+		return errors.NewValidationError("Reconciliation is already completed")
+	}
+	return nil
+}
+
+// updateBalance will recalculate cleared balance and difference of reconciliation and save it.
+func (ser *reconciliationService) updateBalance(ctx context.Context, uow *repository.UnitOfWork,
+	reconciliation *accountModel.ReconciliationDTO) error {
+
+	statementEnd, err := ser.statementEnd(ctx, reconciliation.UserID, reconciliation.StatementDate)
+	if err != nil {
+		return err
+	}
+
+	reconciliation.ClearedBalance, err = ser.calculateClearedBalance(uow, reconciliation.AccountID, statementEnd)
+	if err != nil {
+		return err
+	}
+	reconciliation.Difference = roundAmount(reconciliation.StatementBalance - reconciliation.ClearedBalance)
+
+	return ser.repo.UpdateWithMap(uow, &accountModel.Reconciliation{}, map[string]interface{}{
+		"ClearedBalance": reconciliation.ClearedBalance,
+		"Difference":     reconciliation.Difference,
+	}, repository.Filter("reconciliations.`id` = ?", reconciliation.ID))
+}
+
+// statementEnd returns end of the statement day, which is start of the next day in timezone of the user.
+func (ser *reconciliationService) statementEnd(ctx context.Context, userID uuid.UUID,
+	statementDate general.Date) (time.Time, error) {
+
+	preference, err := ser.preferences.GetPreference(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return preference.StartOfDay(statementDate.Time).AddDate(0, 0, 1), nil
+}
+
+// calculateClearedBalance will return opening balance of account along with
+// all cleared and reconciled transactions before end of the statement day.
+func (ser *reconciliationService) calculateClearedBalance(uow *repository.UnitOfWork,
+	accountID uuid.UUID, statementEnd time.Time) (float64, error) {
+
+	account := accountModel.AccountDTO{}
+
+	err := ser.repo.GetRecord(uow, &account, repository.Filter("accounts.`id` = ?", accountID),
+		repository.Select("`amount`"))
+	if err != nil {
+		return 0, err
+	}
+
+	total := struct {
+		Amount float64
+	}{}
+
+	err = ser.repo.Scan(uow, &total, repository.Model(&envelopModel.Transaction{}),
+		repository.Select("COALESCE(SUM(CASE WHEN transactions.`transaction_type` = ? THEN transactions.`amount`"+
+			" ELSE -transactions.`amount` END), 0) AS amount", envelopModel.TransactionTypeCredit),
+		repository.Filter("transactions.`account_id` = ? AND transactions.`status` IN (?) AND transactions.`date` < ?"+
+			" AND transactions.`deleted_at` IS NULL", accountID, []string{envelopModel.TransactionStatusCleared,
+			envelopModel.TransactionStatusReconciled}, statementEnd))
+	if err != nil {
+		return 0, err
+	}
+
+	return roundAmount(account.Amount + total.Amount), nil
+}
+
+// recordTransactionChange will add a version to change log of the transaction changed by reconciliation.
+func (ser *reconciliationService) recordTransactionChange(uow *repository.UnitOfWork,
+	before, after *envelopModel.Transaction) error {
+
+	return ser.changeLogger.Record(uow, &auditModel.ChangeLog{
+		EntityType: auditModel.EntityTransaction,
+		EntityID:   before.ID,
+		Action:     auditModel.ActionUpdate,
+	}, before, after)
+}
+
+// validateAccountID will verify if accountID exist in budget or not.
+func (ser *reconciliationService) validateAccountID(ctx context.Context, budgetID, accountID uuid.UUID) error {
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), accountModel.Account{},
+		repository.Filter("accounts.`id` = ? AND accounts.`budget_id` = ? AND accounts.`deleted_at` IS NULL",
+			accountID, budgetID))
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return errors.NewValidationError("Account not found")
+	}
+	return nil
+}
+
+// roundAmount will round amount to 2 decimal places.
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	auditService "github.com/shaileshhb/budget-planner-go/budgetplanner/audit/service"
+	budgetService "github.com/shaileshhb/budget-planner-go/budgetplanner/budget/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	accountModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/account"
+	auditModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/audit"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"gorm.io/gorm"
+)
+
+// AccountService service provides methods to update, delete, add, get method for accountService.
+type AccountService interface {
+	AddAccount(ctx context.Context, account *accountModel.Account) error
+	UpdateAccount(ctx context.Context, account *accountModel.Account) error
+	DeleteAccount(ctx context.Context, account *accountModel.Account) error
+	GetAccounts(ctx context.Context, accounts *[]accountModel.AccountDTO, userID, budgetID uuid.UUID) error
+	GetAccountHistory(ctx context.Context, history *[]auditModel.ChangeLogDTO, account *accountModel.Account) error
+	RevertAccount(ctx context.Context, account *accountModel.Account, version int) error
+}
+
+// accountService
+type accountService struct {
+	db           *gorm.DB
+	repo         repository.Repository
+	auth         *security.Authentication
+	changeLogger auditService.ChangeLogger
+	membership   budgetService.MembershipService
+}
+
+// NewAccountService create new account service.
+func NewAccountService(db *gorm.DB, repo repository.Repository, auth *security.Authentication,
+	changeLogger auditService.ChangeLogger, membership budgetService.MembershipService) AccountService {
+	return &accountService{
+		db:           db,
+		repo:         repo,
+		auth:         auth,
+		changeLogger: changeLogger,
+		membership:   membership,
+	}
+}
+
+// AddAccount will add new account in specified budget.
+func (ser *accountService) AddAccount(ctx context.Context, account *accountModel.Account) error {
+
+	err := ser.membership.Authorize(ctx, account.UserID, account.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.Add(uow, account)
+	if err != nil {
+		return err
+	}
+
+	err = ser.recordChange(uow, auditModel.ActionCreate, account.ID, nil, account)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// UpdateAccount will update specified account.
+func (ser *accountService) UpdateAccount(ctx context.Context, account *accountModel.Account) error {
+
+	err := ser.membership.Authorize(ctx, account.UserID, account.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	err = ser.validateAccountID(ctx, account.BudgetID, account.ID)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	tempAccount := accountModel.Account{}
+
+	err = ser.repo.GetRecord(uow, &tempAccount, repository.Filter("accounts.`id` = ?", account.ID))
+	if err != nil {
+		return err
+	}
+
+	// user who created the account remains its creator.
+	account.UserID = tempAccount.UserID
+
+	err = ser.repo.Updates(uow, account)
+	if err != nil {
+		return err
+	}
+
+	err = ser.recordUpdate(uow, auditModel.ActionUpdate, &tempAccount)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// DeleteAccount will delete specified account.
+func (ser *accountService) DeleteAccount(ctx context.Context, account *accountModel.Account) error {
+
+	err := ser.membership.Authorize(ctx, account.UserID, account.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	err = ser.validateAccountID(ctx, account.BudgetID, account.ID)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	tempAccount := accountModel.Account{}
+
+	err = ser.repo.GetRecord(uow, &tempAccount, repository.Filter("accounts.`id` = ?", account.ID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &accountModel.Account{}, map[string]interface{}{
+		"DeletedAt": time.Now(),
+	}, repository.Filter("accounts.`id` = ?", account.ID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.recordChange(uow, auditModel.ActionDelete, account.ID, &tempAccount, nil)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// GetAccounts will fetch all the accounts of specifed budget.
+func (ser *accountService) GetAccounts(ctx context.Context, accounts *[]accountModel.AccountDTO, userID, budgetID uuid.UUID) error {
+
+	err := ser.membership.Authorize(ctx, userID, budgetID, budgetModel.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.GetAllInOrder(uow, accounts, "accounts.`name`",
+		repository.Filter("accounts.`budget_id` = ? AND accounts.`deleted_at` IS NULL", budgetID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// GetAccountHistory will fetch all versions of specified account.
+func (ser *accountService) GetAccountHistory(ctx context.Context, history *[]auditModel.ChangeLogDTO, account *accountModel.Account) error {
+
+	err := ser.membership.Authorize(ctx, account.UserID, account.BudgetID, budgetModel.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), accountModel.Account{},
+		repository.Filter("accounts.`id` = ? AND accounts.`budget_id` = ?", account.ID, account.BudgetID))
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return errors.NewValidationError("Account not found")
+	}
+
+	return ser.changeLogger.GetHistory(ctx, history, auditModel.EntityAccount, account.ID)
+}
+
+// RevertAccount will revert specified account to the state it was in at specified version.
+func (ser *accountService) RevertAccount(ctx context.Context, account *accountModel.Account, version int) error {
+
+	err := ser.membership.Authorize(ctx, account.UserID, account.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	tempAccount := accountModel.Account{}
+
+	err = ser.repo.GetRecord(uow, &tempAccount,
+		repository.Filter("accounts.`id` = ? AND accounts.`budget_id` = ? AND accounts.`deleted_at` IS NULL",
+			account.ID, account.BudgetID))
+	if err != nil {
+		return errors.NewValidationError("Account not found")
+	}
+
+	err = ser.changeLogger.GetVersion(uow, auditModel.EntityAccount, account.ID, version, account)
+	if err != nil {
+		return err
+	}
+
+	account.ID = tempAccount.ID
+	account.UserID = tempAccount.UserID
+	account.BudgetID = tempAccount.BudgetID
+	account.CreatedAt = tempAccount.CreatedAt
+
+	err = account.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Save(uow, account)
+	if err != nil {
+		return err
+	}
+
+	err = ser.recordUpdate(uow, auditModel.ActionRevert, &tempAccount)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// recordUpdate will fetch the saved account and record its changes from before.
+func (ser *accountService) recordUpdate(uow *repository.UnitOfWork, action string,
+	before *accountModel.Account) error {
+
+	after := accountModel.Account{}
+
+	err := ser.repo.GetRecord(uow, &after, repository.Filter("accounts.`id` = ?", before.ID))
+	if err != nil {
+		return err
+	}
+
+	return ser.recordChange(uow, action, before.ID, before, &after)
+}
+
+// recordChange will add a version to change log of the account.
+func (ser *accountService) recordChange(uow *repository.UnitOfWork, action string,
+	accountID uuid.UUID, before, after *accountModel.Account) error {
+
+	return ser.changeLogger.Record(uow, &auditModel.ChangeLog{
+		EntityType: auditModel.EntityAccount,
+		EntityID:   accountID,
+		Action:     action,
+	}, before, after)
+}
+
+// validateAccountID will verify if accountID exist in budget or not.
+func (ser *accountService) validateAccountID(ctx context.Context, budgetID, accountID uuid.UUID) error {
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), accountModel.Account{},
+		repository.Filter("accounts.`id` = ? AND accounts.`budget_id` = ? AND accounts.`deleted_at` IS NULL",
+			accountID, budgetID))
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return errors.NewValidationError("Account not found")
+	}
+	return nil
+}
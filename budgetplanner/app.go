@@ -26,6 +26,13 @@ type ModuleConfig interface {
 	TableMigration(wg *sync.WaitGroup)
 }
 
+// Job is a task which will be run by the app at every interval till the app is stopped.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func() error
+}
+
 // App Struct For Start the tsam service.
 type App struct {
 	sync.Mutex
@@ -40,6 +47,8 @@ type App struct {
 	Auth           *security.Authentication
 	Repository     repository.Repository
 	IsInProduction bool
+	jobs           []Job
+	quit           chan struct{}
 	// EventPool      *event.Pool
 }
 
@@ -55,6 +64,7 @@ func NewApp(name string, db *gorm.DB, log log.Logger, conf config.ConfReader, wg
 		Auth:           auth,
 		IsInProduction: isProd,
 		Repository:     repo,
+		quit:           make(chan struct{}),
 		// EventPool:      pool,
 	}
 }
@@ -110,6 +120,17 @@ func (app *App) RegisterControllerRoutes(controllers []Controller) {
 	}
 }
 
+// RegisterWellKnownRoutes will register routes in controllers under /.well-known, outside of api prefix,
+// so that they are found at the paths other services expect.
+func (app *App) RegisterWellKnownRoutes(controllers []Controller) {
+	app.Lock()
+	defer app.Unlock()
+
+	for _, controller := range controllers {
+		controller.RegisterRoutes(app.Engine.Group("/.well-known"))
+	}
+}
+
 // MigrateTables will do a table table migration for all modules.
 func (app *App) MigrateTables(configs []ModuleConfig) {
 	app.WG.Add(len(configs))
@@ -121,6 +142,46 @@ func (app *App) MigrateTables(configs []ModuleConfig) {
 	app.Log.Info("End of Migration")
 }
 
+// ScheduleJobs will register jobs which will be started along with the app.
+func (app *App) ScheduleJobs(jobs []Job) {
+	app.Lock()
+	defer app.Unlock()
+
+	app.jobs = append(app.jobs, jobs...)
+}
+
+// startJobs will run every scheduled job in its own go routine.
+func (app *App) startJobs() {
+	app.Lock()
+	defer app.Unlock()
+
+	app.WG.Add(len(app.jobs))
+	for _, job := range app.jobs {
+		go app.runJob(job)
+	}
+}
+
+// runJob will run the job at every interval till quit is closed.
+func (app *App) runJob(job Job) {
+	defer app.WG.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			app.Log.Info("Running job: ", job.Name)
+			if err := job.Run(); err != nil {
+				app.Log.Errorf("Job %s failed: %s", job.Name, err.Error())
+			}
+		case <-app.quit:
+			app.Log.Info("Stopping job: ", job.Name)
+			return
+		}
+	}
+}
+
 func (app *App) getPort() string {
 	return app.Config.GetString(config.PORT)
 }
@@ -131,6 +192,8 @@ func (app *App) Start() error {
 	app.Log.Info("Server Time: ", time.Now())
 	app.Log.Info("Server Running on port: ", app.getPort())
 
+	app.startJobs()
+
 	// if err := app.Server.ListenAndServe(); err != nil {
 	// 	app.Log.Error("Listen and serve error: ", err)
 	// 	return err
@@ -143,6 +206,8 @@ func (app *App) Start() error {
 // Stop stops the app.
 func (app *App) Stop() {
 	// Stopping scheduler.
+	close(app.quit)
+
 	context, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
 	// Closing db
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/encryption"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	auditModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/audit"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"gorm.io/gorm"
+)
+
+// ChangeLogger provides methods to record and read versioned changes of entities.
+type ChangeLogger interface {
+	Record(uow *repository.UnitOfWork, changeLog *auditModel.ChangeLog, before, after interface{}) error
+	GetHistory(ctx context.Context, changeLogs *[]auditModel.ChangeLogDTO, entityType string, entityID uuid.UUID) error
+	GetVersion(uow *repository.UnitOfWork, entityType string, entityID uuid.UUID, version int, out interface{}) error
+}
+
+// changeLogger
+type changeLogger struct {
+	db   *gorm.DB
+	repo repository.Repository
+}
+
+// NewChangeLogger create new change logger.
+func NewChangeLogger(db *gorm.DB, repo repository.Repository) ChangeLogger {
+	return &changeLogger{
+		db:   db,
+		repo: repo,
+	}
+}
+
+// Record will add new version of the entity with field level diff between before and after.
+// before should be nil for newly created entity. Update with no changed field is not recorded.
+// It must be called with the same unit of work in which the entity is changed.
+// Actor and admin impersonating them are taken from the unit of work context when not set on changeLog.
+func (ser *changeLogger) Record(uow *repository.UnitOfWork, changeLog *auditModel.ChangeLog,
+	before, after interface{}) error {
+
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return err
+	}
+
+	afterFields, err := toFields(after)
+	if err != nil {
+		return err
+	}
+
+	changes := diff(beforeFields, afterFields)
+	if len(changes) == 0 && changeLog.Action == auditModel.ActionUpdate {
+		return nil
+	}
+
+	rawChanges, err := json.Marshal(changes)
+	if err != nil {
+		return errors.NewUnexpectedError(errors.ErrorCodeJSONMarshalFailure, err)
+	}
+
+	// snapshot is the state of entity after this version. For deleted entity it is the last known state.
+	snapshot := afterFields
+	if len(snapshot) == 0 {
+		snapshot = beforeFields
+	}
+
+	rawSnapshot, err := json.Marshal(snapshot)
+	if err != nil {
+		return errors.NewUnexpectedError(errors.ErrorCodeJSONMarshalFailure, err)
+	}
+
+	latest := struct {
+		Version int
+	}{}
+
+	// versions of the entity are locked, so that concurrent changes of it get consecutive versions.
+	err = ser.repo.Scan(uow, &latest, repository.Model(&auditModel.ChangeLog{}),
+		repository.Select("COALESCE(MAX(change_logs.`version`), 0) AS version"),
+		repository.Filter("change_logs.`entity_type` = ? AND change_logs.`entity_id` = ?",
+			changeLog.EntityType, changeLog.EntityID), repository.ForUpdate())
+	if err != nil {
+		return err
+	}
+
+	if changeLog.ActorID == uuid.Nil {
+		changeLog.ActorID, _ = general.ActorFromContext(uow.DB.Statement.Context)
+	}
+
+	if impersonatorID, ok := general.ImpersonatorFromContext(uow.DB.Statement.Context); ok &&
+		changeLog.ImpersonatorID == nil {
+		changeLog.ImpersonatorID = &impersonatorID
+	}
+
+	changeLog.Version = latest.Version + 1
+	changeLog.Changes = encryption.String(rawChanges)
+	changeLog.Snapshot = encryption.String(rawSnapshot)
+
+	return ser.repo.Add(uow, changeLog)
+}
+
+// GetHistory will fetch all versions of the entity, latest first.
+func (ser *changeLogger) GetHistory(ctx context.Context, changeLogs *[]auditModel.ChangeLogDTO, entityType string,
+	entityID uuid.UUID) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err := ser.repo.GetAllInOrder(uow, changeLogs, "change_logs.`version` DESC",
+		repository.Filter("change_logs.`entity_type` = ? AND change_logs.`entity_id` = ?", entityType, entityID))
+	if err != nil {
+		return err
+	}
+
+	for index := range *changeLogs {
+		changeLog := &(*changeLogs)[index]
+		changeLog.Changes = []auditModel.FieldChange{}
+		if len(changeLog.RawChanges) == 0 {
+			continue
+		}
+		err = json.Unmarshal([]byte(changeLog.RawChanges), &changeLog.Changes)
+		if err != nil {
+			return errors.NewUnexpectedError(errors.ErrorCodeInvalidJSON, err)
+		}
+	}
+
+	return uow.Commit()
+}
+
+// GetVersion will fill out with the state of entity at specified version.
+func (ser *changeLogger) GetVersion(uow *repository.UnitOfWork, entityType string, entityID uuid.UUID,
+	version int, out interface{}) error {
+
+	changeLog := auditModel.ChangeLog{}
+
+	err := ser.repo.GetRecord(uow, &changeLog,
+		repository.Filter("change_logs.`entity_type` = ? AND change_logs.`entity_id` = ? AND change_logs.`version` = ?",
+			entityType, entityID, version))
+	if err != nil {
+		return errors.NewValidationError("Version not found")
+	}
+
+	err = json.Unmarshal([]byte(changeLog.Snapshot), out)
+	if err != nil {
+		return errors.NewUnexpectedError(errors.ErrorCodeInvalidJSON, err)
+	}
+	return nil
+}
+
+// toFields will convert entity to map of its json fields.
+func toFields(entity interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+
+	if entity == nil {
+		return fields, nil
+	}
+
+	if value := reflect.ValueOf(entity); value.Kind() == reflect.Ptr && value.IsNil() {
+		return fields, nil
+	}
+
+	raw, err := json.Marshal(entity)
+	if err != nil {
+		return nil, errors.NewUnexpectedError(errors.ErrorCodeJSONMarshalFailure, err)
+	}
+
+	err = json.Unmarshal(raw, &fields)
+	if err != nil {
+		return nil, errors.NewUnexpectedError(errors.ErrorCodeInvalidJSON, err)
+	}
+	return fields, nil
+}
+
+// diff will return fields which are different in before and after, sorted by field name.
+func diff(before, after map[string]interface{}) []auditModel.FieldChange {
+	changes := []auditModel.FieldChange{}
+
+	names := make([]string, 0, len(before)+len(after))
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if reflect.DeepEqual(before[name], after[name]) {
+			continue
+		}
+		changes = append(changes, auditModel.FieldChange{
+			Field: name,
+			From:  before[name],
+			To:    after[name],
+		})
+	}
+	return changes
+}
@@ -0,0 +1,301 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/budget/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// BudgetController provides methods to manage budgets and their members.
+type BudgetController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	addBudget(ctx *gin.Context)
+	updateBudget(ctx *gin.Context)
+	deleteBudget(ctx *gin.Context)
+	getBudgets(ctx *gin.Context)
+	getMembers(ctx *gin.Context)
+	updateMember(ctx *gin.Context)
+	removeMember(ctx *gin.Context)
+}
+
+// budgetController.
+type budgetController struct {
+	service service.BudgetService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewBudgetController create new BudgetController
+func NewBudgetController(ser service.BudgetService, log log.Logger,
+	auth *security.Authentication) BudgetController {
+	return &budgetController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for budget controller.
+// Personal access tokens can only list budgets and members.
+func (c *budgetController) RegisterRoutes(router *gin.RouterGroup) {
+
+	scoped := router.Group("/users", c.auth.ScopedMiddleware(), c.auth.AuthorizeUser())
+	scoped.GET("/:userID/budgets", c.auth.RequireScope(userModel.ScopeRead), c.getBudgets)
+	scoped.GET("/:userID/budgets/:budgetID/members", c.auth.RequireScope(userModel.ScopeRead), c.getMembers)
+
+	guarded := router.Group("/users", c.auth.Middleware(), c.auth.AuthorizeUser())
+	guarded.POST("/:userID/budgets", c.addBudget)
+	guarded.PUT("/:userID/budgets/:budgetID", c.updateBudget)
+	guarded.DELETE("/:userID/budgets/:budgetID", c.deleteBudget)
+	guarded.PUT("/:userID/budgets/:budgetID/members/:memberID", c.updateMember)
+	guarded.DELETE("/:userID/budgets/:budgetID/members/:memberID", c.removeMember)
+}
+
+// addBudget will create new shared budget owned by the user.
+func (c *budgetController) addBudget(ctx *gin.Context) {
+	budget := budgetModel.Budget{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &budget)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = budget.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.AddBudget(ctx.Request.Context(), userID, &budget)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusCreated, budget)
+}
+
+// updateBudget will rename specified budget.
+func (c *budgetController) updateBudget(ctx *gin.Context) {
+	budget := budgetModel.Budget{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &budget)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	budget.ID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = budget.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.UpdateBudget(ctx.Request.Context(), userID, &budget)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// deleteBudget will delete specified shared budget.
+func (c *budgetController) deleteBudget(ctx *gin.Context) {
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	budgetID, err := parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.DeleteBudget(ctx.Request.Context(), userID, budgetID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// getBudgets will fetch all budgets of which user is member.
+func (c *budgetController) getBudgets(ctx *gin.Context) {
+	budgets := []budgetModel.BudgetDTO{}
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetBudgets(ctx.Request.Context(), &budgets, userID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, budgets)
+}
+
+// getMembers will fetch all members of specified budget.
+func (c *budgetController) getMembers(ctx *gin.Context) {
+	members := []budgetModel.BudgetMemberDTO{}
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	budgetID, err := parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetMembers(ctx.Request.Context(), &members, userID, budgetID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, members)
+}
+
+// updateMember will change role of specified member of budget.
+func (c *budgetController) updateMember(ctx *gin.Context) {
+	role := budgetModel.MemberRole{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &role)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = role.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	budgetID, err := parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	memberID, err := parser.GetUUID("memberID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.UpdateMember(ctx.Request.Context(), userID, budgetID, memberID, &role)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// removeMember will remove specified member from budget, member can remove themselves to leave it.
+func (c *budgetController) removeMember(ctx *gin.Context) {
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	budgetID, err := parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	memberID, err := parser.GetUUID("memberID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.RemoveMember(ctx.Request.Context(), userID, budgetID, memberID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
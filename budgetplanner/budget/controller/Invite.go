@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/budget/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// InviteController provides methods to invite users to budgets.
+type InviteController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	invite(ctx *gin.Context)
+	getInvites(ctx *gin.Context)
+	revokeInvite(ctx *gin.Context)
+	acceptInvite(ctx *gin.Context)
+}
+
+// inviteController.
+type inviteController struct {
+	service service.InviteService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewInviteController create new InviteController
+func NewInviteController(ser service.InviteService, log log.Logger,
+	auth *security.Authentication) InviteController {
+	return &inviteController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for invite controller.
+func (c *inviteController) RegisterRoutes(router *gin.RouterGroup) {
+	guarded := router.Group("/users", c.auth.Middleware(), c.auth.AuthorizeUser())
+	guarded.GET("/:userID/budgets/:budgetID/invites", c.getInvites)
+	guarded.POST("/:userID/budgets/:budgetID/invites", c.invite)
+	guarded.DELETE("/:userID/budgets/:budgetID/invites/:inviteID", c.revokeInvite)
+	guarded.POST("/:userID/budget-invites/accept", c.acceptInvite)
+}
+
+// invite will email invite to join specified budget.
+func (c *inviteController) invite(ctx *gin.Context) {
+	invite := budgetModel.BudgetInvite{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &invite)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	invite.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = invite.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.Invite(ctx.Request.Context(), userID, &invite)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusCreated, invite)
+}
+
+// getInvites will fetch pending invites of specified budget.
+func (c *inviteController) getInvites(ctx *gin.Context) {
+	invites := []budgetModel.BudgetInviteDTO{}
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	budgetID, err := parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetInvites(ctx.Request.Context(), &invites, userID, budgetID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, invites)
+}
+
+// revokeInvite will cancel specified pending invite.
+func (c *inviteController) revokeInvite(ctx *gin.Context) {
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	budgetID, err := parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	inviteID, err := parser.GetUUID("inviteID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.RevokeInvite(ctx.Request.Context(), userID, budgetID, inviteID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, nil)
+}
+
+// acceptInvite will add user to budget using token sent in invite email.
+func (c *inviteController) acceptInvite(ctx *gin.Context) {
+	acceptance := budgetModel.InviteAcceptance{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &acceptance)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = acceptance.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.AcceptInvite(ctx.Request.Context(), userID, &acceptance)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, nil)
+}
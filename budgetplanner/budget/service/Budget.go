@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"gorm.io/gorm"
+)
+
+// BudgetService consist of all methods BudgetService should implement.
+type BudgetService interface {
+	AddBudget(ctx context.Context, userID uuid.UUID, budget *budgetModel.Budget) error
+	UpdateBudget(ctx context.Context, userID uuid.UUID, budget *budgetModel.Budget) error
+	DeleteBudget(ctx context.Context, userID, budgetID uuid.UUID) error
+	GetBudgets(ctx context.Context, budgets *[]budgetModel.BudgetDTO, userID uuid.UUID) error
+	GetMembers(ctx context.Context, members *[]budgetModel.BudgetMemberDTO, userID, budgetID uuid.UUID) error
+	UpdateMember(ctx context.Context, userID, budgetID, memberUserID uuid.UUID, role *budgetModel.MemberRole) error
+	RemoveMember(ctx context.Context, userID, budgetID, memberUserID uuid.UUID) error
+}
+
+// budgetService provides methods to manage budgets and their members.
+type budgetService struct {
+	db         *gorm.DB
+	repo       repository.Repository
+	auth       *security.Authentication
+	membership MembershipService
+}
+
+// NewBudgetService returns new instance of BudgetService.
+func NewBudgetService(db *gorm.DB, repo repository.Repository, auth *security.Authentication,
+	membership MembershipService) BudgetService {
+	return &budgetService{
+		db:         db,
+		repo:       repo,
+		auth:       auth,
+		membership: membership,
+	}
+}
+
+// AddBudget will create new shared budget with user as its owner.
+func (ser *budgetService) AddBudget(ctx context.Context, userID uuid.UUID, budget *budgetModel.Budget) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	budget.IsPersonal = false
+
+	err := ser.repo.Add(uow, budget)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Add(uow, &budgetModel.BudgetMember{
+		BudgetID: budget.ID,
+		UserID:   userID,
+		Role:     budgetModel.RoleOwner,
+	})
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// UpdateBudget will rename the budget.
+func (ser *budgetService) UpdateBudget(ctx context.Context, userID uuid.UUID, budget *budgetModel.Budget) error {
+
+	err := ser.membership.Authorize(ctx, userID, budget.ID, budgetModel.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.UpdateWithMap(uow, &budgetModel.Budget{}, map[string]interface{}{
+		"Name": budget.Name,
+	}, repository.Filter("budgets.`id` = ?", budget.ID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// DeleteBudget will delete shared budget. Personal budget can't be deleted.
+func (ser *budgetService) DeleteBudget(ctx context.Context, userID, budgetID uuid.UUID) error {
+
+	err := ser.membership.Authorize(ctx, userID, budgetID, budgetModel.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	budget := budgetModel.Budget{}
+
+	err = ser.repo.GetRecord(uow, &budget, repository.Filter("budgets.`id` = ?", budgetID))
+	if err != nil {
+		return err
+	}
+
+	if budget.IsPersonal {
+		return errors.NewValidationError("Personal budget cannot be deleted")
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &budgetModel.Budget{}, map[string]interface{}{
+		"DeletedAt": time.Now(),
+	}, repository.Filter("budgets.`id` = ?", budgetID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Delete(uow, &budgetModel.BudgetInvite{}, "`budget_id` = ? AND `accepted_at` IS NULL", budgetID)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// GetBudgets will fetch all budgets of which user is member along with role of the user.
+func (ser *budgetService) GetBudgets(ctx context.Context, budgets *[]budgetModel.BudgetDTO, userID uuid.UUID) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err := ser.repo.GetAllInOrder(uow, budgets, "budgets.`is_personal` DESC, budgets.`name`",
+		repository.Join("INNER JOIN budget_members ON budget_members.`budget_id` = budgets.`id`"),
+		repository.Select("budgets.*, budget_members.`role`"),
+		repository.Filter("budget_members.`user_id` = ? AND budgets.`deleted_at` IS NULL", userID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// GetMembers will fetch all members of the budget.
+func (ser *budgetService) GetMembers(ctx context.Context, members *[]budgetModel.BudgetMemberDTO,
+	userID, budgetID uuid.UUID) error {
+
+	err := ser.membership.Authorize(ctx, userID, budgetID, budgetModel.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.GetAllInOrder(uow, members, "users.`name`",
+		repository.Join("INNER JOIN users ON users.`id` = budget_members.`user_id`"),
+		repository.Select("budget_members.`id`, budget_members.`user_id`, budget_members.`role`,"+
+			" users.`name`, users.`email`"),
+		repository.Filter("budget_members.`budget_id` = ?", budgetID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// UpdateMember will change role of member of the budget. Budget must be left with at least one owner.
+func (ser *budgetService) UpdateMember(ctx context.Context, userID, budgetID, memberUserID uuid.UUID,
+	role *budgetModel.MemberRole) error {
+
+	err := ser.membership.Authorize(ctx, userID, budgetID, budgetModel.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	member, err := ser.getMember(uow, budgetID, memberUserID)
+	if err != nil {
+		return err
+	}
+
+	if member.Role == budgetModel.RoleOwner && role.Role != budgetModel.RoleOwner {
+		err = ser.validateOtherOwnerExists(uow, budgetID, memberUserID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &budgetModel.BudgetMember{}, map[string]interface{}{
+		"Role": role.Role,
+	}, repository.Filter("budget_members.`id` = ?", member.ID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// RemoveMember will remove member from the budget. Owner can remove any member and
+// every member can leave the budget. Budget must be left with at least one owner.
+func (ser *budgetService) RemoveMember(ctx context.Context, userID, budgetID, memberUserID uuid.UUID) error {
+
+	requiredRole := budgetModel.RoleOwner
+	if userID == memberUserID {
+		requiredRole = budgetModel.RoleViewer
+	}
+
+	err := ser.membership.Authorize(ctx, userID, budgetID, requiredRole)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	budget := budgetModel.Budget{}
+
+	err = ser.repo.GetRecord(uow, &budget, repository.Filter("budgets.`id` = ?", budgetID))
+	if err != nil {
+		return err
+	}
+
+	member, err := ser.getMember(uow, budgetID, memberUserID)
+	if err != nil {
+		return err
+	}
+
+	if member.Role == budgetModel.RoleOwner {
+		if budget.IsPersonal {
+			return errors.NewValidationError("Owner cannot leave personal budget")
+		}
+
+		err = ser.validateOtherOwnerExists(uow, budgetID, memberUserID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = ser.repo.Delete(uow, &budgetModel.BudgetMember{}, "`id` = ?", member.ID)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// getMember will fetch membership of user in budget.
+func (ser *budgetService) getMember(uow *repository.UnitOfWork, budgetID,
+	userID uuid.UUID) (*budgetModel.BudgetMember, error) {
+
+	member := budgetModel.BudgetMember{}
+
+	err := ser.repo.GetRecord(uow, &member, repository.Filter("budget_members.`budget_id` = ?"+
+		" AND budget_members.`user_id` = ?", budgetID, userID))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewValidationError("Member not found")
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// validateOtherOwnerExists will return error if user is the only owner of budget.
+func (ser *budgetService) validateOtherOwnerExists(uow *repository.UnitOfWork, budgetID, userID uuid.UUID) error {
+
+	var totalCount int64
+
+	err := ser.repo.GetCount(uow, budgetModel.BudgetMember{}, &totalCount,
+		repository.Filter("budget_members.`budget_id` = ? AND budget_members.`user_id` != ?"+
+			" AND budget_members.`role` = ?", budgetID, userID, budgetModel.RoleOwner))
+	if err != nil {
+		return err
+	}
+
+	if totalCount == 0 {
+		return errors.NewValidationError("Budget must have at least one owner")
+	}
+	return nil
+}
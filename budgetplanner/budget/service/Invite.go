@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/email"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"gorm.io/gorm"
+)
+
+// inviteTTL is how long invite to budget can be accepted.
+const inviteTTL = 7 * 24 * time.Hour
+
+// InviteService consist of all methods InviteService should implement.
+type InviteService interface {
+	Invite(ctx context.Context, userID uuid.UUID, invite *budgetModel.BudgetInvite) error
+	GetInvites(ctx context.Context, invites *[]budgetModel.BudgetInviteDTO, userID, budgetID uuid.UUID) error
+	RevokeInvite(ctx context.Context, userID, budgetID, inviteID uuid.UUID) error
+	AcceptInvite(ctx context.Context, userID uuid.UUID, acceptance *budgetModel.InviteAcceptance) error
+	PurgeExpired() error
+}
+
+// inviteService provides methods to invite users to budget by email.
+type inviteService struct {
+	db         *gorm.DB
+	repo       repository.Repository
+	auth       *security.Authentication
+	conf       config.ConfReader
+	sender     email.Sender
+	membership MembershipService
+}
+
+// NewInviteService returns new instance of InviteService.
+func NewInviteService(db *gorm.DB, repo repository.Repository, auth *security.Authentication,
+	conf config.ConfReader, sender email.Sender, membership MembershipService) InviteService {
+	return &inviteService{
+		db:         db,
+		repo:       repo,
+		auth:       auth,
+		conf:       conf,
+		sender:     sender,
+		membership: membership,
+	}
+}
+
+// Invite will email link to join the budget. Earlier invite to the same email is replaced.
+func (ser *inviteService) Invite(ctx context.Context, userID uuid.UUID, invite *budgetModel.BudgetInvite) error {
+
+	err := ser.membership.Authorize(ctx, userID, invite.BudgetID, budgetModel.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), budgetModel.BudgetMember{},
+		repository.Join("INNER JOIN users ON users.`id` = budget_members.`user_id`"),
+		repository.Filter("budget_members.`budget_id` = ? AND users.`email` = ?", invite.BudgetID, invite.Email))
+	if err != nil {
+		return err
+	}
+
+	if exist {
+		return errors.NewValidationError("User is already a member of the budget")
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	budget := budgetModel.Budget{}
+
+	err = ser.repo.GetRecord(uow, &budget, repository.Filter("budgets.`id` = ?", invite.BudgetID))
+	if err != nil {
+		return err
+	}
+
+	inviter := userModel.User{}
+
+	err = ser.repo.GetRecord(uow, &inviter, repository.Filter("users.`id` = ?", userID),
+		repository.Select("`id`, `name`"))
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Delete(uow, &budgetModel.BudgetInvite{}, "`budget_id` = ? AND `email` = ? AND `accepted_at` IS NULL",
+		invite.BudgetID, invite.Email)
+	if err != nil {
+		return err
+	}
+
+	token, tokenHash, err := ser.auth.GenerateRandomToken()
+	if err != nil {
+		return err
+	}
+
+	invite.TokenHash = tokenHash
+	invite.ExpiresAt = time.Now().Add(inviteTTL)
+	invite.AcceptedAt = nil
+
+	err = ser.repo.Add(uow, invite)
+	if err != nil {
+		return err
+	}
+
+	err = ser.sender.Send(&email.Message{
+		To:      []string{invite.Email},
+		Subject: fmt.Sprintf("%s invited you to %s", inviter.Name, budget.Name),
+		Body: fmt.Sprintf("Hi,\n\n%s invited you to join budget %s as %s. Open the link below to join.\n\n%s\n\n"+
+			"The link expires in %d days.", inviter.Name, budget.Name, invite.Role, ser.inviteLink(token),
+			int(inviteTTL.Hours()/24)),
+	})
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// GetInvites will fetch pending invites of the budget.
+func (ser *inviteService) GetInvites(ctx context.Context, invites *[]budgetModel.BudgetInviteDTO,
+	userID, budgetID uuid.UUID) error {
+
+	err := ser.membership.Authorize(ctx, userID, budgetID, budgetModel.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.GetAllInOrder(uow, invites, "budget_invites.`created_at` DESC",
+		repository.Filter("budget_invites.`budget_id` = ? AND budget_invites.`accepted_at` IS NULL"+
+			" AND budget_invites.`expires_at` > ?", budgetID, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// RevokeInvite will cancel pending invite so that it can't be accepted.
+func (ser *inviteService) RevokeInvite(ctx context.Context, userID, budgetID, inviteID uuid.UUID) error {
+
+	err := ser.membership.Authorize(ctx, userID, budgetID, budgetModel.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), budgetModel.BudgetInvite{},
+		repository.Filter("budget_invites.`id` = ? AND budget_invites.`budget_id` = ?"+
+			" AND budget_invites.`accepted_at` IS NULL", inviteID, budgetID))
+	if err != nil {
+		return err
+	}
+
+	if !exist {
+		return errors.NewValidationError("Invite not found")
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.Delete(uow, &budgetModel.BudgetInvite{}, "`id` = ?", inviteID)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// AcceptInvite will add user to budget of the invite. Invite can only be accepted by user
+// with the email to which it was sent.
+func (ser *inviteService) AcceptInvite(ctx context.Context, userID uuid.UUID,
+	acceptance *budgetModel.InviteAcceptance) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	invite := budgetModel.BudgetInvite{}
+
+	err := ser.repo.GetRecord(uow, &invite, repository.Join("INNER JOIN budgets"+
+		" ON budgets.`id` = budget_invites.`budget_id` AND budgets.`deleted_at` IS NULL"),
+		repository.Filter("budget_invites.`token_hash` = ?", security.HashToken(acceptance.Token)))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewValidationError("Invalid or expired invite")
+		}
+		return err
+	}
+
+	if !invite.IsActive() {
+		return errors.NewValidationError("Invalid or expired invite")
+	}
+
+	// invites to budgets of other tenants can't be accepted.
+	err = ser.repo.GetRecord(uow, &budgetModel.Budget{}, repository.Filter("budgets.`id` = ?", invite.BudgetID),
+		repository.Select("`id`"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewValidationError("Invalid or expired invite")
+		}
+		return err
+	}
+
+	user := userModel.User{}
+
+	err = ser.repo.GetRecord(uow, &user, repository.Filter("users.`id` = ? AND users.`deleted_at` IS NULL", userID),
+		repository.Select("`id`, `email`"))
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(user.Email, invite.Email) {
+		return errors.NewValidationError("Invite was sent to a different email")
+	}
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), budgetModel.BudgetMember{},
+		repository.Filter("budget_members.`budget_id` = ? AND budget_members.`user_id` = ?", invite.BudgetID, userID))
+	if err != nil {
+		return err
+	}
+
+	if !exist {
+		err = ser.repo.Add(uow, &budgetModel.BudgetMember{
+			BudgetID: invite.BudgetID,
+			UserID:   userID,
+			Role:     invite.Role,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &budgetModel.BudgetInvite{}, map[string]interface{}{
+		"AcceptedAt": time.Now(),
+	}, repository.Filter("budget_invites.`id` = ?", invite.ID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// PurgeExpired will remove invites which were not accepted in time.
+func (ser *inviteService) PurgeExpired() error {
+
+	uow := repository.NewUnitOfWork(ser.db)
+	defer uow.RollBack()
+
+	err := ser.repo.Delete(uow, &budgetModel.BudgetInvite{}, "`accepted_at` IS NULL AND `expires_at` < ?", time.Now())
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// inviteLink will return link which is sent to the user for joining budget.
+func (ser *inviteService) inviteLink(token string) string {
+	return strings.TrimRight(ser.conf.GetString(config.AppURL), "/") + "/budget-invites/accept?token=" +
+		url.QueryEscape(token)
+}
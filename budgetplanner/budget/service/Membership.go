@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"gorm.io/gorm"
+)
+
+// MembershipService consist of all methods MembershipService should implement.
+type MembershipService interface {
+	Authorize(ctx context.Context, userID, budgetID uuid.UUID, role string) error
+	GetRole(ctx context.Context, userID, budgetID uuid.UUID) (string, error)
+}
+
+// membershipService checks access of users to budgets.
+type membershipService struct {
+	db   *gorm.DB
+	repo repository.Repository
+}
+
+// NewMembershipService returns new instance of MembershipService.
+func NewMembershipService(db *gorm.DB, repo repository.Repository) MembershipService {
+	return &membershipService{
+		db:   db,
+		repo: repo,
+	}
+}
+
+// Authorize will return error if user is not member of budget with at least the specified role.
+func (ser *membershipService) Authorize(ctx context.Context, userID, budgetID uuid.UUID, role string) error {
+
+	memberRole, err := ser.GetRole(ctx, userID, budgetID)
+	if err != nil {
+		return err
+	}
+
+	if !budgetModel.HasRole(memberRole, role) {
+		return errors.NewValidationError("You don't have permission to do this in the budget")
+	}
+	return nil
+}
+
+// GetRole will return role of user in budget.
+func (ser *membershipService) GetRole(ctx context.Context, userID, budgetID uuid.UUID) (string, error) {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	// role is read through budget so that budgets of other tenants are not found.
+	budget := budgetModel.BudgetDTO{}
+
+	err := ser.repo.GetRecord(uow, &budget, repository.Join("INNER JOIN budget_members"+
+		" ON budget_members.`budget_id` = budgets.`id`"),
+		repository.Filter("budgets.`id` = ? AND budgets.`deleted_at` IS NULL AND budget_members.`user_id` = ?",
+			budgetID, userID),
+		repository.Select("budget_members.`role`"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "", errors.NewValidationError("Budget not found")
+		}
+		return "", err
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		return "", err
+	}
+	return budget.Role, nil
+}
@@ -15,6 +15,7 @@ type ConfReader interface {
 	GetString(key EnvKey) string
 	IsSet(key EnvKey) bool
 	GetInt64(key EnvKey) int64
+	GetBool(key EnvKey) bool
 }
 
 // NewConfig Read envfile and Return Config
@@ -58,3 +59,8 @@ func (config *Config) IsSet(key EnvKey) bool {
 func (config *Config) GetInt64(key EnvKey) int64 {
 	return config.viper.GetInt64(string(key))
 }
+
+// GetBool will return env value as bool
+func (config *Config) GetBool(key EnvKey) bool {
+	return config.viper.GetBool(string(key))
+}
@@ -20,9 +20,63 @@ const (
 	HTTPReadTimeout  EnvKey = "HTTP_READ_TIMEOUT"
 	HTTPIdleTimeout  EnvKey = "HTTP_IDLE_TIMEOUT"
 
+	// For tokens
+	JWTKeysDir            EnvKey = "JWT_KEYS_DIR"
+	JWTSigningKeyID       EnvKey = "JWT_SIGNING_KEY_ID"
+	AccessTokenTTLMinutes EnvKey = "ACCESS_TOKEN_TTL_MINUTES"
+	RefreshTokenTTLDays   EnvKey = "REFRESH_TOKEN_TTL_DAYS"
+
+	// For login lockout
+	LoginMaxAttempts       EnvKey = "LOGIN_MAX_ATTEMPTS"
+	LoginMaxIPAttempts     EnvKey = "LOGIN_MAX_IP_ATTEMPTS"
+	LoginLockoutMinutes    EnvKey = "LOGIN_LOCKOUT_MINUTES"
+	LoginMaxLockoutMinutes EnvKey = "LOGIN_MAX_LOCKOUT_MINUTES"
+
+	// For password policy
+	PasswordMinLength     EnvKey = "PASSWORD_MIN_LENGTH"
+	PasswordRequireUpper  EnvKey = "PASSWORD_REQUIRE_UPPER"
+	PasswordRequireLower  EnvKey = "PASSWORD_REQUIRE_LOWER"
+	PasswordRequireDigit  EnvKey = "PASSWORD_REQUIRE_DIGIT"
+	PasswordRequireSymbol EnvKey = "PASSWORD_REQUIRE_SYMBOL"
+
+	// For trash
+	TrashRetentionDays EnvKey = "TRASH_RETENTION_DAYS"
+
 	// For emails
-	SMTPHost   string = "smtp.gmail.com"
-	SMTPPort   string = "587"
-	SenderMail EnvKey = "SENDER_MAIL"
-	SenderPass EnvKey = "SENDER_PASSWORD"
+	SMTPHost       string = "smtp.gmail.com"
+	SMTPPort       string = "587"
+	SMTPServerHost EnvKey = "SMTP_HOST"
+	SMTPServerPort EnvKey = "SMTP_PORT"
+	SenderMail     EnvKey = "SENDER_MAIL"
+	SenderPass     EnvKey = "SENDER_PASSWORD"
+	AppURL         EnvKey = "APP_URL"
+
+	// For email verification
+	RequireVerifiedEmail EnvKey = "REQUIRE_VERIFIED_EMAIL"
+
+	// For OpenID Connect login, comma separated names of providers.
+	// Every provider is configured with OIDC_<NAME>_* keys, see oidc.Provider.
+	OIDCProviders EnvKey = "OIDC_PROVIDERS"
+
+	// For file storage, driver is local (default) or s3. S3 driver works with any S3 compatible server
+	// such as MinIO, objects are addressed path style as <endpoint>/<bucket>/<key>.
+	StorageDriver      EnvKey = "STORAGE_DRIVER"
+	StorageLocalDir    EnvKey = "STORAGE_LOCAL_DIR"
+	StorageS3Endpoint  EnvKey = "STORAGE_S3_ENDPOINT"
+	StorageS3Region    EnvKey = "STORAGE_S3_REGION"
+	StorageS3Bucket    EnvKey = "STORAGE_S3_BUCKET"
+	StorageS3AccessKey EnvKey = "STORAGE_S3_ACCESS_KEY"
+	StorageS3SecretKey EnvKey = "STORAGE_S3_SECRET_KEY"
+
+	// For profile images, maximum size of uploaded image in bytes.
+	ProfileImageMaxBytes EnvKey = "PROFILE_IMAGE_MAX_BYTES"
+
+	// For receipts attached to transactions, maximum size of uploaded file in bytes.
+	AttachmentMaxBytes EnvKey = "ATTACHMENT_MAX_BYTES"
+
+	// For account deletion, days after which account is erased unless deletion is cancelled.
+	AccountDeletionGraceDays EnvKey = "ACCOUNT_DELETION_GRACE_DAYS"
+
+	// For administration, comma separated emails of users who are made admin on start.
+	AdminEmails EnvKey = "ADMIN_EMAILS"
 )
@@ -1 +1,137 @@
 package email
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/smtp"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+)
+
+// Message is an email to be sent to one or more recipients.
+type Message struct {
+	To          []string
+	Subject     string
+	Body        string
+	Attachments []Attachment
+}
+
+// Attachment is a file sent along with the message.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Sender is implemented by every way of delivering emails.
+type Sender interface {
+	Send(message *Message) error
+}
+
+// NewSender will return SMTP sender when sender mail is configured, else a sender which only logs emails.
+// SMTP_HOST and SMTP_PORT can point the sender to a local SMTP server.
+func NewSender(conf config.ConfReader) Sender {
+	if !conf.IsSet(config.SenderMail) {
+		log.GetLogger().Warn("sender mail not configured, emails will only be logged")
+		return &logSender{}
+	}
+
+	host := config.SMTPHost
+	if conf.IsSet(config.SMTPServerHost) {
+		host = conf.GetString(config.SMTPServerHost)
+	}
+
+	port := config.SMTPPort
+	if conf.IsSet(config.SMTPServerPort) {
+		port = conf.GetString(config.SMTPServerPort)
+	}
+
+	return &smtpSender{
+		host:     host,
+		port:     port,
+		from:     conf.GetString(config.SenderMail),
+		password: conf.GetString(config.SenderPass),
+	}
+}
+
+// smtpSender sends emails through SMTP server.
+type smtpSender struct {
+	host     string
+	port     string
+	from     string
+	password string
+}
+
+// Send will deliver the message through SMTP server.
+func (s *smtpSender) Send(message *Message) error {
+	var auth smtp.Auth
+	if len(s.password) > 0 {
+		auth = smtp.PlainAuth("", s.from, s.password, s.host)
+	}
+
+	body := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\n"+
+		"Content-Type: text/plain; charset=\"UTF-8\"\r\n\r\n%s\r\n",
+		s.from, strings.Join(message.To, ", "), message.Subject, message.Body)
+
+	if len(message.Attachments) > 0 {
+		body = s.multipartBody(message)
+	}
+
+	err := smtp.SendMail(s.host+":"+s.port, auth, s.from, message.To, []byte(body))
+	if err != nil {
+		return errors.NewUnexpectedError(errors.ErrorCodeAPICallFailure, err)
+	}
+	return nil
+}
+
+// multipartBody will build message with text body followed by base64 encoded attachments.
+func (s *smtpSender) multipartBody(message *Message) string {
+	boundary := fmt.Sprintf("budget-planner-%d", time.Now().UnixNano())
+
+	var body strings.Builder
+
+	fmt.Fprintf(&body, "From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\n"+
+		"Content-Type: multipart/mixed; boundary=\"%s\"\r\n\r\n",
+		s.from, strings.Join(message.To, ", "), message.Subject, boundary)
+
+	fmt.Fprintf(&body, "--%s\r\nContent-Type: text/plain; charset=\"UTF-8\"\r\n\r\n%s\r\n",
+		boundary, message.Body)
+
+	for _, attachment := range message.Attachments {
+		fmt.Fprintf(&body, "--%s\r\nContent-Type: %s\r\nContent-Transfer-Encoding: base64\r\n"+
+			"Content-Disposition: attachment; filename=\"%s\"\r\n\r\n",
+			boundary, attachment.ContentType, attachment.Filename)
+
+		// lines of base64 encoded content must not be longer than 76 characters.
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		for len(encoded) > 76 {
+			body.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		body.WriteString(encoded + "\r\n")
+	}
+
+	fmt.Fprintf(&body, "--%s--\r\n", boundary)
+	return body.String()
+}
+
+// logSender writes emails to the log, used when SMTP is not configured.
+type logSender struct{}
+
+// linkToken matches token param of links in the message.
+var linkToken = regexp.MustCompile(`token=[^&\s]+`)
+
+// Send will log the message. Tokens of links are redacted so that links can't be used by log readers.
+func (s *logSender) Send(message *Message) error {
+	log.GetLogger().Infof("email to %s: %s\n%s", strings.Join(message.To, ", "), message.Subject,
+		linkToken.ReplaceAllString(message.Body, "token=REDACTED"))
+	for _, attachment := range message.Attachments {
+		log.GetLogger().Infof("email attachment %s (%d bytes)", attachment.Filename, len(attachment.Data))
+	}
+	return nil
+}
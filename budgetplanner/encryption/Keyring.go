@@ -0,0 +1,172 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
+)
+
+// prefix marks value encrypted by keyring, it is followed by version of the key and encrypted value
+// as enc:v<version>:<base64 of nonce and ciphertext>. Values without prefix are treated as plain text.
+const prefix = "enc:v"
+
+// keyring contains AES-GCM ciphers by version along with version of the key used for encrypting new values.
+type keyring struct {
+	sync.RWMutex
+	ciphers       map[int]cipher.AEAD
+	activeVersion int
+}
+
+var keys = &keyring{}
+
+// Configure will load keys from ENCRYPTION_KEY. It contains comma separated keys as <version>:<secret>,
+// first key is used for encrypting new values and rest are used only for decrypting. Secret without
+// version is key of version 1. Secrets are stretched to 256 bit AES keys with SHA-256.
+// Keys are rotated by adding a new key in front and removing the old key once ReEncrypt has run.
+func Configure(conf config.ConfReader) error {
+	entries := strings.Split(conf.GetString(config.ENCRYPTION_KEY), ",")
+
+	ciphers := make(map[int]cipher.AEAD, len(entries))
+	activeVersion := 0
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+
+		version, secret := 1, entry
+		if index := strings.Index(entry, ":"); index > 0 {
+			parsed, err := strconv.Atoi(entry[:index])
+			if err == nil {
+				version, secret = parsed, entry[index+1:]
+			}
+		}
+
+		if version <= 0 || len(secret) == 0 {
+			return fmt.Errorf("invalid encryption key of version %d", version)
+		}
+		if _, ok := ciphers[version]; ok {
+			return fmt.Errorf("encryption key of version %d is specified more than once", version)
+		}
+
+		key := sha256.Sum256([]byte(secret))
+
+		block, err := aes.NewCipher(key[:])
+		if err != nil {
+			return err
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return err
+		}
+
+		ciphers[version] = aead
+		if activeVersion == 0 {
+			activeVersion = version
+		}
+	}
+
+	if activeVersion == 0 {
+		return fmt.Errorf("%s is not set", config.ENCRYPTION_KEY)
+	}
+
+	keys.Lock()
+	defer keys.Unlock()
+
+	keys.ciphers = ciphers
+	keys.activeVersion = activeVersion
+	return nil
+}
+
+// Encrypt will encrypt value with the active key.
+func Encrypt(value string) (string, error) {
+	keys.RLock()
+	defer keys.RUnlock()
+
+	aead, ok := keys.ciphers[keys.activeVersion]
+	if !ok {
+		return "", fmt.Errorf("encryption key is not configured")
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(value), nil)
+	return prefix + strconv.Itoa(keys.activeVersion) + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt will decrypt value with the key it was encrypted with. Plain text value is returned as it is
+// so that columns can be encrypted without downtime.
+func Decrypt(value string) (string, error) {
+	version, encoded, ok := parse(value)
+	if !ok {
+		return value, nil
+	}
+
+	keys.RLock()
+	aead, ok := keys.ciphers[version]
+	keys.RUnlock()
+
+	if !ok {
+		return "", fmt.Errorf("encryption key of version %d is not configured", version)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("encrypted value is too short")
+	}
+
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// IsCurrent will return true if value is encrypted with the active key.
+func IsCurrent(value string) bool {
+	version, _, ok := parse(value)
+
+	keys.RLock()
+	defer keys.RUnlock()
+
+	return ok && version == keys.activeVersion
+}
+
+// parse will split encrypted value into version of its key and encoded ciphertext.
+// It returns false if value is plain text.
+func parse(value string) (int, string, bool) {
+	if !strings.HasPrefix(value, prefix) {
+		return 0, "", false
+	}
+
+	rest := value[len(prefix):]
+
+	index := strings.Index(rest, ":")
+	if index <= 0 {
+		return 0, "", false
+	}
+
+	version, err := strconv.Atoi(rest[:index])
+	if err != nil {
+		return 0, "", false
+	}
+	return version, rest[index+1:], true
+}
@@ -0,0 +1,122 @@
+package encryption
+
+import (
+	"strings"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	"gorm.io/gorm"
+)
+
+// reEncryptBatchSize is number of rows read at a time while re-encrypting a table.
+const reEncryptBatchSize = 500
+
+// Table contains name of table and its encrypted columns.
+type Table struct {
+	Name    string
+	Columns []string
+}
+
+// ReEncrypt will encrypt values of columns which are in plain text or encrypted with an old key using
+// the active key. Rows are updated one at a time and only if they still hold the value that was read,
+// so that it can be run while the app is serving requests. It returns number of rows updated.
+func ReEncrypt(db *gorm.DB, tables []Table) (int, error) {
+	updated := 0
+
+	for _, table := range tables {
+		count, err := reEncryptTable(db, table)
+		updated += count
+		if err != nil {
+			return updated, err
+		}
+		log.GetLogger().Infof("Re-encrypted %d rows of %s", count, table.Name)
+	}
+	return updated, nil
+}
+
+// reEncryptTable will re-encrypt columns of the table in batches ordered by id.
+func reEncryptTable(db *gorm.DB, table Table) (int, error) {
+	updated := 0
+	lastID := ""
+
+	selects := append([]string{"`id`"}, quote(table.Columns)...)
+
+	for {
+		var rows []map[string]interface{}
+
+		err := db.Table(table.Name).Select(strings.Join(selects, ", ")).Where("`id` > ?", lastID).
+			Order("`id`").Limit(reEncryptBatchSize).Find(&rows).Error
+		if err != nil {
+			return updated, err
+		}
+
+		for _, row := range rows {
+			lastID = asString(row["id"])
+
+			changes := map[string]interface{}{}
+			query := db.Table(table.Name).Where("`id` = ?", lastID)
+
+			for _, column := range table.Columns {
+				if row[column] == nil {
+					continue
+				}
+
+				stored := asString(row[column])
+				if IsCurrent(stored) {
+					continue
+				}
+
+				// row is updated only if column was not written since it was read.
+				query = query.Where("`"+column+"` = ?", stored)
+
+				plain, err := Decrypt(stored)
+				if err != nil {
+					return updated, err
+				}
+
+				changes[column], err = Encrypt(plain)
+				if err != nil {
+					return updated, err
+				}
+			}
+
+			if len(changes) == 0 {
+				continue
+			}
+
+			result := query.UpdateColumns(changes)
+			if result.Error != nil {
+				return updated, result.Error
+			}
+
+			// row was changed by the app in the meantime, the app writes it with the active key.
+			if result.RowsAffected == 0 {
+				continue
+			}
+			updated++
+		}
+
+		if len(rows) < reEncryptBatchSize {
+			return updated, nil
+		}
+	}
+}
+
+// quote will quote names of columns.
+func quote(columns []string) []string {
+	quoted := make([]string, 0, len(columns))
+	for _, column := range columns {
+		quoted = append(quoted, "`"+column+"`")
+	}
+	return quoted
+}
+
+// asString will convert value read from database to string.
+func asString(value interface{}) string {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package encryption
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// String is string column which is stored encrypted with AES-GCM and decrypted when it is read.
+// Encrypted columns can't be searched or sorted in queries. Values of String must be used in maps
+// passed to UpdateWithMap, plain strings in maps are stored as they are.
+type String string
+
+// Value will encrypt the string with the active key.
+func (s String) Value() (driver.Value, error) {
+	return Encrypt(string(s))
+}
+
+// Scan will decrypt value read from database.
+func (s *String) Scan(value interface{}) error {
+	var stored string
+
+	switch v := value.(type) {
+	case nil:
+		*s = ""
+		return nil
+	case []byte:
+		stored = string(v)
+	case string:
+		stored = v
+	default:
+		return fmt.Errorf("cannot scan %T into encrypted string", value)
+	}
+
+	plain, err := Decrypt(stored)
+	if err != nil {
+		return err
+	}
+
+	*s = String(plain)
+	return nil
+}
@@ -0,0 +1,218 @@
+package controller
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/envelop/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	envelopModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/envelop"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// multipartOverhead is allowed size of multipart body apart from the uploaded file.
+const multipartOverhead = 64 << 10
+
+// AttachmentController provides methods to attach receipts to transactions.
+type AttachmentController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	addAttachment(ctx *gin.Context)
+	getAttachments(ctx *gin.Context)
+	getAttachmentFile(ctx *gin.Context)
+	deleteAttachment(ctx *gin.Context)
+}
+
+// attachmentController.
+type attachmentController struct {
+	service service.AttachmentService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewAttachmentController create new AttachmentController
+func NewAttachmentController(ser service.AttachmentService, log log.Logger,
+	auth *security.Authentication) AttachmentController {
+	return &attachmentController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for attachment controller.
+func (c *attachmentController) RegisterRoutes(router *gin.RouterGroup) {
+
+	guarded := router.Group("/users", c.auth.ScopedMiddleware(), c.auth.AuthorizeUser())
+
+	guarded.POST("/:userID/budgets/:budgetID/transactions/:transactionID/attachments", c.auth.RequireScope(userModel.ScopeTransactionsWrite), c.addAttachment)
+	guarded.GET("/:userID/budgets/:budgetID/transactions/:transactionID/attachments", c.auth.RequireScope(userModel.ScopeRead), c.getAttachments)
+	guarded.GET("/:userID/budgets/:budgetID/transactions/:transactionID/attachments/:attachmentID", c.auth.RequireScope(userModel.ScopeRead), c.getAttachmentFile)
+	guarded.DELETE("/:userID/budgets/:budgetID/transactions/:transactionID/attachments/:attachmentID", c.auth.RequireScope(userModel.ScopeTransactionsWrite), c.deleteAttachment)
+}
+
+// addAttachment will attach file of multipart field file to the transaction.
+func (c *attachmentController) addAttachment(ctx *gin.Context) {
+
+	upload := envelopModel.AttachmentUpload{}
+
+	err := c.parseAttachment(ctx, &upload.Attachment, false)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	maxSize := c.service.MaxUploadSize()
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxSize+multipartOverhead)
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest,
+			fmt.Sprintf("File of at most %d bytes must be uploaded as multipart field file", maxSize))
+		return
+	}
+
+	upload.FileName = header.Filename
+
+	err = upload.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondError(ctx, errors.NewDataReadWriteError(err))
+		return
+	}
+	defer file.Close()
+
+	upload.Data, err = io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		c.log.Error(err)
+		web.RespondError(ctx, errors.NewDataReadWriteError(err))
+		return
+	}
+
+	err = c.service.AddAttachment(ctx.Request.Context(), &upload)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusCreated, upload.ID)
+}
+
+// getAttachments will fetch attachments of the transaction.
+func (c *attachmentController) getAttachments(ctx *gin.Context) {
+
+	attachments := []envelopModel.AttachmentDTO{}
+	attachment := envelopModel.Attachment{}
+
+	err := c.parseAttachment(ctx, &attachment, false)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetAttachments(ctx.Request.Context(), &attachments, &attachment)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, attachments)
+}
+
+// getAttachmentFile will download content of the attachment.
+func (c *attachmentController) getAttachmentFile(ctx *gin.Context) {
+
+	file := envelopModel.AttachmentFile{}
+
+	err := c.parseAttachment(ctx, &file.Attachment, true)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetAttachmentFile(ctx.Request.Context(), &file)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+	defer file.Content.Close()
+
+	ctx.DataFromReader(http.StatusOK, file.Size, file.ContentType, file.Content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": file.FileName}),
+		"Cache-Control":          "private, no-cache",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// deleteAttachment will permanently delete the attachment.
+func (c *attachmentController) deleteAttachment(ctx *gin.Context) {
+
+	attachment := envelopModel.Attachment{}
+
+	err := c.parseAttachment(ctx, &attachment, true)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.DeleteAttachment(ctx.Request.Context(), &attachment)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// parseAttachment will set user, budget and transaction of the attachment from path params.
+// ID of the attachment is parsed only when withID is set.
+func (c *attachmentController) parseAttachment(ctx *gin.Context, attachment *envelopModel.Attachment,
+	withID bool) error {
+
+	parser := web.NewParser(ctx)
+	var err error
+
+	attachment.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		return err
+	}
+
+	attachment.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		return err
+	}
+
+	attachment.TransactionID, err = parser.GetUUID("transactionID")
+	if err != nil {
+		return err
+	}
+
+	if withID {
+		attachment.ID, err = parser.GetUUID("attachmentID")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
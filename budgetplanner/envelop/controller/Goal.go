@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/envelop/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	envelopModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/envelop"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// GoalController provides methods to manage savings goals of envelops.
+type GoalController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	setGoal(ctx *gin.Context)
+	deleteGoal(ctx *gin.Context)
+	getUnderfundedReport(ctx *gin.Context)
+}
+
+// goalController.
+type goalController struct {
+	service service.GoalService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewGoalController create new GoalController
+func NewGoalController(ser service.GoalService, log log.Logger,
+	auth *security.Authentication) GoalController {
+	return &goalController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for goal controller.
+func (c *goalController) RegisterRoutes(router *gin.RouterGroup) {
+
+	guarded := router.Group("/users", c.auth.ScopedMiddleware(), c.auth.AuthorizeUser())
+
+	guarded.PUT("/:userID/budgets/:budgetID/envelops/:envelopID/goal", c.auth.RequireScope(userModel.ScopeEnvelopsWrite), c.setGoal)
+	guarded.DELETE("/:userID/budgets/:budgetID/envelops/:envelopID/goal", c.auth.RequireScope(userModel.ScopeEnvelopsWrite), c.deleteGoal)
+	guarded.GET("/:userID/budgets/:budgetID/goals/underfunded", c.auth.RequireScope(userModel.ScopeRead), c.getUnderfundedReport)
+}
+
+// setGoal will set goal of specified envelop.
+func (c *goalController) setGoal(ctx *gin.Context) {
+
+	goal := envelopModel.Goal{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &goal)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	goal.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	goal.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	goal.EnvelopID, err = parser.GetUUID("envelopID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = goal.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.SetGoal(ctx.Request.Context(), &goal)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, goal)
+}
+
+// deleteGoal will remove goal of specified envelop.
+func (c *goalController) deleteGoal(ctx *gin.Context) {
+
+	goal := envelopModel.Goal{}
+	parser := web.NewParser(ctx)
+	var err error
+
+	goal.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	goal.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	goal.EnvelopID, err = parser.GetUUID("envelopID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.DeleteGoal(ctx.Request.Context(), &goal)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// getUnderfundedReport will fetch goals of the budget which are short of contribution required in
+// current budget period.
+func (c *goalController) getUnderfundedReport(ctx *gin.Context) {
+
+	report := envelopModel.UnderfundedReport{}
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	budgetID, err := parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetUnderfundedReport(ctx.Request.Context(), &report, userID, budgetID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, report)
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/envelop/service"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	auditModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/audit"
 	envelopModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/envelop"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
 )
@@ -19,6 +21,8 @@ type TransactionController interface {
 	updateTransaction(ctx *gin.Context)
 	deleteTransaction(ctx *gin.Context)
 	getUserTransaction(ctx *gin.Context)
+	getTransactionHistory(ctx *gin.Context)
+	revertTransaction(ctx *gin.Context)
 }
 
 // transactionController.
@@ -41,12 +45,14 @@ func NewTransactionController(ser service.TransactionService, log log.Logger,
 // RegisterRoutes will register routes for authentication controller.
 func (c *transactionController) RegisterRoutes(router *gin.RouterGroup) {
 
-	guarded := router.Group("/users", c.auth.Middleware())
+	guarded := router.Group("/users", c.auth.ScopedMiddleware(), c.auth.AuthorizeUser())
 
-	guarded.POST("/:userID/transactions", c.addTransaction)
-	guarded.PUT("/:userID/transactions/:transactionID", c.updateTransaction)
-	guarded.DELETE("/:userID/transactions/:transactionID", c.deleteTransaction)
-	guarded.GET("/:userID/transactions", c.getUserTransaction)
+	guarded.POST("/:userID/budgets/:budgetID/transactions", c.auth.RequireScope(userModel.ScopeTransactionsWrite), c.addTransaction)
+	guarded.PUT("/:userID/budgets/:budgetID/transactions/:transactionID", c.auth.RequireScope(userModel.ScopeTransactionsWrite), c.updateTransaction)
+	guarded.DELETE("/:userID/budgets/:budgetID/transactions/:transactionID", c.auth.RequireScope(userModel.ScopeTransactionsWrite), c.deleteTransaction)
+	guarded.GET("/:userID/budgets/:budgetID/transactions", c.auth.RequireScope(userModel.ScopeRead), c.getUserTransaction)
+	guarded.GET("/:userID/budgets/:budgetID/transactions/:transactionID/history", c.auth.RequireScope(userModel.ScopeRead), c.getTransactionHistory)
+	guarded.POST("/:userID/budgets/:budgetID/transactions/:transactionID/history/:version/revert", c.auth.RequireScope(userModel.ScopeTransactionsWrite), c.revertTransaction)
 }
 
 // addTransaction will add new transaction for user.
@@ -69,7 +75,12 @@ func (c *transactionController) addTransaction(ctx *gin.Context) {
 		return
 	}
 
-	// transaction.CreatedBy = transaction.UserID
+	transaction.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	err = transaction.Validate()
 	if err != nil {
@@ -78,7 +89,7 @@ func (c *transactionController) addTransaction(ctx *gin.Context) {
 		return
 	}
 
-	err = c.service.AddTransaction(&transaction)
+	err = c.service.AddTransaction(ctx.Request.Context(), &transaction)
 	if err != nil {
 		c.log.Error(err)
 		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
@@ -108,14 +119,19 @@ func (c *transactionController) updateTransaction(ctx *gin.Context) {
 		return
 	}
 
-	transaction.ID, err = parser.GetUUID("transactionID")
+	transaction.BudgetID, err = parser.GetUUID("budgetID")
 	if err != nil {
 		c.log.Error(err)
 		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// transaction.UpdatedBy = transaction.UserID
+	transaction.ID, err = parser.GetUUID("transactionID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	err = transaction.Validate()
 	if err != nil {
@@ -124,7 +140,7 @@ func (c *transactionController) updateTransaction(ctx *gin.Context) {
 		return
 	}
 
-	err = c.service.UpdateTransaction(&transaction)
+	err = c.service.UpdateTransaction(ctx.Request.Context(), &transaction)
 	if err != nil {
 		c.log.Error(err)
 		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
@@ -148,16 +164,21 @@ func (c *transactionController) deleteTransaction(ctx *gin.Context) {
 		return
 	}
 
-	transaction.ID, err = parser.GetUUID("transactionID")
+	transaction.BudgetID, err = parser.GetUUID("budgetID")
 	if err != nil {
 		c.log.Error(err)
 		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// transaction.DeletedBy = transaction.UserID
+	transaction.ID, err = parser.GetUUID("transactionID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	err = c.service.DeleteTransaction(&transaction)
+	err = c.service.DeleteTransaction(ctx.Request.Context(), &transaction)
 	if err != nil {
 		c.log.Error(err)
 		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
@@ -180,9 +201,16 @@ func (c *transactionController) getUserTransaction(ctx *gin.Context) {
 		return
 	}
 
+	budgetID, err := parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	var totalCount int64
 
-	err = c.service.GetUserTransaction(&transactions, userID, &totalCount, parser)
+	err = c.service.GetUserTransaction(ctx.Request.Context(), &transactions, userID, budgetID, &totalCount, parser)
 	if err != nil {
 		c.log.Error(err)
 		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
@@ -191,3 +219,87 @@ func (c *transactionController) getUserTransaction(ctx *gin.Context) {
 
 	web.RespondJSONWithXTotalCount(ctx, http.StatusOK, int(totalCount), transactions)
 }
+
+// getTransactionHistory will fetch all versions of specified transaction.
+func (c *transactionController) getTransactionHistory(ctx *gin.Context) {
+
+	var history []auditModel.ChangeLogDTO
+	transaction := envelopModel.Transaction{}
+	parser := web.NewParser(ctx)
+	var err error
+
+	transaction.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	transaction.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	transaction.ID, err = parser.GetUUID("transactionID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetTransactionHistory(ctx.Request.Context(), &history, &transaction)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, history)
+}
+
+// revertTransaction will revert specified transaction to the specified version.
+func (c *transactionController) revertTransaction(ctx *gin.Context) {
+
+	transaction := envelopModel.Transaction{}
+	parser := web.NewParser(ctx)
+	var err error
+
+	transaction.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	transaction.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	transaction.ID, err = parser.GetUUID("transactionID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	version, err := parser.GetInt("version")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.RevertTransaction(ctx.Request.Context(), &transaction, version)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/envelop/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	envelopModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/envelop"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// TrashController provides methods to list, restore and purge deleted envelops and transactions.
+type TrashController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	getTrash(ctx *gin.Context)
+	restoreEnvelop(ctx *gin.Context)
+	restoreTransaction(ctx *gin.Context)
+	purgeEnvelop(ctx *gin.Context)
+	purgeTransaction(ctx *gin.Context)
+}
+
+// trashController.
+type trashController struct {
+	service service.TrashService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewTrashController create new TrashController
+func NewTrashController(ser service.TrashService, log log.Logger,
+	auth *security.Authentication) TrashController {
+	return &trashController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for trash controller.
+func (c *trashController) RegisterRoutes(router *gin.RouterGroup) {
+
+	guarded := router.Group("/users", c.auth.ScopedMiddleware(), c.auth.AuthorizeUser())
+
+	guarded.GET("/:userID/budgets/:budgetID/trash", c.auth.RequireScope(userModel.ScopeRead), c.getTrash)
+	guarded.POST("/:userID/budgets/:budgetID/trash/envelops/:envelopID/restore", c.auth.RequireScope(userModel.ScopeEnvelopsWrite), c.restoreEnvelop)
+	guarded.POST("/:userID/budgets/:budgetID/trash/transactions/:transactionID/restore", c.auth.RequireScope(userModel.ScopeTransactionsWrite), c.restoreTransaction)
+	guarded.DELETE("/:userID/budgets/:budgetID/trash/envelops/:envelopID", c.auth.RequireScope(userModel.ScopeEnvelopsWrite), c.purgeEnvelop)
+	guarded.DELETE("/:userID/budgets/:budgetID/trash/transactions/:transactionID", c.auth.RequireScope(userModel.ScopeTransactionsWrite), c.purgeTransaction)
+}
+
+// getTrash will fetch recently deleted envelops and transactions of user.
+func (c *trashController) getTrash(ctx *gin.Context) {
+
+	trash := envelopModel.Trash{}
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	budgetID, err := parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetTrash(ctx.Request.Context(), &trash, userID, budgetID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, trash)
+}
+
+// restoreEnvelop will restore specified envelop along with its transactions.
+func (c *trashController) restoreEnvelop(ctx *gin.Context) {
+
+	envelop, err := c.parseEnvelop(web.NewParser(ctx))
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.RestoreEnvelop(ctx.Request.Context(), &envelop)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// restoreTransaction will restore specified transaction.
+func (c *trashController) restoreTransaction(ctx *gin.Context) {
+
+	transaction, err := c.parseTransaction(web.NewParser(ctx))
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.RestoreTransaction(ctx.Request.Context(), &transaction)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// purgeEnvelop will permanently delete specified envelop.
+func (c *trashController) purgeEnvelop(ctx *gin.Context) {
+
+	envelop, err := c.parseEnvelop(web.NewParser(ctx))
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.PurgeEnvelop(ctx.Request.Context(), &envelop)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// purgeTransaction will permanently delete specified transaction.
+func (c *trashController) purgeTransaction(ctx *gin.Context) {
+
+	transaction, err := c.parseTransaction(web.NewParser(ctx))
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.PurgeTransaction(ctx.Request.Context(), &transaction)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// parseEnvelop will parse user and envelop IDs from URL params.
+func (c *trashController) parseEnvelop(parser *web.Parser) (envelopModel.Envelop, error) {
+
+	envelop := envelopModel.Envelop{}
+	var err error
+
+	envelop.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		return envelop, err
+	}
+
+	envelop.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		return envelop, err
+	}
+
+	envelop.ID, err = parser.GetUUID("envelopID")
+	if err != nil {
+		return envelop, err
+	}
+
+	return envelop, nil
+}
+
+// parseTransaction will parse user and transaction IDs from URL params.
+func (c *trashController) parseTransaction(parser *web.Parser) (envelopModel.Transaction, error) {
+
+	transaction := envelopModel.Transaction{}
+	var err error
+
+	transaction.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		return transaction, err
+	}
+
+	transaction.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		return transaction, err
+	}
+
+	transaction.ID, err = parser.GetUUID("transactionID")
+	if err != nil {
+		return transaction, err
+	}
+
+	return transaction, nil
+}
@@ -4,9 +4,12 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/envelop/service"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	auditModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/audit"
 	envelopModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/envelop"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
 )
@@ -18,6 +21,8 @@ type EnvelopController interface {
 	updateEnvelop(ctx *gin.Context)
 	deleteEnvelop(ctx *gin.Context)
 	getEnvelops(ctx *gin.Context)
+	getEnvelopHistory(ctx *gin.Context)
+	revertEnvelop(ctx *gin.Context)
 }
 
 // envelopController.
@@ -40,12 +45,14 @@ func NewEnvelopController(ser service.EnvelopService, log log.Logger,
 // RegisterRoutes will register routes for authentication controller.
 func (c *envelopController) RegisterRoutes(router *gin.RouterGroup) {
 
-	guarded := router.Group("/users", c.auth.Middleware())
+	guarded := router.Group("/users", c.auth.ScopedMiddleware(), c.auth.AuthorizeUser())
 
-	guarded.POST("/:userID/envelops", c.addEnvelop)
-	guarded.PUT("/:userID/envelops/:envelopID", c.updateEnvelop)
-	guarded.DELETE("/:userID/envelops/:envelopID", c.deleteEnvelop)
-	guarded.GET("/:userID/envelops", c.getEnvelops)
+	guarded.POST("/:userID/budgets/:budgetID/envelops", c.auth.RequireScope(userModel.ScopeEnvelopsWrite), c.addEnvelop)
+	guarded.PUT("/:userID/budgets/:budgetID/envelops/:envelopID", c.auth.RequireScope(userModel.ScopeEnvelopsWrite), c.updateEnvelop)
+	guarded.DELETE("/:userID/budgets/:budgetID/envelops/:envelopID", c.auth.RequireScope(userModel.ScopeEnvelopsWrite), c.deleteEnvelop)
+	guarded.GET("/:userID/budgets/:budgetID/envelops", c.auth.RequireScope(userModel.ScopeRead), c.getEnvelops)
+	guarded.GET("/:userID/budgets/:budgetID/envelops/:envelopID/history", c.auth.RequireScope(userModel.ScopeRead), c.getEnvelopHistory)
+	guarded.POST("/:userID/budgets/:budgetID/envelops/:envelopID/history/:version/revert", c.auth.RequireScope(userModel.ScopeEnvelopsWrite), c.revertEnvelop)
 }
 
 // addEnvelop will add new envelop for specified user.
@@ -68,7 +75,12 @@ func (c *envelopController) addEnvelop(ctx *gin.Context) {
 		return
 	}
 
-	// envelop.CreatedBy = envelop.UserID
+	envelop.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	err = envelop.Validate()
 	if err != nil {
@@ -77,7 +89,7 @@ func (c *envelopController) addEnvelop(ctx *gin.Context) {
 		return
 	}
 
-	err = c.service.AddEnvelop(&envelop)
+	err = c.service.AddEnvelop(ctx.Request.Context(), &envelop)
 	if err != nil {
 		c.log.Error(err)
 		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
@@ -107,14 +119,19 @@ func (c *envelopController) updateEnvelop(ctx *gin.Context) {
 		return
 	}
 
-	envelop.ID, err = parser.GetUUID("envelopID")
+	envelop.BudgetID, err = parser.GetUUID("budgetID")
 	if err != nil {
 		c.log.Error(err)
 		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// envelop.UpdatedBy = envelop.UserID
+	envelop.ID, err = parser.GetUUID("envelopID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	err = envelop.Validate()
 	if err != nil {
@@ -123,7 +140,7 @@ func (c *envelopController) updateEnvelop(ctx *gin.Context) {
 		return
 	}
 
-	err = c.service.UpdateEnvelop(&envelop)
+	err = c.service.UpdateEnvelop(ctx.Request.Context(), &envelop)
 	if err != nil {
 		c.log.Error(err)
 		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
@@ -133,7 +150,8 @@ func (c *envelopController) updateEnvelop(ctx *gin.Context) {
 	web.RespondJSON(ctx, http.StatusAccepted, nil)
 }
 
-// deleteEnvelop will delete specified envelop.
+// deleteEnvelop will delete specified envelop. Transactions of envelop are handled
+// as per "transactions" query param (refuse, reassign or delete).
 func (c *envelopController) deleteEnvelop(ctx *gin.Context) {
 
 	envelop := envelopModel.Envelop{}
@@ -147,6 +165,13 @@ func (c *envelopController) deleteEnvelop(ctx *gin.Context) {
 		return
 	}
 
+	envelop.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	envelop.ID, err = parser.GetUUID("envelopID")
 	if err != nil {
 		c.log.Error(err)
@@ -154,9 +179,28 @@ func (c *envelopController) deleteEnvelop(ctx *gin.Context) {
 		return
 	}
 
-	// envelop.DeletedBy = envelop.UserID
+	deletion := envelopModel.EnvelopDeletion{
+		Transactions: parser.Form.Get("transactions"),
+	}
+
+	if reassignTo := parser.Form.Get("reassignTo"); len(reassignTo) > 0 {
+		envelopID, err := uuid.Parse(reassignTo)
+		if err != nil {
+			c.log.Error(err)
+			web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+			return
+		}
+		deletion.ReassignTo = &envelopID
+	}
+
+	err = deletion.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	err = c.service.DeleteEnvelop(&envelop)
+	err = c.service.DeleteEnvelop(ctx.Request.Context(), &envelop, &deletion)
 	if err != nil {
 		c.log.Error(err)
 		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
@@ -179,7 +223,14 @@ func (c *envelopController) getEnvelops(ctx *gin.Context) {
 		return
 	}
 
-	err = c.service.GetEnvelops(&envelops, userID)
+	budgetID, err := parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetEnvelops(ctx.Request.Context(), &envelops, userID, budgetID)
 	if err != nil {
 		c.log.Error(err)
 		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
@@ -188,3 +239,87 @@ func (c *envelopController) getEnvelops(ctx *gin.Context) {
 
 	web.RespondJSON(ctx, http.StatusOK, envelops)
 }
+
+// getEnvelopHistory will fetch all versions of specified envelop.
+func (c *envelopController) getEnvelopHistory(ctx *gin.Context) {
+
+	var history []auditModel.ChangeLogDTO
+	envelop := envelopModel.Envelop{}
+	parser := web.NewParser(ctx)
+	var err error
+
+	envelop.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	envelop.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	envelop.ID, err = parser.GetUUID("envelopID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetEnvelopHistory(ctx.Request.Context(), &history, &envelop)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, history)
+}
+
+// revertEnvelop will revert specified envelop to the specified version.
+func (c *envelopController) revertEnvelop(ctx *gin.Context) {
+
+	envelop := envelopModel.Envelop{}
+	parser := web.NewParser(ctx)
+	var err error
+
+	envelop.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	envelop.BudgetID, err = parser.GetUUID("budgetID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	envelop.ID, err = parser.GetUUID("envelopID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	version, err := parser.GetInt("version")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.RevertEnvelop(ctx.Request.Context(), &envelop, version)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
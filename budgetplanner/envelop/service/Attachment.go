@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	budgetService "github.com/shaileshhb/budget-planner-go/budgetplanner/budget/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
+	envelopModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/envelop"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/storage"
+	"gorm.io/gorm"
+)
+
+// AttachmentService provides methods to attach receipts to transactions.
+type AttachmentService interface {
+	AddAttachment(ctx context.Context, upload *envelopModel.AttachmentUpload) error
+	GetAttachments(ctx context.Context, attachments *[]envelopModel.AttachmentDTO,
+		attachment *envelopModel.Attachment) error
+	GetAttachmentFile(ctx context.Context, file *envelopModel.AttachmentFile) error
+	DeleteAttachment(ctx context.Context, attachment *envelopModel.Attachment) error
+	MaxUploadSize() int64
+}
+
+// attachmentService
+type attachmentService struct {
+	db         *gorm.DB
+	repo       repository.Repository
+	conf       config.ConfReader
+	store      storage.Storage
+	membership budgetService.MembershipService
+}
+
+// NewAttachmentService create new attachment service.
+func NewAttachmentService(db *gorm.DB, repo repository.Repository, conf config.ConfReader, store storage.Storage,
+	membership budgetService.MembershipService) AttachmentService {
+	return &attachmentService{
+		db:         db,
+		repo:       repo,
+		conf:       conf,
+		store:      store,
+		membership: membership,
+	}
+}
+
+// AddAttachment will store uploaded file and attach it to the transaction.
+// Type of the file is detected from its content and only images and PDFs are accepted.
+func (ser *attachmentService) AddAttachment(ctx context.Context, upload *envelopModel.AttachmentUpload) error {
+
+	err := ser.membership.Authorize(ctx, upload.UserID, upload.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	if int64(len(upload.Data)) > ser.MaxUploadSize() {
+		return errors.NewValidationError(fmt.Sprintf("File must not be larger than %d bytes", ser.MaxUploadSize()))
+	}
+
+	upload.ContentType = http.DetectContentType(upload.Data)
+	if !envelopModel.AttachmentContentTypes[upload.ContentType] {
+		return errors.NewValidationError("Only images and PDF files can be attached")
+	}
+
+	err = ser.validateTransactionID(ctx, upload.BudgetID, upload.TransactionID)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	var totalCount int64
+
+	err = ser.repo.GetCount(uow, envelopModel.Attachment{}, &totalCount,
+		repository.Filter("attachments.`transaction_id` = ?", upload.TransactionID))
+	if err != nil {
+		return err
+	}
+
+	if totalCount >= envelopModel.MaxAttachmentsPerTransaction {
+		return errors.NewValidationError(fmt.Sprintf("Transaction can have at most %d attachments",
+			envelopModel.MaxAttachmentsPerTransaction))
+	}
+
+	attachment := &upload.Attachment
+	attachment.Size = int64(len(upload.Data))
+
+	// ID of attachment is assigned on adding it, key is updated once it is known.
+	err = ser.repo.Add(uow, attachment)
+	if err != nil {
+		return err
+	}
+
+	attachment.StorageKey = attachment.BuildStorageKey()
+
+	err = ser.repo.UpdateWithMap(uow, &envelopModel.Attachment{}, map[string]interface{}{
+		"StorageKey": attachment.StorageKey,
+	}, repository.Filter("attachments.`id` = ?", attachment.ID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.store.Put(ctx, attachment.StorageKey, attachment.ContentType, upload.Data)
+	if err != nil {
+		return err
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		// attachment was not saved, so stored file would never be referred to.
+		deleteFiles(ser.store, []string{attachment.StorageKey})
+		return err
+	}
+	return nil
+}
+
+// GetAttachments will fetch attachments of the transaction.
+func (ser *attachmentService) GetAttachments(ctx context.Context, attachments *[]envelopModel.AttachmentDTO,
+	attachment *envelopModel.Attachment) error {
+
+	err := ser.membership.Authorize(ctx, attachment.UserID, attachment.BudgetID, budgetModel.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	err = ser.validateTransactionID(ctx, attachment.BudgetID, attachment.TransactionID)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.GetAllInOrder(uow, attachments, "attachments.`created_at`",
+		repository.Filter("attachments.`transaction_id` = ?", attachment.TransactionID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// GetAttachmentFile will open content of the attachment.
+func (ser *attachmentService) GetAttachmentFile(ctx context.Context, file *envelopModel.AttachmentFile) error {
+
+	err := ser.membership.Authorize(ctx, file.UserID, file.BudgetID, budgetModel.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	err = ser.getAttachment(ctx, &file.Attachment)
+	if err != nil {
+		return err
+	}
+
+	file.Content, err = ser.store.Get(ctx, file.StorageKey)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteAttachment will permanently delete the attachment along with its file.
+func (ser *attachmentService) DeleteAttachment(ctx context.Context, attachment *envelopModel.Attachment) error {
+
+	err := ser.membership.Authorize(ctx, attachment.UserID, attachment.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	err = ser.getAttachment(ctx, attachment)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.Delete(uow, &envelopModel.Attachment{}, "`id` = ?", attachment.ID)
+	if err != nil {
+		return err
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		return err
+	}
+
+	deleteFiles(ser.store, []string{attachment.StorageKey})
+	return nil
+}
+
+// MaxUploadSize will return maximum size of attached file in bytes.
+func (ser *attachmentService) MaxUploadSize() int64 {
+	if ser.conf.IsSet(config.AttachmentMaxBytes) && ser.conf.GetInt64(config.AttachmentMaxBytes) > 0 {
+		return ser.conf.GetInt64(config.AttachmentMaxBytes)
+	}
+	return envelopModel.DefaultAttachmentMaxBytes
+}
+
+// getAttachment will fetch attachment of transaction which is not deleted.
+func (ser *attachmentService) getAttachment(ctx context.Context, attachment *envelopModel.Attachment) error {
+
+	err := ser.validateTransactionID(ctx, attachment.BudgetID, attachment.TransactionID)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.GetRecord(uow, attachment, repository.Filter("attachments.`id` = ? AND"+
+		" attachments.`transaction_id` = ?", attachment.ID, attachment.TransactionID))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewValidationError("Attachment not found")
+		}
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// validateTransactionID will verify if transaction exist in specified budget and is not deleted.
+func (ser *attachmentService) validateTransactionID(ctx context.Context, budgetID, transactionID uuid.UUID) error {
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), envelopModel.Transaction{},
+		repository.Filter("transactions.`id` = ? AND transactions.`budget_id` = ? AND transactions.`deleted_at` IS NULL",
+			transactionID, budgetID))
+	if err != nil {
+		return err
+	}
+
+	if !exist {
+		return errors.NewValidationError("Transaction not found")
+	}
+	return nil
+}
+
+// purgeAttachments will delete attachments of transactions matching the condition and return keys of their
+// files. Files must be deleted with deleteFiles only after the unit of work is committed.
+func purgeAttachments(uow *repository.UnitOfWork, repo repository.Repository, condition string,
+	args ...interface{}) ([]string, error) {
+
+	var attachments []envelopModel.Attachment
+
+	inTransactions := "attachments.`transaction_id` IN (SELECT `id` FROM `transactions` WHERE " + condition + ")"
+
+	err := repo.GetAll(uow, &attachments, repository.Filter(inTransactions, args...),
+		repository.Select("attachments.`id`, attachments.`storage_key`"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(attachments) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(attachments))
+	keys := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		ids = append(ids, attachment.ID)
+		keys = append(keys, attachment.StorageKey)
+	}
+
+	err = repo.Delete(uow, &envelopModel.Attachment{}, "`id` IN (?)", ids)
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// deleteFiles will delete files of purged or unsaved attachments. Files left behind on failure are only logged
+// as they are no longer referred to.
+func deleteFiles(store storage.Storage, keys []string) {
+	for _, key := range keys {
+		err := store.Delete(context.Background(), key)
+		if err != nil {
+			log.GetLogger().Error(err)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	budgetService "github.com/shaileshhb/budget-planner-go/budgetplanner/budget/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
+	envelopModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/envelop"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	userService "github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
+	"gorm.io/gorm"
+)
+
+// GoalService provides methods to manage savings goals of envelops.
+type GoalService interface {
+	SetGoal(ctx context.Context, goal *envelopModel.Goal) error
+	DeleteGoal(ctx context.Context, goal *envelopModel.Goal) error
+	GetUnderfundedReport(ctx context.Context, report *envelopModel.UnderfundedReport, userID, budgetID uuid.UUID) error
+}
+
+// goalService
+type goalService struct {
+	db          *gorm.DB
+	repo        repository.Repository
+	membership  budgetService.MembershipService
+	preferences userService.PreferenceService
+}
+
+// NewGoalService create new goal service.
+func NewGoalService(db *gorm.DB, repo repository.Repository, membership budgetService.MembershipService,
+	preferences userService.PreferenceService) GoalService {
+	return &goalService{
+		db:          db,
+		repo:        repo,
+		membership:  membership,
+		preferences: preferences,
+	}
+}
+
+// SetGoal will set goal of the envelop, replacing its current goal if any.
+// Target date given without offset is taken in timezone of the user.
+func (ser *goalService) SetGoal(ctx context.Context, goal *envelopModel.Goal) error {
+
+	err := ser.membership.Authorize(ctx, goal.UserID, goal.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	err = ser.validateEnvelopID(ctx, goal.BudgetID, goal.EnvelopID)
+	if err != nil {
+		return err
+	}
+
+	preference, err := ser.preferences.GetPreference(ctx, goal.UserID)
+	if err != nil {
+		return err
+	}
+
+	goal.TargetDate.Localize(preference.Location())
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	tempGoal := envelopModel.Goal{}
+
+	err = ser.repo.GetRecord(uow, &tempGoal, repository.Filter("envelop_goals.`envelop_id` = ?", goal.EnvelopID),
+		repository.Select("envelop_goals.`id`, envelop_goals.`user_id`"))
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	if err == gorm.ErrRecordNotFound {
+		err = ser.repo.Add(uow, goal)
+		if err != nil {
+			return err
+		}
+
+		return uow.Commit()
+	}
+
+	// user who set the goal first remains its creator.
+	goal.ID = tempGoal.ID
+	goal.UserID = tempGoal.UserID
+
+	err = ser.repo.UpdateWithMap(uow, &envelopModel.Goal{}, map[string]interface{}{
+		"TargetAmount":     goal.TargetAmount,
+		"TargetDate":       goal.TargetDate,
+		"ContributionType": goal.ContributionType,
+		"MonthlyAmount":    goal.MonthlyAmount,
+	}, repository.Filter("envelop_goals.`id` = ?", goal.ID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// DeleteGoal will remove goal of the envelop.
+func (ser *goalService) DeleteGoal(ctx context.Context, goal *envelopModel.Goal) error {
+
+	err := ser.membership.Authorize(ctx, goal.UserID, goal.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	err = ser.validateEnvelopID(ctx, goal.BudgetID, goal.EnvelopID)
+	if err != nil {
+		return err
+	}
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), envelopModel.Goal{},
+		repository.Filter("envelop_goals.`envelop_id` = ?", goal.EnvelopID))
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return errors.NewValidationError("Goal not found")
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.Delete(uow, &envelopModel.Goal{}, "`envelop_id` = ?", goal.EnvelopID)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// GetUnderfundedReport will fetch goals of the budget which have not received contribution required
+// in current budget period of the user, with largest shortfall first.
+func (ser *goalService) GetUnderfundedReport(ctx context.Context, report *envelopModel.UnderfundedReport,
+	userID, budgetID uuid.UUID) error {
+
+	err := ser.membership.Authorize(ctx, userID, budgetID, budgetModel.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	preference, err := ser.preferences.GetPreference(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	var envelops []envelopModel.EnvelopDTO
+
+	err = ser.repo.GetAll(uow, &envelops,
+		repository.Filter("envelops.`budget_id` = ? AND envelops.`deleted_at` IS NULL", budgetID),
+		repository.Select("envelops.`id`, envelops.`name`"))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	progress, err := getGoalProgress(uow, ser.repo, budgetID, preference, now)
+	if err != nil {
+		return err
+	}
+
+	report.PeriodStart, report.PeriodEnd = preference.Period(now)
+	report.Goals = []envelopModel.UnderfundedGoal{}
+
+	for _, envelop := range envelops {
+		goal, ok := progress[envelop.ID]
+		if !ok || !goal.IsUnderfunded() {
+			continue
+		}
+
+		report.Goals = append(report.Goals, envelopModel.UnderfundedGoal{
+			EnvelopName:  envelop.Name,
+			GoalProgress: *goal,
+		})
+		report.TotalShortfall += goal.Shortfall
+	}
+
+	sort.SliceStable(report.Goals, func(i, j int) bool {
+		return report.Goals[i].Shortfall > report.Goals[j].Shortfall
+	})
+
+	report.TotalShortfall = math.Round(report.TotalShortfall*100) / 100
+
+	return uow.Commit()
+}
+
+// validateEnvelopID will verify if envelop exist in specified budget and is not deleted.
+func (ser *goalService) validateEnvelopID(ctx context.Context, budgetID, envelopID uuid.UUID) error {
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), envelopModel.Envelop{},
+		repository.Filter("envelops.`id` = ? AND envelops.`budget_id` = ? AND envelops.`deleted_at` IS NULL",
+			envelopID, budgetID))
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return errors.NewValidationError("Envelop not found")
+	}
+	return nil
+}
+
+// getGoalProgress will compute progress of every goal of the budget in current budget period of the user.
+// Progress is mapped by ID of the envelop of the goal.
+func getGoalProgress(uow *repository.UnitOfWork, repo repository.Repository, budgetID uuid.UUID,
+	preference *userModel.Preference, now time.Time) (map[uuid.UUID]*envelopModel.GoalProgress, error) {
+
+	var goals []envelopModel.GoalDTO
+
+	err := repo.GetAll(uow, &goals, repository.Filter("envelop_goals.`budget_id` = ?", budgetID))
+	if err != nil {
+		return nil, err
+	}
+
+	periodStart, periodEnd := preference.Period(now)
+	progress := make(map[uuid.UUID]*envelopModel.GoalProgress, len(goals))
+
+	for index := range goals {
+		total := struct {
+			Saved       float64
+			Contributed float64
+		}{}
+
+		err = repo.Scan(uow, &total, repository.Model(&envelopModel.Transaction{}),
+			repository.Select("COALESCE(SUM(CASE WHEN transactions.`transaction_type` = ? THEN transactions.`amount`"+
+				" ELSE -transactions.`amount` END), 0) AS saved, COALESCE(SUM(CASE WHEN transactions.`date` >= ?"+
+				" AND transactions.`date` < ? THEN (CASE WHEN transactions.`transaction_type` = ?"+
+				" THEN transactions.`amount` ELSE -transactions.`amount` END) ELSE 0 END), 0) AS contributed",
+				envelopModel.TransactionTypeCredit, periodStart, periodEnd, envelopModel.TransactionTypeCredit),
+			repository.Filter("transactions.`envelop_id` = ? AND transactions.`budget_id` = ?"+
+				" AND transactions.`deleted_at` IS NULL", goals[index].EnvelopID, budgetID))
+		if err != nil {
+			return nil, err
+		}
+
+		progress[goals[index].EnvelopID] = envelopModel.NewGoalProgress(&goals[index], total.Saved,
+			total.Contributed, preference, now)
+	}
+
+	return progress, nil
+}
@@ -1,16 +1,23 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"time"
 
 	"github.com/google/uuid"
+	auditService "github.com/shaileshhb/budget-planner-go/budgetplanner/audit/service"
+	budgetService "github.com/shaileshhb/budget-planner-go/budgetplanner/budget/service"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	accountModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/account"
+	auditModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/audit"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
 	envelopModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/envelop"
 	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	userService "github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/util"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
 	"gorm.io/gorm"
@@ -18,43 +25,66 @@ import (
 
 // TransactionService service provides methods to update, delete, add, get method for TransactionService.
 type TransactionService interface {
-	AddTransaction(transaction *envelopModel.Transaction) error
-	UpdateTransaction(transaction *envelopModel.Transaction) error
-	DeleteTransaction(transaction *envelopModel.Transaction) error
-	GetUserTransaction(transactions *[]envelopModel.TransactionDTO,
-		userID uuid.UUID, totalCount *int64, parser *web.Parser) error
+	AddTransaction(ctx context.Context, transaction *envelopModel.Transaction) error
+	UpdateTransaction(ctx context.Context, transaction *envelopModel.Transaction) error
+	DeleteTransaction(ctx context.Context, transaction *envelopModel.Transaction) error
+	GetUserTransaction(ctx context.Context, transactions *[]envelopModel.TransactionDTO,
+		userID, budgetID uuid.UUID, totalCount *int64, parser *web.Parser) error
+	GetTransactionHistory(ctx context.Context, history *[]auditModel.ChangeLogDTO, transaction *envelopModel.Transaction) error
+	RevertTransaction(ctx context.Context, transaction *envelopModel.Transaction, version int) error
 }
 
 // transactionService
 type transactionService struct {
-	db   *gorm.DB
-	repo repository.Repository
-	auth *security.Authentication
+	db           *gorm.DB
+	repo         repository.Repository
+	auth         *security.Authentication
+	changeLogger auditService.ChangeLogger
+	membership   budgetService.MembershipService
+	preferences  userService.PreferenceService
 }
 
 // NewTransactionService create new envelop service.
-func NewTransactionService(db *gorm.DB, repo repository.Repository, auth *security.Authentication) TransactionService {
+func NewTransactionService(db *gorm.DB, repo repository.Repository, auth *security.Authentication,
+	changeLogger auditService.ChangeLogger, membership budgetService.MembershipService,
+	preferences userService.PreferenceService) TransactionService {
 	return &transactionService{
-		db:   db,
-		repo: repo,
-		auth: auth,
+		db:           db,
+		repo:         repo,
+		auth:         auth,
+		changeLogger: changeLogger,
+		membership:   membership,
+		preferences:  preferences,
 	}
 }
 
-// AddTransaction will add new transaction for user in specified envelop.
-func (ser *transactionService) AddTransaction(transaction *envelopModel.Transaction) error {
+// AddTransaction will add new transaction in specified envelop of the budget.
+func (ser *transactionService) AddTransaction(ctx context.Context, transaction *envelopModel.Transaction) error {
 
-	err := ser.validateUserID(transaction.UserID)
+	err := ser.membership.Authorize(ctx, transaction.UserID, transaction.BudgetID, budgetModel.RoleEditor)
 	if err != nil {
 		return err
 	}
 
-	err = ser.validateEnvelopID(transaction.UserID, transaction.EnvelopID)
+	err = ser.validateEnvelopID(ctx, transaction.BudgetID, transaction.EnvelopID)
 	if err != nil {
 		return err
 	}
 
-	uow := repository.NewUnitOfWork(ser.db)
+	err = ser.validateAccountID(ctx, transaction.BudgetID, transaction.AccountID)
+	if err != nil {
+		return err
+	}
+
+	err = ser.localizeDate(ctx, transaction.UserID, transaction)
+	if err != nil {
+		return err
+	}
+
+	// transaction can only be linked to a reconciliation by completing it.
+	transaction.ReconciliationID = nil
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
 	defer uow.RollBack()
 
 	err = ser.repo.Add(uow, transaction)
@@ -62,122 +92,285 @@ func (ser *transactionService) AddTransaction(transaction *envelopModel.Transact
 		return err
 	}
 
-	uow.Commit()
-	return nil
+	err = ser.recordChange(uow, auditModel.ActionCreate, transaction.ID, nil, transaction)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
 }
 
-// UpdateTransaction will update specified transaction of user.
-func (ser *transactionService) UpdateTransaction(transaction *envelopModel.Transaction) error {
+// UpdateTransaction will update specified transaction of budget.
+func (ser *transactionService) UpdateTransaction(ctx context.Context, transaction *envelopModel.Transaction) error {
 
-	err := ser.validateUserID(transaction.UserID)
+	err := ser.membership.Authorize(ctx, transaction.UserID, transaction.BudgetID, budgetModel.RoleEditor)
 	if err != nil {
 		return err
 	}
 
-	err = ser.validateEnvelopID(transaction.UserID, transaction.EnvelopID)
+	err = ser.validateEnvelopID(ctx, transaction.BudgetID, transaction.EnvelopID)
 	if err != nil {
 		return err
 	}
 
-	err = ser.validateTransactionID(transaction.ID)
+	err = ser.validateAccountID(ctx, transaction.BudgetID, transaction.AccountID)
 	if err != nil {
 		return err
 	}
 
-	uow := repository.NewUnitOfWork(ser.db)
+	err = ser.validateTransactionID(ctx, transaction.BudgetID, transaction.ID)
+	if err != nil {
+		return err
+	}
+
+	err = ser.localizeDate(ctx, transaction.UserID, transaction)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
 	defer uow.RollBack()
 
 	tempTransaction := envelopModel.Transaction{}
 
-	err = ser.repo.GetRecord(uow, &tempTransaction, repository.Filter("`id` = ?", transaction.ID),
-		repository.Select("`created_at`"))
+	err = ser.repo.GetRecord(uow, &tempTransaction, repository.Filter("transactions.`id` = ? AND transactions.`budget_id` = ?",
+		transaction.ID, transaction.BudgetID))
 	if err != nil {
 		return err
 	}
 
+	if tempTransaction.IsReconciled() {
+		return errors.NewValidationError("Reconciled transaction cannot be updated")
+	}
+
+	transaction.UserID = tempTransaction.UserID
+	transaction.CreatedAt = tempTransaction.CreatedAt
+	transaction.ReconciliationID = nil
+
 	err = ser.repo.Save(uow, transaction)
 	if err != nil {
 		return err
 	}
 
-	uow.Commit()
-	return nil
+	err = ser.recordUpdate(uow, auditModel.ActionUpdate, &tempTransaction)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
 }
 
-// DeleteTransaction will delete specified transaction of user.
-func (ser *transactionService) DeleteTransaction(transaction *envelopModel.Transaction) error {
+// DeleteTransaction will delete specified transaction of budget.
+func (ser *transactionService) DeleteTransaction(ctx context.Context, transaction *envelopModel.Transaction) error {
 
-	err := ser.validateTransactionID(transaction.ID)
+	err := ser.membership.Authorize(ctx, transaction.UserID, transaction.BudgetID, budgetModel.RoleEditor)
 	if err != nil {
 		return err
 	}
 
-	err = ser.validateUserID(transaction.UserID)
+	err = ser.validateTransactionID(ctx, transaction.BudgetID, transaction.ID)
 	if err != nil {
 		return err
 	}
 
-	uow := repository.NewUnitOfWork(ser.db)
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
 	defer uow.RollBack()
 
+	tempTransaction := envelopModel.Transaction{}
+
+	err = ser.repo.GetRecord(uow, &tempTransaction, repository.Filter("transactions.`id` = ? AND transactions.`budget_id` = ?",
+		transaction.ID, transaction.BudgetID))
+	if err != nil {
+		return err
+	}
+
+	if tempTransaction.IsReconciled() {
+		return errors.NewValidationError("Reconciled transaction cannot be deleted")
+	}
+
 	fmt.Println(" ================= deleting...")
 
 	err = ser.repo.UpdateWithMap(uow, transaction, map[string]interface{}{
 		"DeletedAt": time.Now(),
-	}, repository.Filter("`id` = ?", transaction.ID))
+	}, repository.Filter("transactions.`id` = ? AND transactions.`budget_id` = ?", transaction.ID, transaction.BudgetID))
 	if err != nil {
 		return err
 	}
 
-	uow.Commit()
-	return nil
+	err = ser.recordChange(uow, auditModel.ActionDelete, transaction.ID, &tempTransaction, nil)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
 }
 
-// GetUserTransaction will fetch transactions of user.
-func (ser *transactionService) GetUserTransaction(transactions *[]envelopModel.TransactionDTO,
-	userID uuid.UUID, totalCount *int64, parser *web.Parser) error {
+// GetUserTransaction will fetch transactions of budget for member.
+func (ser *transactionService) GetUserTransaction(ctx context.Context, transactions *[]envelopModel.TransactionDTO,
+	userID, budgetID uuid.UUID, totalCount *int64, parser *web.Parser) error {
 
-	err := ser.validateUserID(userID)
+	err := ser.membership.Authorize(ctx, userID, budgetID, budgetModel.RoleViewer)
 	if err != nil {
 		return err
 	}
 
 	limit, offset := parser.ParseLimitAndOffset()
 
-	uow := repository.NewUnitOfWork(ser.db)
+	preference, err := ser.preferences.GetPreference(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	searchQueries, err := ser.addSearchQueries(parser.Form, preference)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
 	defer uow.RollBack()
 
 	err = ser.repo.GetAllInOrder(uow, transactions, "transactions.`date` DESC",
-		ser.addSearchQueries(parser.Form), repository.PreloadAssociations([]string{"Envelop"}),
-		repository.Filter("transactions.`user_id` = ? AND transactions.`deleted_at` IS NULL", userID),
+		searchQueries, repository.PreloadAssociations([]string{"Envelop"}),
+		repository.Filter("transactions.`budget_id` = ? AND transactions.`deleted_at` IS NULL", budgetID),
+		repository.Filter("transactions.`envelop_id` IN (SELECT envelops.`id` FROM envelops"+
+			" WHERE envelops.`budget_id` = ? AND envelops.`deleted_at` IS NULL)", budgetID),
 		repository.Paginate(limit, offset, totalCount))
 	if err != nil {
 		return err
 	}
 
-	uow.Commit()
-	return nil
+	location := preference.Location()
+	for index := range *transactions {
+		(*transactions)[index].Date = (*transactions)[index].Date.In(location)
+	}
+
+	return uow.Commit()
 }
 
-// validateUserID will verify if userID exist or not.
-func (ser *transactionService) validateUserID(userID uuid.UUID) error {
+// GetTransactionHistory will fetch all versions of specified transaction.
+func (ser *transactionService) GetTransactionHistory(ctx context.Context, history *[]auditModel.ChangeLogDTO,
+	transaction *envelopModel.Transaction) error {
 
-	exist, err := repository.DoesRecordExist(ser.db, userModel.User{},
-		repository.Filter("users.`id` = ?", userID))
+	err := ser.membership.Authorize(ctx, transaction.UserID, transaction.BudgetID, budgetModel.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), envelopModel.Transaction{},
+		repository.Filter("transactions.`id` = ? AND transactions.`budget_id` = ?", transaction.ID, transaction.BudgetID))
 	if err != nil {
 		return err
 	}
 	if !exist {
-		return errors.NewValidationError("User not found")
+		return errors.NewValidationError("Transaction not found")
 	}
-	return nil
+
+	return ser.changeLogger.GetHistory(ctx, history, auditModel.EntityTransaction, transaction.ID)
+}
+
+// RevertTransaction will revert specified transaction to the state it was in at specified version.
+func (ser *transactionService) RevertTransaction(ctx context.Context, transaction *envelopModel.Transaction, version int) error {
+
+	err := ser.membership.Authorize(ctx, transaction.UserID, transaction.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	tempTransaction := envelopModel.Transaction{}
+
+	err = ser.repo.GetRecord(uow, &tempTransaction,
+		repository.Filter("transactions.`id` = ? AND transactions.`budget_id` = ? AND transactions.`deleted_at` IS NULL",
+			transaction.ID, transaction.BudgetID))
+	if err != nil {
+		return errors.NewValidationError("Transaction not found")
+	}
+
+	if tempTransaction.IsReconciled() {
+		return errors.NewValidationError("Reconciled transaction cannot be updated")
+	}
+
+	err = ser.changeLogger.GetVersion(uow, auditModel.EntityTransaction, transaction.ID, version, transaction)
+	if err != nil {
+		return err
+	}
+
+	// versions recorded before dates were typed can have dates without offset.
+	err = ser.localizeDate(ctx, transaction.UserID, transaction)
+	if err != nil {
+		return err
+	}
+
+	transaction.ID = tempTransaction.ID
+	transaction.UserID = tempTransaction.UserID
+	transaction.BudgetID = tempTransaction.BudgetID
+	transaction.CreatedAt = tempTransaction.CreatedAt
+	transaction.ReconciliationID = nil
+	if transaction.IsReconciled() {
+		transaction.Status = envelopModel.TransactionStatusCleared
+	}
+
+	err = transaction.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = ser.validateEnvelopID(ctx, transaction.BudgetID, transaction.EnvelopID)
+	if err != nil {
+		return err
+	}
+
+	err = ser.validateAccountID(ctx, transaction.BudgetID, transaction.AccountID)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Save(uow, transaction)
+	if err != nil {
+		return err
+	}
+
+	err = ser.recordUpdate(uow, auditModel.ActionRevert, &tempTransaction)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// recordUpdate will fetch the saved transaction and record its changes from before.
+func (ser *transactionService) recordUpdate(uow *repository.UnitOfWork, action string,
+	before *envelopModel.Transaction) error {
+
+	after := envelopModel.Transaction{}
+
+	err := ser.repo.GetRecord(uow, &after, repository.Filter("`id` = ?", before.ID))
+	if err != nil {
+		return err
+	}
+
+	return ser.recordChange(uow, action, before.ID, before, &after)
 }
 
-// validateEnvelopID will verify if envelopID exist or not.
-func (ser *transactionService) validateEnvelopID(userID, envelopID uuid.UUID) error {
+// recordChange will add a version to change log of the transaction.
+func (ser *transactionService) recordChange(uow *repository.UnitOfWork, action string,
+	transactionID uuid.UUID, before, after *envelopModel.Transaction) error {
+
+	return ser.changeLogger.Record(uow, &auditModel.ChangeLog{
+		EntityType: auditModel.EntityTransaction,
+		EntityID:   transactionID,
+		Action:     action,
+	}, before, after)
+}
 
-	exist, err := repository.DoesRecordExist(ser.db, envelopModel.Envelop{},
-		repository.Filter("envelops.`id` = ? AND envelops.`user_id` = ?", envelopID, userID))
+// validateEnvelopID will verify if envelopID exist in budget or not.
+func (ser *transactionService) validateEnvelopID(ctx context.Context, budgetID, envelopID uuid.UUID) error {
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), envelopModel.Envelop{},
+		repository.Filter("envelops.`id` = ? AND envelops.`budget_id` = ? AND envelops.`deleted_at` IS NULL",
+			envelopID, budgetID))
 	if err != nil {
 		return err
 	}
@@ -187,11 +380,31 @@ func (ser *transactionService) validateEnvelopID(userID, envelopID uuid.UUID) er
 	return nil
 }
 
-// validateTransactionID will verify if transaction exist or not.
-func (ser *transactionService) validateTransactionID(transactionID uuid.UUID) error {
+// validateAccountID will verify if accountID exist or not. Account is optional for transaction.
+func (ser *transactionService) validateAccountID(ctx context.Context, budgetID uuid.UUID, accountID *uuid.UUID) error {
 
-	exist, err := repository.DoesRecordExist(ser.db, envelopModel.Transaction{},
-		repository.Filter("transactions.`id` = ?", transactionID))
+	if accountID == nil {
+		return nil
+	}
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), accountModel.Account{},
+		repository.Filter("accounts.`id` = ? AND accounts.`budget_id` = ? AND accounts.`deleted_at` IS NULL",
+			*accountID, budgetID))
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return errors.NewValidationError("Account not found")
+	}
+	return nil
+}
+
+// validateTransactionID will verify if transaction exist in specified budget or not.
+func (ser *transactionService) validateTransactionID(ctx context.Context, budgetID, transactionID uuid.UUID) error {
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), envelopModel.Transaction{},
+		repository.Filter("transactions.`id` = ? AND transactions.`budget_id` = ? AND transactions.`deleted_at` IS NULL",
+			transactionID, budgetID))
 	if err != nil {
 		return err
 	}
@@ -202,21 +415,81 @@ func (ser *transactionService) validateTransactionID(transactionID uuid.UUID) er
 	return nil
 }
 
-func (ser *transactionService) addSearchQueries(requestForm url.Values) repository.QueryProcessor {
+// localizeDate will take date of the transaction in timezone of the user, when it is given without offset.
+func (ser *transactionService) localizeDate(ctx context.Context, userID uuid.UUID,
+	transaction *envelopModel.Transaction) error {
+
+	preference, err := ser.preferences.GetPreference(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	transaction.Date.Localize(preference.Location())
+	return nil
+}
+
+// addSearchQueries will build filters of transactions from query params. Dates without time of day are
+// taken as whole days in timezone of the user, so toDate includes every transaction of that day.
+// period param can be current or previous budget period of the user.
+func (ser *transactionService) addSearchQueries(requestForm url.Values,
+	preference *userModel.Preference) (repository.QueryProcessor, error) {
 	var columnNames []string
 	var conditions []string
 	var operators []string
 	var values []interface{}
 	var queryProcessors []repository.QueryProcessor
 
-	if fromDate, ok := requestForm["fromDate"]; ok {
-		util.AddToSlice("transactions.`date`", ">= ?", "AND", fromDate, &columnNames, &conditions, &operators, &values)
+	if fromDate := requestForm.Get("fromDate"); len(fromDate) > 0 {
+		date, err := preference.ParseDate(fromDate)
+		if err != nil {
+			return nil, errors.NewValidationError("fromDate: " + err.Error())
+		}
+		util.AddToSlice("transactions.`date`", ">= ?", "AND", date.Time, &columnNames, &conditions, &operators, &values)
 	}
 
-	if toDate, ok := requestForm["toDate"]; ok {
-		util.AddToSlice("transactions.`date`", "<= ?", "AND", toDate, &columnNames, &conditions, &operators, &values)
+	if toDate := requestForm.Get("toDate"); len(toDate) > 0 {
+		date, err := preference.ParseDate(toDate)
+		if err != nil {
+			return nil, errors.NewValidationError("toDate: " + err.Error())
+		}
+		if date.IsDateOnly() {
+			util.AddToSlice("transactions.`date`", "< ?", "AND", date.AddDate(0, 0, 1),
+				&columnNames, &conditions, &operators, &values)
+		} else {
+			util.AddToSlice("transactions.`date`", "<= ?", "AND", date.Time, &columnNames, &conditions, &operators, &values)
+		}
+	}
+
+	if period := requestForm.Get("period"); len(period) > 0 {
+		start, end := preference.Period(time.Now())
+		switch period {
+		case "current":
+		case "previous":
+			start, end = start.AddDate(0, -1, 0), start
+		default:
+			return nil, errors.NewValidationError("period must be current or previous")
+		}
+		util.AddToSlice("transactions.`date`", ">= ?", "AND", start, &columnNames, &conditions, &operators, &values)
+		util.AddToSlice("transactions.`date`", "< ?", "AND", end, &columnNames, &conditions, &operators, &values)
+	}
+
+	if accountID, ok := requestForm["accountID"]; ok {
+		util.AddToSlice("transactions.`account_id`", "= ?", "AND", accountID, &columnNames, &conditions, &operators, &values)
+	}
+
+	if status, ok := requestForm["status"]; ok {
+		util.AddToSlice("transactions.`status`", "IN (?)", "AND", status, &columnNames, &conditions, &operators, &values)
 	}
 
 	queryProcessors = append(queryProcessors, repository.FilterWithOperator(columnNames, conditions, operators, values))
-	return repository.CombineQueries(queryProcessors)
+
+	if hasAttachments, ok := requestForm["hasAttachments"]; ok && len(hasAttachments) > 0 {
+		attachments := "EXISTS (SELECT 1 FROM attachments WHERE attachments.`transaction_id` = transactions.`id`)"
+		if hasAttachments[0] == "false" {
+			attachments = "NOT " + attachments
+		}
+		queryProcessors = append(queryProcessors, repository.Filter(attachments))
+	}
+
+	return repository.CombineQueries(queryProcessors), nil
 }
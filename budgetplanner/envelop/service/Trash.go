@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	auditService "github.com/shaileshhb/budget-planner-go/budgetplanner/audit/service"
+	budgetService "github.com/shaileshhb/budget-planner-go/budgetplanner/budget/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	auditModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/audit"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
+	envelopModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/envelop"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/storage"
+	"gorm.io/gorm"
+)
+
+// defaultTrashRetentionDays is used when TRASH_RETENTION_DAYS is not set.
+const defaultTrashRetentionDays = 30
+
+// TrashService provides methods to list, restore and purge deleted envelops and transactions.
+type TrashService interface {
+	GetTrash(ctx context.Context, trash *envelopModel.Trash, userID, budgetID uuid.UUID) error
+	RestoreEnvelop(ctx context.Context, envelop *envelopModel.Envelop) error
+	RestoreTransaction(ctx context.Context, transaction *envelopModel.Transaction) error
+	PurgeEnvelop(ctx context.Context, envelop *envelopModel.Envelop) error
+	PurgeTransaction(ctx context.Context, transaction *envelopModel.Transaction) error
+	PurgeExpired() error
+}
+
+// trashService
+type trashService struct {
+	db           *gorm.DB
+	repo         repository.Repository
+	auth         *security.Authentication
+	conf         config.ConfReader
+	store        storage.Storage
+	changeLogger auditService.ChangeLogger
+	membership   budgetService.MembershipService
+}
+
+// NewTrashService create new trash service.
+func NewTrashService(db *gorm.DB, repo repository.Repository, auth *security.Authentication,
+	conf config.ConfReader, store storage.Storage, changeLogger auditService.ChangeLogger,
+	membership budgetService.MembershipService) TrashService {
+	return &trashService{
+		db:           db,
+		repo:         repo,
+		auth:         auth,
+		conf:         conf,
+		store:        store,
+		changeLogger: changeLogger,
+		membership:   membership,
+	}
+}
+
+// GetTrash will fetch envelops and transactions deleted from budget within the retention period.
+func (ser *trashService) GetTrash(ctx context.Context, trash *envelopModel.Trash, userID, budgetID uuid.UUID) error {
+
+	err := ser.membership.Authorize(ctx, userID, budgetID, budgetModel.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	cutoff := ser.retentionCutoff()
+
+	err = ser.repo.GetAllInOrder(uow, &trash.Envelops, "envelops.`deleted_at` DESC",
+		repository.Filter("envelops.`budget_id` = ? AND envelops.`deleted_at` >= ?", budgetID, cutoff))
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.GetAllInOrder(uow, &trash.Transactions, "transactions.`deleted_at` DESC",
+		repository.Filter("transactions.`budget_id` = ? AND transactions.`deleted_at` >= ?", budgetID, cutoff))
+	if err != nil {
+		return err
+	}
+
+	for index := range trash.Envelops {
+		trash.Envelops[index].PurgeAt = ser.purgeAt(trash.Envelops[index].DeletedAt)
+	}
+
+	for index := range trash.Transactions {
+		trash.Transactions[index].PurgeAt = ser.purgeAt(trash.Transactions[index].DeletedAt)
+	}
+
+	return uow.Commit()
+}
+
+// RestoreEnvelop will restore deleted envelop along with transactions
+// which were deleted with or after the envelop.
+func (ser *trashService) RestoreEnvelop(ctx context.Context, envelop *envelopModel.Envelop) error {
+
+	err := ser.membership.Authorize(ctx, envelop.UserID, envelop.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	deletedEnvelop := envelopModel.Envelop{}
+
+	err = ser.repo.GetRecord(uow, &deletedEnvelop,
+		repository.Filter("envelops.`id` = ? AND envelops.`budget_id` = ? AND envelops.`deleted_at` IS NOT NULL",
+			envelop.ID, envelop.BudgetID))
+	if err != nil {
+		return errors.NewValidationError("Envelop not found in trash")
+	}
+
+	var transactions []envelopModel.Transaction
+
+	err = ser.repo.GetAll(uow, &transactions,
+		repository.Filter("transactions.`envelop_id` = ? AND transactions.`deleted_at` >= ?",
+			envelop.ID, deletedEnvelop.DeletedAt))
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &envelopModel.Transaction{}, map[string]interface{}{
+		"DeletedAt": nil,
+	}, repository.Filter("transactions.`envelop_id` = ? AND transactions.`deleted_at` >= ?",
+		envelop.ID, deletedEnvelop.DeletedAt))
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &envelopModel.Envelop{}, map[string]interface{}{
+		"DeletedAt": nil,
+	}, repository.Filter("envelops.`id` = ?", envelop.ID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.recordRestore(uow, auditModel.EntityEnvelop, envelop.ID, &deletedEnvelop)
+	if err != nil {
+		return err
+	}
+
+	for index := range transactions {
+		err = ser.recordRestore(uow, auditModel.EntityTransaction, transactions[index].ID,
+			&transactions[index])
+		if err != nil {
+			return err
+		}
+	}
+
+	return uow.Commit()
+}
+
+// RestoreTransaction will restore deleted transaction. Envelop of the transaction must not be deleted.
+func (ser *trashService) RestoreTransaction(ctx context.Context, transaction *envelopModel.Transaction) error {
+
+	err := ser.membership.Authorize(ctx, transaction.UserID, transaction.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	deletedTransaction := envelopModel.Transaction{}
+
+	err = ser.repo.GetRecord(uow, &deletedTransaction,
+		repository.Filter("transactions.`id` = ? AND transactions.`budget_id` = ? AND transactions.`deleted_at` IS NOT NULL",
+			transaction.ID, transaction.BudgetID))
+	if err != nil {
+		return errors.NewValidationError("Transaction not found in trash")
+	}
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), envelopModel.Envelop{},
+		repository.Filter("envelops.`id` = ? AND envelops.`deleted_at` IS NULL", deletedTransaction.EnvelopID))
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return errors.NewValidationError("Envelop of the transaction is deleted. Restore the envelop first")
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &envelopModel.Transaction{}, map[string]interface{}{
+		"DeletedAt": nil,
+	}, repository.Filter("transactions.`id` = ?", transaction.ID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.recordRestore(uow, auditModel.EntityTransaction, transaction.ID,
+		&deletedTransaction)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// PurgeEnvelop will permanently delete envelop in trash along with its transactions and their attachments.
+func (ser *trashService) PurgeEnvelop(ctx context.Context, envelop *envelopModel.Envelop) error {
+
+	err := ser.membership.Authorize(ctx, envelop.UserID, envelop.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), envelopModel.Envelop{},
+		repository.Filter("envelops.`id` = ? AND envelops.`budget_id` = ? AND envelops.`deleted_at` IS NOT NULL",
+			envelop.ID, envelop.BudgetID))
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return errors.NewValidationError("Envelop not found in trash")
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	keys, err := purgeAttachments(uow, ser.repo, "`envelop_id` = ?", envelop.ID)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Delete(uow, &envelopModel.Transaction{}, "`envelop_id` = ?", envelop.ID)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Delete(uow, &envelopModel.Goal{}, "`envelop_id` = ?", envelop.ID)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Delete(uow, &envelopModel.Envelop{}, "`id` = ?", envelop.ID)
+	if err != nil {
+		return err
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		return err
+	}
+
+	deleteFiles(ser.store, keys)
+	return nil
+}
+
+// PurgeTransaction will permanently delete transaction in trash along with its attachments.
+func (ser *trashService) PurgeTransaction(ctx context.Context, transaction *envelopModel.Transaction) error {
+
+	err := ser.membership.Authorize(ctx, transaction.UserID, transaction.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), envelopModel.Transaction{},
+		repository.Filter("transactions.`id` = ? AND transactions.`budget_id` = ? AND transactions.`deleted_at` IS NOT NULL",
+			transaction.ID, transaction.BudgetID))
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return errors.NewValidationError("Transaction not found in trash")
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	keys, err := purgeAttachments(uow, ser.repo, "`id` = ?", transaction.ID)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Delete(uow, &envelopModel.Transaction{}, "`id` = ?", transaction.ID)
+	if err != nil {
+		return err
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		return err
+	}
+
+	deleteFiles(ser.store, keys)
+	return nil
+}
+
+// PurgeExpired will permanently delete envelops and transactions which are in trash
+// for more than the retention period. It is run periodically by the retention job.
+func (ser *trashService) PurgeExpired() error {
+
+	uow := repository.NewUnitOfWork(ser.db)
+	defer uow.RollBack()
+
+	cutoff := ser.retentionCutoff()
+
+	keys, err := purgeAttachments(uow, ser.repo, "`deleted_at` < ?"+
+		" OR `envelop_id` IN (SELECT `id` FROM `envelops` WHERE `deleted_at` < ?)", cutoff, cutoff)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Delete(uow, &envelopModel.Transaction{}, "`deleted_at` < ?", cutoff)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Delete(uow, &envelopModel.Transaction{},
+		"`envelop_id` IN (SELECT `id` FROM `envelops` WHERE `deleted_at` < ?)", cutoff)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Delete(uow, &envelopModel.Goal{},
+		"`envelop_id` IN (SELECT `id` FROM `envelops` WHERE `deleted_at` < ?)", cutoff)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Delete(uow, &envelopModel.Envelop{}, "`deleted_at` < ?", cutoff)
+	if err != nil {
+		return err
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		return err
+	}
+
+	deleteFiles(ser.store, keys)
+	return nil
+}
+
+// recordRestore will add a version to change log of the restored entity.
+func (ser *trashService) recordRestore(uow *repository.UnitOfWork, entityType string,
+	entityID uuid.UUID, entity interface{}) error {
+
+	return ser.changeLogger.Record(uow, &auditModel.ChangeLog{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     auditModel.ActionRestore,
+	}, entity, entity)
+}
+
+// retentionDays will return number of days deleted items are kept in trash.
+func (ser *trashService) retentionDays() int64 {
+	if ser.conf.IsSet(config.TrashRetentionDays) && ser.conf.GetInt64(config.TrashRetentionDays) > 0 {
+		return ser.conf.GetInt64(config.TrashRetentionDays)
+	}
+	return defaultTrashRetentionDays
+}
+
+// retentionCutoff will return time before which deleted items are purged.
+func (ser *trashService) retentionCutoff() time.Time {
+	return time.Now().AddDate(0, 0, -int(ser.retentionDays()))
+}
+
+// purgeAt will return time at which item deleted at deletedAt will be purged.
+func (ser *trashService) purgeAt(deletedAt time.Time) time.Time {
+	return deletedAt.AddDate(0, 0, int(ser.retentionDays()))
+}
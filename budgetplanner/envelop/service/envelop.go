@@ -1,58 +1,74 @@
 package service
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	auditService "github.com/shaileshhb/budget-planner-go/budgetplanner/audit/service"
+	budgetService "github.com/shaileshhb/budget-planner-go/budgetplanner/budget/service"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	auditModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/audit"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
 	envelopModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/envelop"
-	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	userService "github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
 	"gorm.io/gorm"
 )
 
 // EnvelopService service provides methods to update, delete, add, get method for envelopService.
 type EnvelopService interface {
-	AddEnvelop(envelop *envelopModel.Envelop) error
-	UpdateEnvelop(envelop *envelopModel.Envelop) error
-	DeleteEnvelop(envelop *envelopModel.Envelop) error
-	GetEnvelops(envelops *[]envelopModel.EnvelopDTO, userID uuid.UUID) error
+	AddEnvelop(ctx context.Context, envelop *envelopModel.Envelop) error
+	UpdateEnvelop(ctx context.Context, envelop *envelopModel.Envelop) error
+	DeleteEnvelop(ctx context.Context, envelop *envelopModel.Envelop, deletion *envelopModel.EnvelopDeletion) error
+	GetEnvelops(ctx context.Context, envelops *[]envelopModel.EnvelopDTO, userID, budgetID uuid.UUID) error
+	GetEnvelopHistory(ctx context.Context, history *[]auditModel.ChangeLogDTO, envelop *envelopModel.Envelop) error
+	RevertEnvelop(ctx context.Context, envelop *envelopModel.Envelop, version int) error
 }
 
 // envelopService
 type envelopService struct {
-	db          *gorm.DB
-	repo        repository.Repository
-	auth        *security.Authentication
-	MaxEnvelops int
+	db           *gorm.DB
+	repo         repository.Repository
+	auth         *security.Authentication
+	changeLogger auditService.ChangeLogger
+	membership   budgetService.MembershipService
+	preferences  userService.PreferenceService
+	MaxEnvelops  int
 }
 
 // NewEnvelopService create new envelop service.
-func NewEnvelopService(db *gorm.DB, repo repository.Repository, auth *security.Authentication) EnvelopService {
+func NewEnvelopService(db *gorm.DB, repo repository.Repository, auth *security.Authentication,
+	changeLogger auditService.ChangeLogger, membership budgetService.MembershipService,
+	preferences userService.PreferenceService) EnvelopService {
 	return &envelopService{
-		db:          db,
-		repo:        repo,
-		auth:        auth,
-		MaxEnvelops: 20,
+		db:           db,
+		repo:         repo,
+		auth:         auth,
+		changeLogger: changeLogger,
+		membership:   membership,
+		preferences:  preferences,
+		MaxEnvelops:  20,
 	}
 }
 
-// AddEnvelop will add new envelop for specified user.
-func (ser *envelopService) AddEnvelop(envelop *envelopModel.Envelop) error {
+// AddEnvelop will add new envelop in specified budget.
+func (ser *envelopService) AddEnvelop(ctx context.Context, envelop *envelopModel.Envelop) error {
 
-	err := ser.validateUserID(envelop.UserID)
+	err := ser.membership.Authorize(ctx, envelop.UserID, envelop.BudgetID, budgetModel.RoleEditor)
 	if err != nil {
 		return err
 	}
 
-	uow := repository.NewUnitOfWork(ser.db)
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
 	defer uow.RollBack()
 
 	var totalCount int64
 
 	err = ser.repo.GetCount(uow, envelopModel.Envelop{}, &totalCount,
-		repository.Filter("envelops.`user_id` = ?", envelop.UserID))
+		repository.Filter("envelops.`budget_id` = ?", envelop.BudgetID))
 	if err != nil {
 		return err
 	}
@@ -66,72 +82,252 @@ func (ser *envelopService) AddEnvelop(envelop *envelopModel.Envelop) error {
 		return err
 	}
 
-	uow.Commit()
-	return nil
+	err = ser.recordChange(uow, auditModel.ActionCreate, envelop.ID, nil, envelop)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
 }
 
 // UpdateEnvelop will update specified envelop.
-func (ser *envelopService) UpdateEnvelop(envelop *envelopModel.Envelop) error {
+func (ser *envelopService) UpdateEnvelop(ctx context.Context, envelop *envelopModel.Envelop) error {
 
-	err := ser.validateUserID(envelop.UserID)
+	err := ser.membership.Authorize(ctx, envelop.UserID, envelop.BudgetID, budgetModel.RoleEditor)
 	if err != nil {
 		return err
 	}
 
-	err = ser.validateEnvelopID(envelop.ID)
+	err = ser.validateEnvelopID(ctx, envelop.BudgetID, envelop.ID)
 	if err != nil {
 		return err
 	}
 
-	uow := repository.NewUnitOfWork(ser.db)
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
 	defer uow.RollBack()
 
+	tempEnvelop := envelopModel.Envelop{}
+
+	err = ser.repo.GetRecord(uow, &tempEnvelop, repository.Filter("envelops.`id` = ? AND envelops.`budget_id` = ?",
+		envelop.ID, envelop.BudgetID))
+	if err != nil {
+		return err
+	}
+
+	// user who created the envelop remains its creator.
+	envelop.UserID = tempEnvelop.UserID
+
 	// using update because there is no nullable field in envelops table
 	err = ser.repo.Updates(uow, envelop)
 	if err != nil {
 		return err
 	}
 
-	uow.Commit()
-	return nil
+	err = ser.recordUpdate(uow, auditModel.ActionUpdate, &tempEnvelop)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
 }
 
-// DeleteEnvelop will delete specified envelop.
-func (ser *envelopService) DeleteEnvelop(envelop *envelopModel.Envelop) error {
+// DeleteEnvelop will delete specified envelop. Transactions of the envelop are either
+// reassigned to another envelop, deleted along with it or the deletion is refused.
+func (ser *envelopService) DeleteEnvelop(ctx context.Context, envelop *envelopModel.Envelop, deletion *envelopModel.EnvelopDeletion) error {
 
-	err := ser.validateEnvelopID(envelop.ID)
+	err := ser.membership.Authorize(ctx, envelop.UserID, envelop.BudgetID, budgetModel.RoleEditor)
 	if err != nil {
 		return err
 	}
 
-	uow := repository.NewUnitOfWork(ser.db)
+	err = ser.validateEnvelopID(ctx, envelop.BudgetID, envelop.ID)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
 	defer uow.RollBack()
 
+	tempEnvelop := envelopModel.Envelop{}
+
+	err = ser.repo.GetRecord(uow, &tempEnvelop, repository.Filter("envelops.`id` = ? AND envelops.`budget_id` = ?",
+		envelop.ID, envelop.BudgetID))
+	if err != nil {
+		return err
+	}
+
+	var totalCount int64
+
+	err = ser.repo.GetCount(uow, envelopModel.Transaction{}, &totalCount,
+		repository.Filter("transactions.`envelop_id` = ? AND transactions.`deleted_at` IS NULL", envelop.ID))
+	if err != nil {
+		return err
+	}
+
+	deletedAt := time.Now()
+
+	if totalCount > 0 {
+		switch deletion.Transactions {
+		case envelopModel.EnvelopDeleteReassign:
+			err = ser.reassignTransactions(uow, envelop, *deletion.ReassignTo)
+		case envelopModel.EnvelopDeleteCascade:
+			err = ser.deleteTransactions(uow, envelop, deletedAt)
+		default:
+			err = errors.NewValidationError(fmt.Sprintf("Envelop has %d transactions. Reassign or delete them first",
+				totalCount))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
 	// using update because there is no nullable field in envelops table
-	err = ser.repo.UpdateWithMap(uow, envelopModel.Envelop{}, map[string]interface{}{
-		"DeletedAt": time.Now(),
-	}, repository.Filter("envelops.`id` = ?", envelop.ID))
+	err = ser.repo.UpdateWithMap(uow, &envelopModel.Envelop{}, map[string]interface{}{
+		"DeletedAt": deletedAt,
+	}, repository.Filter("envelops.`id` = ? AND envelops.`budget_id` = ?", envelop.ID, envelop.BudgetID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.recordChange(uow, auditModel.ActionDelete, envelop.ID, &tempEnvelop, nil)
 	if err != nil {
 		return err
 	}
 
-	uow.Commit()
+	return uow.Commit()
+}
+
+// reassignTransactions will move all transactions of envelop to the specified envelop.
+// Reconciled transactions cannot be moved.
+func (ser *envelopService) reassignTransactions(uow *repository.UnitOfWork, envelop *envelopModel.Envelop,
+	reassignTo uuid.UUID) error {
+
+	if reassignTo == envelop.ID {
+		return errors.NewValidationError("Transactions cannot be reassigned to the envelop being deleted")
+	}
+
+	var totalCount int64
+
+	err := ser.repo.GetCount(uow, envelopModel.Envelop{}, &totalCount,
+		repository.Filter("envelops.`id` = ? AND envelops.`budget_id` = ? AND envelops.`deleted_at` IS NULL",
+			reassignTo, envelop.BudgetID))
+	if err != nil {
+		return err
+	}
+	if totalCount == 0 {
+		return errors.NewValidationError("Envelop to reassign transactions not found")
+	}
+
+	err = ser.validateNotReconciled(uow, envelop.ID, "reassigned")
+	if err != nil {
+		return err
+	}
+
+	transactions, err := ser.getTransactions(uow, envelop.ID)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &envelopModel.Transaction{}, map[string]interface{}{
+		"EnvelopID": reassignTo,
+	}, repository.Filter("transactions.`envelop_id` = ? AND transactions.`deleted_at` IS NULL", envelop.ID))
+	if err != nil {
+		return err
+	}
+
+	for index := range transactions {
+		after := transactions[index]
+		after.EnvelopID = reassignTo
+		err = ser.recordTransactionChange(uow, auditModel.ActionUpdate, &transactions[index], &after)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// GetEnvelops will fetch all the envelops for specifed user.
-func (ser *envelopService) GetEnvelops(envelops *[]envelopModel.EnvelopDTO, userID uuid.UUID) error {
+// deleteTransactions will delete all transactions of envelop at the same time as envelop,
+// so that they are restored along with the envelop. Reconciled transactions cannot be deleted.
+func (ser *envelopService) deleteTransactions(uow *repository.UnitOfWork, envelop *envelopModel.Envelop,
+	deletedAt time.Time) error {
 
-	err := ser.validateUserID(userID)
+	err := ser.validateNotReconciled(uow, envelop.ID, "deleted")
 	if err != nil {
 		return err
 	}
 
-	uow := repository.NewUnitOfWork(ser.db)
+	transactions, err := ser.getTransactions(uow, envelop.ID)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &envelopModel.Transaction{}, map[string]interface{}{
+		"DeletedAt": deletedAt,
+	}, repository.Filter("transactions.`envelop_id` = ? AND transactions.`deleted_at` IS NULL", envelop.ID))
+	if err != nil {
+		return err
+	}
+
+	for index := range transactions {
+		err = ser.recordTransactionChange(uow, auditModel.ActionDelete, &transactions[index], nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateNotReconciled will verify that envelop has no reconciled transactions, as they are locked.
+// action tells what was to be done with the transactions.
+func (ser *envelopService) validateNotReconciled(uow *repository.UnitOfWork, envelopID uuid.UUID, action string) error {
+
+	var totalCount int64
+
+	err := ser.repo.GetCount(uow, envelopModel.Transaction{}, &totalCount,
+		repository.Filter("transactions.`envelop_id` = ? AND transactions.`status` = ? AND transactions.`deleted_at` IS NULL",
+			envelopID, envelopModel.TransactionStatusReconciled))
+	if err != nil {
+		return err
+	}
+	if totalCount > 0 {
+		return errors.NewValidationError("Envelop has reconciled transactions which cannot be " + action)
+	}
+	return nil
+}
+
+// getTransactions will fetch all transactions of envelop which are not deleted.
+func (ser *envelopService) getTransactions(uow *repository.UnitOfWork,
+	envelopID uuid.UUID) ([]envelopModel.Transaction, error) {
+
+	var transactions []envelopModel.Transaction
+
+	err := ser.repo.GetAll(uow, &transactions,
+		repository.Filter("transactions.`envelop_id` = ? AND transactions.`deleted_at` IS NULL", envelopID))
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// GetEnvelops will fetch all the envelops of specifed budget along with progress of their goals
+// in current budget period of the user.
+func (ser *envelopService) GetEnvelops(ctx context.Context, envelops *[]envelopModel.EnvelopDTO, userID, budgetID uuid.UUID) error {
+
+	err := ser.membership.Authorize(ctx, userID, budgetID, budgetModel.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	preference, err := ser.preferences.GetPreference(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
 	defer uow.RollBack()
 
 	err = ser.repo.GetAllInOrder(uow, envelops, "envelops.`name`",
-		repository.Filter("envelops.`user_id` = ? AND envelops.`deleted_at` IS NULL", userID))
+		repository.Filter("envelops.`budget_id` = ? AND envelops.`deleted_at` IS NULL", budgetID))
 	if err != nil {
 		return err
 	}
@@ -139,36 +335,135 @@ func (ser *envelopService) GetEnvelops(envelops *[]envelopModel.EnvelopDTO, user
 	for index := range *envelops {
 		err = ser.repo.Scan(uow, &(*envelops)[index], repository.Model(envelopModel.Transaction{}),
 			repository.Select("SUM(transactions.`amount`) AS amount_spent"),
-			repository.Filter("transactions.`envelop_id` = ? AND transactions.`user_id` = ?",
-				(*envelops)[index].ID, userID))
+			repository.Filter("transactions.`envelop_id` = ? AND transactions.`budget_id` = ? AND transactions.`deleted_at` IS NULL",
+				(*envelops)[index].ID, budgetID))
 		if err != nil {
 			return err
 		}
 	}
 
-	uow.Commit()
-	return nil
+	progress, err := getGoalProgress(uow, ser.repo, budgetID, preference, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for index := range *envelops {
+		(*envelops)[index].Goal = progress[(*envelops)[index].ID]
+	}
+
+	return uow.Commit()
 }
 
-// validateUserID will verify if userID exist or not.
-func (ser *envelopService) validateUserID(userID uuid.UUID) error {
+// GetEnvelopHistory will fetch all versions of specified envelop.
+func (ser *envelopService) GetEnvelopHistory(ctx context.Context, history *[]auditModel.ChangeLogDTO, envelop *envelopModel.Envelop) error {
 
-	exist, err := repository.DoesRecordExist(ser.db, userModel.User{},
-		repository.Filter("users.`id` = ?", userID))
+	err := ser.membership.Authorize(ctx, envelop.UserID, envelop.BudgetID, budgetModel.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), envelopModel.Envelop{},
+		repository.Filter("envelops.`id` = ? AND envelops.`budget_id` = ?", envelop.ID, envelop.BudgetID))
 	if err != nil {
 		return err
 	}
 	if !exist {
-		return errors.NewValidationError("User not found")
+		return errors.NewValidationError("Envelop not found")
 	}
-	return nil
+
+	return ser.changeLogger.GetHistory(ctx, history, auditModel.EntityEnvelop, envelop.ID)
+}
+
+// RevertEnvelop will revert specified envelop to the state it was in at specified version.
+func (ser *envelopService) RevertEnvelop(ctx context.Context, envelop *envelopModel.Envelop, version int) error {
+
+	err := ser.membership.Authorize(ctx, envelop.UserID, envelop.BudgetID, budgetModel.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	tempEnvelop := envelopModel.Envelop{}
+
+	err = ser.repo.GetRecord(uow, &tempEnvelop,
+		repository.Filter("envelops.`id` = ? AND envelops.`budget_id` = ? AND envelops.`deleted_at` IS NULL",
+			envelop.ID, envelop.BudgetID))
+	if err != nil {
+		return errors.NewValidationError("Envelop not found")
+	}
+
+	err = ser.changeLogger.GetVersion(uow, auditModel.EntityEnvelop, envelop.ID, version, envelop)
+	if err != nil {
+		return err
+	}
+
+	envelop.ID = tempEnvelop.ID
+	envelop.UserID = tempEnvelop.UserID
+	envelop.BudgetID = tempEnvelop.BudgetID
+	envelop.CreatedAt = tempEnvelop.CreatedAt
+
+	err = envelop.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Save(uow, envelop)
+	if err != nil {
+		return err
+	}
+
+	err = ser.recordUpdate(uow, auditModel.ActionRevert, &tempEnvelop)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// recordUpdate will fetch the saved envelop and record its changes from before.
+func (ser *envelopService) recordUpdate(uow *repository.UnitOfWork, action string,
+	before *envelopModel.Envelop) error {
+
+	after := envelopModel.Envelop{}
+
+	err := ser.repo.GetRecord(uow, &after, repository.Filter("envelops.`id` = ?", before.ID))
+	if err != nil {
+		return err
+	}
+
+	return ser.recordChange(uow, action, before.ID, before, &after)
+}
+
+// recordChange will add a version to change log of the envelop.
+func (ser *envelopService) recordChange(uow *repository.UnitOfWork, action string,
+	envelopID uuid.UUID, before, after *envelopModel.Envelop) error {
+
+	return ser.changeLogger.Record(uow, &auditModel.ChangeLog{
+		EntityType: auditModel.EntityEnvelop,
+		EntityID:   envelopID,
+		Action:     action,
+	}, before, after)
+}
+
+// recordTransactionChange will add a version to change log of the transaction changed along with envelop.
+func (ser *envelopService) recordTransactionChange(uow *repository.UnitOfWork, action string,
+	before, after *envelopModel.Transaction) error {
+
+	return ser.changeLogger.Record(uow, &auditModel.ChangeLog{
+		EntityType: auditModel.EntityTransaction,
+		EntityID:   before.ID,
+		Action:     action,
+	}, before, after)
 }
 
-// validateEnvelopID will verify if envelopID exist or not.
-func (ser *envelopService) validateEnvelopID(envelopID uuid.UUID) error {
+// validateEnvelopID will verify if envelopID exist in specified budget or not.
+func (ser *envelopService) validateEnvelopID(ctx context.Context, budgetID, envelopID uuid.UUID) error {
 
-	exist, err := repository.DoesRecordExist(ser.db, envelopModel.Envelop{},
-		repository.Filter("envelops.`id` = ?", envelopID))
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), envelopModel.Envelop{},
+		repository.Filter("envelops.`id` = ? AND envelops.`budget_id` = ? AND envelops.`deleted_at` IS NULL",
+			envelopID, budgetID))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,116 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+)
+
+// maxPixels is maximum number of pixels of image which is decoded, it guards against decompression bombs.
+const maxPixels = 25_000_000
+
+// thumbnailQuality is quality of JPEG thumbnails.
+const thumbnailQuality = 85
+
+// decoders contains decoder of every supported content type.
+var decoders = map[string]func([]byte) (image.Image, error){
+	"image/jpeg": func(data []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(data)) },
+	"image/png":  func(data []byte) (image.Image, error) { return png.Decode(bytes.NewReader(data)) },
+	"image/gif":  func(data []byte) (image.Image, error) { return gif.Decode(bytes.NewReader(data)) },
+}
+
+// configDecoders contains decoder of dimensions of every supported content type.
+var configDecoders = map[string]func([]byte) (image.Config, error){
+	"image/jpeg": func(data []byte) (image.Config, error) { return jpeg.DecodeConfig(bytes.NewReader(data)) },
+	"image/png":  func(data []byte) (image.Config, error) { return png.DecodeConfig(bytes.NewReader(data)) },
+	"image/gif":  func(data []byte) (image.Config, error) { return gif.DecodeConfig(bytes.NewReader(data)) },
+}
+
+// Decode will detect content type of data from its content, not from the name or header sent by client,
+// and decode it. Only JPEG, PNG and GIF images are accepted. First frame of animated GIF is decoded.
+func Decode(data []byte) (image.Image, string, error) {
+	contentType := http.DetectContentType(data)
+
+	decode, ok := decoders[contentType]
+	if !ok {
+		return nil, "", errors.NewValidationError("Image must be JPEG, PNG or GIF")
+	}
+
+	config, err := configDecoders[contentType](data)
+	if err != nil {
+		return nil, "", errors.NewValidationError("Image is corrupt")
+	}
+
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return nil, "", errors.NewValidationError("Image dimensions are too large")
+	}
+
+	img, err := decode(data)
+	if err != nil {
+		return nil, "", errors.NewValidationError("Image is corrupt")
+	}
+	return img, contentType, nil
+}
+
+// Thumbnail will crop center square of the image and scale it down to size x size pixels as JPEG.
+// Images smaller than size are not scaled up. Transparent pixels are drawn over white background.
+func Thumbnail(img image.Image, size int) ([]byte, error) {
+	bounds := img.Bounds()
+
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(bounds.Min.X+(bounds.Dx()-side)/2,
+		bounds.Min.Y+(bounds.Dy()-side)/2))
+
+	if side < size {
+		size = side
+	}
+
+	thumbnail := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	// every pixel of thumbnail is average of the source pixels it covers.
+	for y := 0; y < size; y++ {
+		top := crop.Min.Y + y*side/size
+		bottom := crop.Min.Y + (y+1)*side/size
+
+		for x := 0; x < size; x++ {
+			left := crop.Min.X + x*side/size
+			right := crop.Min.X + (x+1)*side/size
+
+			var r, g, b, count uint64
+			for sy := top; sy < bottom; sy++ {
+				for sx := left; sx < right; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r += uint64(pr + 0xffff - pa)
+					g += uint64(pg + 0xffff - pa)
+					b += uint64(pb + 0xffff - pa)
+					count++
+				}
+			}
+
+			thumbnail.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / count >> 8),
+				G: uint8(g / count >> 8),
+				B: uint8(b / count >> 8),
+				A: 0xff,
+			})
+		}
+	}
+
+	buffer := bytes.Buffer{}
+
+	err := jpeg.Encode(&buffer, thumbnail, &jpeg.Options{Quality: thumbnailQuality})
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
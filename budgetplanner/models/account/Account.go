@@ -4,18 +4,28 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/encryption"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
 	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
 )
 
+// maxNumberLength is maximum length of account number, which is length of longest IBAN.
+// Column is wider as number is stored encrypted.
+const maxNumberLength = 34
+
 // Account consist of all details regarding user accounts
 type Account struct {
 	general.Base
-	Name   string         `json:"name" gorm:"type:varchar(100);not_null"`
-	User   userModel.User `json:"-" gorm:"foreignKey:UserID"` // added to create foregin key. can't create using constraint
-	UserID uuid.UUID      `json:"userID" gorm:"type:char(36);index:idx_user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	Amount float64        `json:"amount" gorm:"type:decimal(10,2);not_null"`
+	Name     string             `json:"name" gorm:"type:varchar(100);not_null"`
+	User     userModel.User     `json:"-" gorm:"foreignKey:UserID"` // added to create foregin key. can't create using constraint
+	UserID   uuid.UUID          `json:"userID" gorm:"type:char(36);index:idx_user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Budget   budgetModel.Budget `json:"-" gorm:"foreignKey:BudgetID"`
+	BudgetID uuid.UUID          `json:"budgetID" gorm:"type:char(36);index:idx_budget_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TenantID uuid.UUID          `json:"-" gorm:"type:char(36);index:idx_tenant_id"`
+	Amount   float64            `json:"amount" gorm:"type:decimal(10,2);not_null"`
+	Number   *encryption.String `json:"number" gorm:"type:varchar(255)"`
 }
 
 // TableName will specify table name for envelop struct.
@@ -36,19 +46,33 @@ func (a *Account) Validate() error {
 		return errors.NewValidationError("user must be specified")
 	}
 
+	if a.BudgetID == uuid.Nil {
+		return errors.NewValidationError("budget must be specified")
+	}
+
 	if a.Amount == 0 {
 		return errors.NewValidationError("amount must be greater than 0")
 	}
 
+	if a.Number != nil {
+		*a.Number = encryption.String(strings.TrimSpace(string(*a.Number)))
+		if len(*a.Number) > maxNumberLength {
+			return errors.NewValidationError("account number must not be longer than 34 characters")
+		}
+	}
+
 	return nil
 }
 
 // AccountDTO contains fields for DTO specifically.
 type AccountDTO struct {
 	general.BaseDTO
-	Name   string    `json:"name"`
-	UserID uuid.UUID `json:"userID"`
-	Amount float64   `json:"amount"`
+	Name     string             `json:"name"`
+	UserID   uuid.UUID          `json:"userID"`
+	BudgetID uuid.UUID          `json:"budgetID"`
+	TenantID uuid.UUID          `json:"-"`
+	Amount   float64            `json:"amount"`
+	Number   *encryption.String `json:"number"`
 }
 
 // TableName will specify table name for envelop struct.
@@ -0,0 +1,37 @@
+package account
+
+import (
+	"sync"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	"gorm.io/gorm"
+)
+
+// ModuleConfig use for Automigrant Tables.
+type ModuleConfig struct {
+	db *gorm.DB
+}
+
+// NewAccountModuleConfig Return New Module Config.
+func NewAccountModuleConfig(db *gorm.DB) *ModuleConfig {
+	return &ModuleConfig{
+		db: db,
+	}
+}
+
+// TableMigration Update Table Structure with Latest Version.
+func (config *ModuleConfig) TableMigration(wg *sync.WaitGroup) {
+	var models []interface{} = []interface{}{
+		&Account{},
+		&Reconciliation{},
+	}
+
+	for _, model := range models {
+		err := config.db.Debug().Migrator().AutoMigrate(model)
+		if err != nil {
+			log.GetLogger().Errorf("Auto Migration ==> %s", err.Error())
+		}
+	}
+
+	log.GetLogger().Info("Account Module Configured.")
+}
@@ -0,0 +1,101 @@
+package account
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+)
+
+// Reconciliation statuses.
+const (
+	ReconciliationStatusInProgress = "in_progress"
+	ReconciliationStatusCompleted  = "completed"
+)
+
+// Reconciliation will contain details of account reconciliation against a bank statement.
+type Reconciliation struct {
+	general.Base
+	User             userModel.User     `json:"-" gorm:"foreignKey:UserID"` // added to create foregin key. can't create using constraint
+	Account          Account            `json:"-" gorm:"foreignKey:AccountID"`
+	UserID           uuid.UUID          `json:"userID" gorm:"type:char(36);index:idx_user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	AccountID        uuid.UUID          `json:"accountID" gorm:"type:char(36);index:idx_account_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Budget           budgetModel.Budget `json:"-" gorm:"foreignKey:BudgetID"`
+	BudgetID         uuid.UUID          `json:"budgetID" gorm:"type:char(36);index:idx_budget_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TenantID         uuid.UUID          `json:"-" gorm:"type:char(36);index:idx_tenant_id"`
+	StatementDate    general.Date       `json:"statementDate" gorm:"type:datetime;not_null"`
+	StatementBalance float64            `json:"statementBalance" gorm:"type:decimal(10,2);not_null"`
+	ClearedBalance   float64            `json:"clearedBalance" gorm:"type:decimal(10,2)"`
+	Difference       float64            `json:"difference" gorm:"type:decimal(10,2)"`
+	Status           string             `json:"status" gorm:"type:varchar(20);not_null"`
+	CompletedAt      *time.Time         `json:"completedAt" gorm:"type:datetime"`
+}
+
+// TableName will specify table name for reconciliation struct.
+func (*Reconciliation) TableName() string {
+	return "reconciliations"
+}
+
+// Validate will verify compulsory fields of reconciliation.
+func (r *Reconciliation) Validate() error {
+
+	if r.UserID == uuid.Nil {
+		return errors.NewValidationError("user must be specified")
+	}
+
+	if r.AccountID == uuid.Nil {
+		return errors.NewValidationError("account must be specified")
+	}
+
+	if r.BudgetID == uuid.Nil {
+		return errors.NewValidationError("budget must be specified")
+	}
+
+	if r.StatementDate.IsZero() {
+		return errors.NewValidationError("statement date must be specified")
+	}
+
+	return nil
+}
+
+// IsCompleted returns true if reconciliation is completed.
+func (r *Reconciliation) IsCompleted() bool {
+	return r.Status == ReconciliationStatusCompleted
+}
+
+// ReconciliationDTO contains fields for DTO specifically.
+type ReconciliationDTO struct {
+	general.BaseDTO
+	UserID           uuid.UUID    `json:"userID"`
+	AccountID        uuid.UUID    `json:"accountID"`
+	BudgetID         uuid.UUID    `json:"budgetID"`
+	TenantID         uuid.UUID    `json:"-"`
+	StatementDate    general.Date `json:"statementDate"`
+	StatementBalance float64      `json:"statementBalance"`
+	ClearedBalance   float64      `json:"clearedBalance"`
+	Difference       float64      `json:"difference"`
+	Status           string       `json:"status"`
+	CompletedAt      *time.Time   `json:"completedAt"`
+}
+
+// TableName will specify table name for reconciliation struct.
+func (*ReconciliationDTO) TableName() string {
+	return "reconciliations"
+}
+
+// ReconciliationTransactions contains transactions to be ticked off (or unticked) in a reconciliation.
+type ReconciliationTransactions struct {
+	TransactionIDs []uuid.UUID `json:"transactionIDs"`
+	Cleared        bool        `json:"cleared"`
+}
+
+// Validate will verify compulsory fields of reconciliation transactions.
+func (r *ReconciliationTransactions) Validate() error {
+	if len(r.TransactionIDs) == 0 {
+		return errors.NewValidationError("transactions must be specified")
+	}
+	return nil
+}
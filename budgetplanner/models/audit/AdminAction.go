@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+)
+
+// Actions performed by admins on users.
+const (
+	AdminActionDisable       = "disable"
+	AdminActionEnable        = "enable"
+	AdminActionImpersonate   = "impersonate"
+	AdminActionPasswordReset = "password_reset"
+	AdminActionChangeRole    = "change_role"
+)
+
+// AdminAction records an action performed by admin on a user.
+type AdminAction struct {
+	general.Base
+	AdminID      uuid.UUID `json:"adminID" gorm:"type:char(36);not_null;index:idx_admin_id"`
+	TargetUserID uuid.UUID `json:"targetUserID" gorm:"type:char(36);not_null;index:idx_target_user_id"`
+	Action       string    `json:"action" gorm:"type:varchar(20);not_null"`
+	Details      string    `json:"details" gorm:"type:varchar(255)"`
+	IPAddress    string    `json:"ipAddress" gorm:"type:varchar(45)"`
+}
+
+// TableName will specify table name for admin action struct.
+func (*AdminAction) TableName() string {
+	return "admin_actions"
+}
+
+// AdminActionDTO contains fields for DTO specifically.
+type AdminActionDTO struct {
+	general.BaseDTO
+	AdminID      uuid.UUID `json:"adminID"`
+	TargetUserID uuid.UUID `json:"targetUserID"`
+	Action       string    `json:"action"`
+	Details      string    `json:"details"`
+	IPAddress    string    `json:"ipAddress"`
+	Timestamp    time.Time `json:"timestamp" gorm:"column:created_at"`
+}
+
+// TableName will specify table name for admin action struct.
+func (*AdminActionDTO) TableName() string {
+	return "admin_actions"
+}
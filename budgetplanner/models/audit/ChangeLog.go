@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/encryption"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+)
+
+// Entities for which changes are recorded.
+const (
+	EntityTransaction = "transaction"
+	EntityEnvelop     = "envelop"
+	EntityAccount     = "account"
+)
+
+// Actions recorded in change log.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionRevert  = "revert"
+)
+
+// ChangeLog will contain a version of an entity along with the fields changed in that version.
+type ChangeLog struct {
+	general.Base
+	EntityType     string            `json:"entityType" gorm:"type:varchar(50);not_null;uniqueIndex:idx_entity_version"`
+	EntityID       uuid.UUID         `json:"entityID" gorm:"type:char(36);not_null;uniqueIndex:idx_entity_version"`
+	Version        int               `json:"version" gorm:"not_null;uniqueIndex:idx_entity_version"`
+	Action         string            `json:"action" gorm:"type:varchar(20);not_null"`
+	ActorID        uuid.UUID         `json:"actorID" gorm:"type:char(36)"`
+	ImpersonatorID *uuid.UUID        `json:"impersonatorID" gorm:"type:char(36)"`
+	Changes        encryption.String `json:"-" gorm:"type:mediumtext"`
+	Snapshot       encryption.String `json:"-" gorm:"type:mediumtext"`
+}
+
+// TableName will specify table name for change log struct.
+func (*ChangeLog) TableName() string {
+	return "change_logs"
+}
+
+// FieldChange contains old and new value of a field.
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// ChangeLogDTO contains fields for DTO specifically.
+type ChangeLogDTO struct {
+	general.BaseDTO
+	EntityType     string            `json:"entityType"`
+	EntityID       uuid.UUID         `json:"entityID"`
+	Version        int               `json:"version"`
+	Action         string            `json:"action"`
+	ActorID        uuid.UUID         `json:"actorID"`
+	ImpersonatorID *uuid.UUID        `json:"impersonatorID"`
+	Timestamp      time.Time         `json:"timestamp" gorm:"column:created_at"`
+	RawChanges     encryption.String `json:"-" gorm:"column:changes"`
+	Changes        []FieldChange     `json:"changes" gorm:"-"`
+}
+
+// TableName will specify table name for change log struct.
+func (*ChangeLogDTO) TableName() string {
+	return "change_logs"
+}
@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"sync"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	"gorm.io/gorm"
+)
+
+// ModuleConfig use for Automigrant Tables.
+type ModuleConfig struct {
+	db *gorm.DB
+}
+
+// NewAuditModuleConfig Return New Module Config.
+func NewAuditModuleConfig(db *gorm.DB) *ModuleConfig {
+	return &ModuleConfig{
+		db: db,
+	}
+}
+
+// TableMigration Update Table Structure with Latest Version.
+func (config *ModuleConfig) TableMigration(wg *sync.WaitGroup) {
+	var models []interface{} = []interface{}{
+		&ChangeLog{}, &AdminAction{},
+	}
+
+	for _, model := range models {
+		err := config.db.Debug().Migrator().AutoMigrate(model)
+		if err != nil {
+			log.GetLogger().Errorf("Auto Migration ==> %s", err.Error())
+		}
+	}
+
+	log.GetLogger().Info("Audit Module Configured.")
+}
@@ -0,0 +1,185 @@
+package budget
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+)
+
+// Roles of members of budget. Owner can manage budget and its members, editor can change
+// envelops, accounts and transactions and viewer can only see them.
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// roleRanks orders roles, role with higher rank has every permission of role with lower rank.
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// HasRole returns true if role grants every permission of required role.
+func HasRole(role, required string) bool {
+	return roleRanks[role] >= roleRanks[required] && roleRanks[role] > 0
+}
+
+// IsValidRole returns true if role can be given to a member.
+func IsValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// PersonalBudgetName is name of budget created for every user.
+const PersonalBudgetName = "Personal"
+
+// Budget owns envelops, accounts and transactions which are shared by its members.
+// Every user has a personal budget which can't be deleted.
+type Budget struct {
+	general.Base
+	Name       string    `json:"name" gorm:"type:varchar(100);not_null"`
+	IsPersonal bool      `json:"isPersonal" gorm:"type:tinyint;default:0"`
+	TenantID   uuid.UUID `json:"-" gorm:"type:char(36);index:idx_tenant_id"`
+}
+
+// TableName will specify table name for budget struct.
+func (*Budget) TableName() string {
+	return "budgets"
+}
+
+// Validate will verify compulsory fields of budget.
+func (b *Budget) Validate() error {
+	b.Name = strings.TrimSpace(b.Name)
+	if len(b.Name) == 0 {
+		return errors.NewValidationError("budget name must be specified")
+	}
+	return nil
+}
+
+// BudgetDTO contains fields for DTO specifically.
+type BudgetDTO struct {
+	general.BaseDTO
+	Name       string    `json:"name"`
+	IsPersonal bool      `json:"isPersonal"`
+	Role       string    `json:"role"`
+	TenantID   uuid.UUID `json:"-"`
+}
+
+// TableName will specify table name for budget struct.
+func (*BudgetDTO) TableName() string {
+	return "budgets"
+}
+
+// BudgetMember gives user access to budget with a role.
+type BudgetMember struct {
+	general.Base
+	Budget   Budget         `json:"-" gorm:"foreignKey:BudgetID"`
+	User     userModel.User `json:"-" gorm:"foreignKey:UserID"` // added to create foregin key. can't create using constraint
+	BudgetID uuid.UUID      `json:"budgetID" gorm:"type:char(36);uniqueIndex:idx_budget_user;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserID   uuid.UUID      `json:"userID" gorm:"type:char(36);uniqueIndex:idx_budget_user;index:idx_user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Role     string         `json:"role" gorm:"type:varchar(20);not_null"`
+}
+
+// TableName will specify table name for budget member struct.
+func (*BudgetMember) TableName() string {
+	return "budget_members"
+}
+
+// BudgetMemberDTO contains fields for DTO specifically.
+type BudgetMemberDTO struct {
+	general.BaseDTO
+	UserID uuid.UUID `json:"userID"`
+	Name   string    `json:"name"`
+	Email  string    `json:"email"`
+	Role   string    `json:"role"`
+}
+
+// TableName will specify table name for budget member struct.
+func (*BudgetMemberDTO) TableName() string {
+	return "budget_members"
+}
+
+// MemberRole is used to change role of a member.
+type MemberRole struct {
+	Role string `json:"role"`
+}
+
+// Validate will verify role of member.
+func (m *MemberRole) Validate() error {
+	m.Role = strings.ToLower(strings.TrimSpace(m.Role))
+	if !IsValidRole(m.Role) {
+		return errors.NewValidationError("role must be either owner, editor or viewer")
+	}
+	return nil
+}
+
+// BudgetInvite is sent by owner to email of user who should join the budget.
+type BudgetInvite struct {
+	general.Base
+	Budget     Budget     `json:"-" gorm:"foreignKey:BudgetID"`
+	BudgetID   uuid.UUID  `json:"budgetID" gorm:"type:char(36);index:idx_budget_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Email      string     `json:"email" gorm:"type:varchar(255)"`
+	Role       string     `json:"role" gorm:"type:varchar(20);not_null"`
+	TokenHash  string     `json:"-" gorm:"type:varchar(64);unique;index:idx_token_hash"`
+	ExpiresAt  time.Time  `json:"expiresAt" gorm:"type:datetime"`
+	AcceptedAt *time.Time `json:"-" gorm:"type:datetime"`
+}
+
+// TableName will specify table name for budget invite struct.
+func (*BudgetInvite) TableName() string {
+	return "budget_invites"
+}
+
+// Validate will verify compulsory fields of budget invite. Owners can't be invited,
+// members can be made owner once they join.
+func (i *BudgetInvite) Validate() error {
+	i.Email = strings.TrimSpace(i.Email)
+	if len(i.Email) == 0 {
+		return errors.NewValidationError("email must be specified")
+	}
+
+	i.Role = strings.ToLower(strings.TrimSpace(i.Role))
+	if i.Role != RoleEditor && i.Role != RoleViewer {
+		return errors.NewValidationError("role must be either editor or viewer")
+	}
+	return nil
+}
+
+// IsActive returns true if invite can still be accepted.
+func (i *BudgetInvite) IsActive() bool {
+	return i.AcceptedAt == nil && i.ExpiresAt.After(time.Now())
+}
+
+// BudgetInviteDTO contains fields for DTO specifically.
+type BudgetInviteDTO struct {
+	general.BaseDTO
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// TableName will specify table name for budget invite struct.
+func (*BudgetInviteDTO) TableName() string {
+	return "budget_invites"
+}
+
+// InviteAcceptance contains token of invite sent in email.
+type InviteAcceptance struct {
+	Token string `json:"token"`
+}
+
+// Validate will verify compulsory fields of invite acceptance.
+func (a *InviteAcceptance) Validate() error {
+	a.Token = strings.TrimSpace(a.Token)
+	if len(a.Token) == 0 {
+		return errors.NewValidationError("token must be specified")
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package budget
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	"gorm.io/gorm"
+)
+
+// ModuleConfig use for Automigrant Tables.
+type ModuleConfig struct {
+	db *gorm.DB
+}
+
+// NewBudgetModuleConfig Return New Module Config.
+func NewBudgetModuleConfig(db *gorm.DB) *ModuleConfig {
+	return &ModuleConfig{
+		db: db,
+	}
+}
+
+// TableMigration Update Table Structure with Latest Version.
+func (config *ModuleConfig) TableMigration(wg *sync.WaitGroup) {
+	var models []interface{} = []interface{}{
+		&Budget{}, &BudgetMember{}, &BudgetInvite{},
+	}
+
+	for _, model := range models {
+		err := config.db.Debug().AutoMigrate(model)
+		if err != nil {
+			log.GetLogger().Errorf("Auto Migration ==> %s", err.Error())
+		}
+	}
+
+	log.GetLogger().Info("Budget Module Configured.")
+}
+
+// PersonalBudgetMigration moves data which was owned by users into their personal budgets.
+// It must run after tables of every module are migrated.
+type PersonalBudgetMigration struct {
+	db *gorm.DB
+}
+
+// NewPersonalBudgetMigration Return New Personal Budget Migration.
+func NewPersonalBudgetMigration(db *gorm.DB) *PersonalBudgetMigration {
+	return &PersonalBudgetMigration{
+		db: db,
+	}
+}
+
+// ownedTables are tables whose records were owned by user_id before budgets.
+var ownedTables = []string{"envelops", "accounts", "transactions", "reconciliations"}
+
+// TableMigration will create personal budget for users who don't have one and assign
+// records without budget to personal budget of the user who created them.
+func (config *PersonalBudgetMigration) TableMigration(wg *sync.WaitGroup) {
+	var userIDs []uuid.UUID
+
+	err := config.db.Table("users").Where("users.`id` NOT IN (SELECT budget_members.`user_id`"+
+		" FROM budget_members INNER JOIN budgets ON budgets.`id` = budget_members.`budget_id`"+
+		" WHERE budgets.`is_personal` = 1)").Pluck("id", &userIDs).Error
+	if err != nil {
+		log.GetLogger().Errorf("Personal Budget Migration ==> %s", err.Error())
+		return
+	}
+
+	for _, userID := range userIDs {
+		err = config.db.Transaction(func(tx *gorm.DB) error {
+			budget := Budget{Name: PersonalBudgetName, IsPersonal: true}
+
+			err := tx.Create(&budget).Error
+			if err != nil {
+				return err
+			}
+
+			return tx.Create(&BudgetMember{BudgetID: budget.ID, UserID: userID, Role: RoleOwner}).Error
+		})
+		if err != nil {
+			log.GetLogger().Errorf("Personal Budget Migration ==> %s", err.Error())
+			return
+		}
+	}
+
+	for _, table := range ownedTables {
+		err = config.db.Exec("UPDATE " + table + " SET `budget_id` = (SELECT budget_members.`budget_id`" +
+			" FROM budget_members INNER JOIN budgets ON budgets.`id` = budget_members.`budget_id`" +
+			" WHERE budgets.`is_personal` = 1 AND budget_members.`user_id` = " + table + ".`user_id` LIMIT 1)" +
+			" WHERE `budget_id` IS NULL OR `budget_id` = ''").Error
+		if err != nil {
+			log.GetLogger().Errorf("Personal Budget Migration ==> %s", err.Error())
+		}
+	}
+
+	log.GetLogger().Info("Personal Budgets Migrated.")
+}
@@ -0,0 +1,109 @@
+package envelop
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+)
+
+// DefaultAttachmentMaxBytes is maximum size of uploaded attachment when ATTACHMENT_MAX_BYTES is not set.
+const DefaultAttachmentMaxBytes = 10 << 20
+
+// MaxAttachmentsPerTransaction is maximum number of attachments of a transaction.
+const MaxAttachmentsPerTransaction = 10
+
+// maxFileNameLength is maximum length of name of attached file.
+const maxFileNameLength = 255
+
+// AttachmentContentTypes contains content types of files which can be attached, detected from their content.
+var AttachmentContentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// Attachment is a receipt or any other document attached to a transaction. Attachments of deleted
+// transaction are kept until the transaction is purged, so they are back when transaction is restored.
+type Attachment struct {
+	general.Base
+	Transaction   Transaction `json:"-" gorm:"foreignKey:TransactionID"`
+	TransactionID uuid.UUID   `json:"transactionID" gorm:"type:char(36);index:idx_transaction_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	BudgetID      uuid.UUID   `json:"budgetID" gorm:"type:char(36);index:idx_budget_id"`
+	TenantID      uuid.UUID   `json:"-" gorm:"type:char(36);index:idx_tenant_id"`
+	UserID        uuid.UUID   `json:"userID" gorm:"type:char(36);index:idx_user_id"`
+	FileName      string      `json:"fileName" gorm:"type:varchar(255);not_null"`
+	ContentType   string      `json:"contentType" gorm:"type:varchar(100);not_null"`
+	Size          int64       `json:"size" gorm:"type:bigint;not_null"`
+	StorageKey    string      `json:"-" gorm:"type:varchar(255);not_null"`
+}
+
+// TableName will specify table name for attachment struct.
+func (*Attachment) TableName() string {
+	return "attachments"
+}
+
+// Validate will verify name of the attached file and strip any path or control characters from it.
+func (a *Attachment) Validate() error {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' || r == '\\' {
+			return -1
+		}
+		return r
+	}, filepath.Base(strings.ReplaceAll(a.FileName, "\\", "/")))
+
+	name = strings.TrimSpace(name)
+	if len(name) == 0 || name == "." || name == "/" {
+		return errors.NewValidationError("file name must be specified")
+	}
+
+	if len(name) > maxFileNameLength {
+		return errors.NewValidationError("file name must not be longer than 255 characters")
+	}
+
+	a.FileName = name
+	return nil
+}
+
+// BuildStorageKey will return key under which content of the attachment is stored.
+func (a *Attachment) BuildStorageKey() string {
+	return "budgets/" + a.BudgetID.String() + "/transactions/" + a.TransactionID.String() +
+		"/attachments/" + a.ID.String()
+}
+
+// AttachmentDTO contains fields for DTO specifically.
+type AttachmentDTO struct {
+	general.BaseDTO
+	TransactionID uuid.UUID `json:"transactionID"`
+	BudgetID      uuid.UUID `json:"budgetID"`
+	TenantID      uuid.UUID `json:"-"`
+	UserID        uuid.UUID `json:"userID"`
+	FileName      string    `json:"fileName"`
+	ContentType   string    `json:"contentType"`
+	Size          int64     `json:"size"`
+	UploadedAt    time.Time `json:"uploadedAt" gorm:"column:created_at"`
+}
+
+// TableName will specify table name for attachment struct.
+func (*AttachmentDTO) TableName() string {
+	return "attachments"
+}
+
+// AttachmentUpload contains file uploaded by member of the budget.
+type AttachmentUpload struct {
+	Attachment
+	Data []byte
+}
+
+// AttachmentFile contains attachment being downloaded. Content must be closed after it is read.
+type AttachmentFile struct {
+	Attachment
+	Content io.ReadCloser
+}
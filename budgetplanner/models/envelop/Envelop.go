@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
 	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
 )
@@ -12,10 +13,13 @@ import (
 // Envelop will consist of data related to user envelops.
 type Envelop struct {
 	general.Base
-	Name   string         `json:"name" gorm:"type:varchar(100);not_null"`
-	User   userModel.User `json:"-" gorm:"foreignKey:UserID"` // added to create foregin key. can't create using constraint
-	UserID uuid.UUID      `json:"userID" gorm:"type:char(36);index:idx_user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	Amount float64        `json:"amount" gorm:"type:decimal(10, 2);not_null"`
+	Name     string             `json:"name" gorm:"type:varchar(100);not_null"`
+	User     userModel.User     `json:"-" gorm:"foreignKey:UserID"` // added to create foregin key. can't create using constraint
+	UserID   uuid.UUID          `json:"userID" gorm:"type:char(36);index:idx_user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Budget   budgetModel.Budget `json:"-" gorm:"foreignKey:BudgetID"`
+	BudgetID uuid.UUID          `json:"budgetID" gorm:"type:char(36);index:idx_budget_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TenantID uuid.UUID          `json:"-" gorm:"type:char(36);index:idx_tenant_id"`
+	Amount   float64            `json:"amount" gorm:"type:decimal(10, 2);not_null"`
 	// add account id to table
 }
 
@@ -37,6 +41,10 @@ func (e *Envelop) Validate() error {
 		return errors.NewValidationError("user must be specified")
 	}
 
+	if e.BudgetID == uuid.Nil {
+		return errors.NewValidationError("budget must be specified")
+	}
+
 	if e.Amount == 0 {
 		return errors.NewValidationError("amount must be greater than 0")
 	}
@@ -44,13 +52,55 @@ func (e *Envelop) Validate() error {
 	return nil
 }
 
+// Options to handle transactions of envelop which is being deleted.
+const (
+	// EnvelopDeleteRefuse will not delete envelop if it has any transaction.
+	EnvelopDeleteRefuse = "refuse"
+	// EnvelopDeleteReassign will move transactions to another envelop.
+	EnvelopDeleteReassign = "reassign"
+	// EnvelopDeleteCascade will delete transactions along with the envelop.
+	EnvelopDeleteCascade = "delete"
+)
+
+// EnvelopDeletion specifies how transactions of envelop should be handled when it is deleted.
+type EnvelopDeletion struct {
+	Transactions string     `json:"transactions"`
+	ReassignTo   *uuid.UUID `json:"reassignTo"`
+}
+
+// Validate will verify fields of envelop deletion. Envelop will not be deleted
+// if it has transactions and no option is specified.
+func (d *EnvelopDeletion) Validate() error {
+
+	d.Transactions = strings.TrimSpace(d.Transactions)
+	if len(d.Transactions) == 0 {
+		d.Transactions = EnvelopDeleteRefuse
+	}
+
+	switch d.Transactions {
+	case EnvelopDeleteRefuse, EnvelopDeleteCascade:
+		d.ReassignTo = nil
+	case EnvelopDeleteReassign:
+		if d.ReassignTo == nil || *d.ReassignTo == uuid.Nil {
+			return errors.NewValidationError("envelop to reassign transactions must be specified")
+		}
+	default:
+		return errors.NewValidationError("transactions must be either refuse, reassign or delete")
+	}
+
+	return nil
+}
+
 // EnvelopDTO contains fields for DTO specifically.
 type EnvelopDTO struct {
 	general.BaseDTO
-	Name        string    `json:"name"`
-	UserID      uuid.UUID `json:"userID"`
-	Amount      float64   `json:"amount"`
-	AmountSpent float64   `json:"amountSpent"`
+	Name        string        `json:"name"`
+	UserID      uuid.UUID     `json:"userID"`
+	BudgetID    uuid.UUID     `json:"budgetID"`
+	TenantID    uuid.UUID     `json:"-"`
+	Amount      float64       `json:"amount"`
+	AmountSpent float64       `json:"amountSpent"`
+	Goal        *GoalProgress `json:"goal" gorm:"-"`
 }
 
 // TableName will specify table name for envelop struct.
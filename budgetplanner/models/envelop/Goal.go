@@ -0,0 +1,222 @@
+package envelop
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+)
+
+// Ways of contributing to a goal every budget period.
+const (
+	// GoalContributionByDate spreads what is left to save evenly over periods until target date.
+	GoalContributionByDate = "by_date"
+	// GoalContributionFixed contributes fixed monthly amount until target amount is saved.
+	GoalContributionFixed = "fixed"
+)
+
+// maxGoalAmount is maximum amount that fits decimal(10,2) column.
+const maxGoalAmount = 99999999.99
+
+// Goal is target envelop is saving toward, like 5,000 for a car by June 2027. Contributions are credit
+// transactions of the envelop and debit transactions are withdrawals from what is saved.
+type Goal struct {
+	general.Base
+	Envelop          Envelop      `json:"-" gorm:"foreignKey:EnvelopID"`
+	EnvelopID        uuid.UUID    `json:"envelopID" gorm:"type:char(36);unique;index:idx_envelop_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	BudgetID         uuid.UUID    `json:"budgetID" gorm:"type:char(36);index:idx_budget_id"`
+	TenantID         uuid.UUID    `json:"-" gorm:"type:char(36);index:idx_tenant_id"`
+	UserID           uuid.UUID    `json:"userID" gorm:"type:char(36);index:idx_user_id"`
+	TargetAmount     float64      `json:"targetAmount" gorm:"type:decimal(10,2);not_null"`
+	TargetDate       general.Date `json:"targetDate" gorm:"type:datetime"`
+	ContributionType string       `json:"contributionType" gorm:"type:varchar(20);not_null"`
+	MonthlyAmount    float64      `json:"monthlyAmount" gorm:"type:decimal(10,2);default:0"`
+}
+
+// TableName will specify table name for goal struct.
+func (*Goal) TableName() string {
+	return "envelop_goals"
+}
+
+// Validate will verify fields of goal. Target date is required when contribution is spread
+// until it and monthly amount is required when it is fixed.
+func (g *Goal) Validate() error {
+
+	if g.TargetAmount <= 0 || g.TargetAmount > maxGoalAmount {
+		return errors.NewValidationError("target amount must be greater than 0 and less than 100000000")
+	}
+
+	g.ContributionType = strings.TrimSpace(g.ContributionType)
+	if len(g.ContributionType) == 0 {
+		g.ContributionType = GoalContributionByDate
+	}
+
+	switch g.ContributionType {
+	case GoalContributionByDate:
+		if g.TargetDate.IsZero() {
+			return errors.NewValidationError("target date must be specified")
+		}
+		g.MonthlyAmount = 0
+	case GoalContributionFixed:
+		if g.MonthlyAmount <= 0 || g.MonthlyAmount > maxGoalAmount {
+			return errors.NewValidationError("monthly amount must be greater than 0 and less than 100000000")
+		}
+	default:
+		return errors.NewValidationError("contribution type must be either by_date or fixed")
+	}
+
+	return nil
+}
+
+// GoalDTO contains fields for DTO specifically.
+type GoalDTO struct {
+	general.BaseDTO
+	EnvelopID        uuid.UUID  `json:"envelopID"`
+	BudgetID         uuid.UUID  `json:"budgetID"`
+	TenantID         uuid.UUID  `json:"-"`
+	UserID           uuid.UUID  `json:"userID"`
+	TargetAmount     float64    `json:"targetAmount"`
+	TargetDate       *time.Time `json:"targetDate"`
+	ContributionType string     `json:"contributionType"`
+	MonthlyAmount    float64    `json:"monthlyAmount"`
+}
+
+// TableName will specify table name for goal struct.
+func (*GoalDTO) TableName() string {
+	return "envelop_goals"
+}
+
+// GoalProgress contains goal along with how much is saved toward it and what is needed in current
+// budget period of the user to stay on track.
+type GoalProgress struct {
+	GoalDTO
+	Saved                   float64    `json:"saved"`
+	Remaining               float64    `json:"remaining"`
+	PercentComplete         float64    `json:"percentComplete"`
+	PeriodStart             time.Time  `json:"periodStart"`
+	PeriodEnd               time.Time  `json:"periodEnd"`
+	ContributedThisPeriod   float64    `json:"contributedThisPeriod"`
+	RequiredThisPeriod      float64    `json:"requiredThisPeriod"`
+	Shortfall               float64    `json:"shortfall"`
+	IsComplete              bool       `json:"isComplete"`
+	IsOnTrack               bool       `json:"isOnTrack"`
+	ProjectedCompletionDate *time.Time `json:"projectedCompletionDate"`
+}
+
+// IsUnderfunded returns true if less than required has been contributed in current period.
+func (p *GoalProgress) IsUnderfunded() bool {
+	return p.Shortfall > 0
+}
+
+// NewGoalProgress will compute progress of the goal at now. saved is net amount saved in envelop and
+// contributed is net amount saved in current budget period of the user.
+//
+// Contribution required in current period is what was left to save at its start, spread evenly over
+// periods until target date, or the fixed monthly amount. Completion is projected at the pace of fixed
+// monthly amount, or at average pace since goal was created when it is saved by date.
+func NewGoalProgress(goal *GoalDTO, saved, contributed float64, preference *userModel.Preference,
+	now time.Time) *GoalProgress {
+
+	periodStart, periodEnd := preference.Period(now)
+
+	progress := &GoalProgress{
+		GoalDTO:               *goal,
+		Saved:                 roundAmount(saved),
+		PeriodStart:           periodStart,
+		PeriodEnd:             periodEnd,
+		ContributedThisPeriod: roundAmount(contributed),
+	}
+
+	if progress.TargetDate != nil {
+		targetDate := progress.TargetDate.In(preference.Location())
+		progress.TargetDate = &targetDate
+	}
+
+	progress.Remaining = roundAmount(math.Max(goal.TargetAmount-saved, 0))
+	progress.PercentComplete = roundAmount(math.Min(math.Max(saved, 0)/goal.TargetAmount*100, 100))
+	progress.IsComplete = progress.Remaining == 0
+
+	remainingAtStart := math.Max(goal.TargetAmount-(saved-contributed), 0)
+
+	switch goal.ContributionType {
+	case GoalContributionFixed:
+		progress.RequiredThisPeriod = math.Min(goal.MonthlyAmount, remainingAtStart)
+	default:
+		periods := 1
+		if goal.TargetDate != nil {
+			periods = monthsBetween(periodStart, preference.StartOfPeriod(*goal.TargetDate)) + 1
+		}
+		if periods < 1 {
+			periods = 1
+		}
+		progress.RequiredThisPeriod = remainingAtStart / float64(periods)
+	}
+
+	progress.RequiredThisPeriod = roundUpAmount(progress.RequiredThisPeriod)
+	progress.Shortfall = roundAmount(math.Max(progress.RequiredThisPeriod-contributed, 0))
+	progress.IsOnTrack = progress.IsComplete || progress.Shortfall == 0
+
+	if !progress.IsComplete {
+		progress.ProjectedCompletionDate = projectCompletion(progress, preference, periodStart)
+	}
+
+	return progress
+}
+
+// projectCompletion returns last day of period in which goal is projected to be complete, nil is
+// returned when nothing is being saved.
+func projectCompletion(progress *GoalProgress, preference *userModel.Preference, periodStart time.Time) *time.Time {
+
+	pace := progress.MonthlyAmount
+	if progress.ContributionType != GoalContributionFixed {
+		periods := monthsBetween(preference.StartOfPeriod(progress.CreatedAt), periodStart) + 1
+		pace = progress.Saved / float64(periods)
+	}
+
+	if pace <= 0 {
+		return nil
+	}
+
+	// what is still to be contributed in current period is counted toward it.
+	remaining := progress.Remaining - math.Max(pace-progress.ContributedThisPeriod, 0)
+	periods := 1
+	if remaining > 0 {
+		periods += int(math.Ceil(remaining / pace))
+	}
+
+	completion := periodStart.AddDate(0, periods, -1)
+	return &completion
+}
+
+// UnderfundedGoal contains goal which has not received contribution required in current period.
+type UnderfundedGoal struct {
+	EnvelopName string `json:"envelopName"`
+	GoalProgress
+}
+
+// UnderfundedReport contains underfunded goals of the budget, with largest shortfall first.
+type UnderfundedReport struct {
+	PeriodStart    time.Time         `json:"periodStart"`
+	PeriodEnd      time.Time         `json:"periodEnd"`
+	TotalShortfall float64           `json:"totalShortfall"`
+	Goals          []UnderfundedGoal `json:"goals"`
+}
+
+// monthsBetween returns number of whole months from month of from to month of to.
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
+
+// roundAmount will round amount to cents.
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// roundUpAmount will round amount up to cents, so that contributing it is never short of target.
+func roundUpAmount(amount float64) float64 {
+	return math.Ceil(math.Round(amount*1e6)/1e4) / 100
+}
@@ -24,6 +24,8 @@ func (config *ModuleConfig) TableMigration(wg *sync.WaitGroup) {
 	var models []interface{} = []interface{}{
 		&Envelop{},
 		&Transaction{},
+		&Attachment{},
+		&Goal{},
 	}
 
 	for _, model := range models {
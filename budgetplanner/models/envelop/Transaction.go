@@ -5,7 +5,10 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/encryption"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	accountModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/account"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
 	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
 )
@@ -13,17 +16,41 @@ import (
 // Transaction will contain all details related to user transactions.
 type Transaction struct {
 	general.Base
-	User            userModel.User `json:"-" gorm:"foreignKey:UserID"` // added to create foregin key. can't create using constraint
-	Envelop         Envelop        `json:"-" gorm:"foreignKey:EnvelopID"`
-	UserID          uuid.UUID      `json:"userID" gorm:"type:char(36);index:idx_user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	EnvelopID       uuid.UUID      `json:"envelopID" gorm:"type:char(36);index:idx_envelop_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	Payee           string         `json:"payee" gorm:"type:varchar(100);not_null"`
-	Amount          float64        `json:"amount" gorm:"type:decimal(10,2);not_null"`
-	Date            string         `json:"date" gorm:"type:datetime;not_null"`
-	TransactionType string         `json:"transactionType" gorm:"type:varchar(255)"`
-	Description     *string        `json:"description" gorm:"type:varchar(1000)"`
+	User             userModel.User        `json:"-" gorm:"foreignKey:UserID"` // added to create foregin key. can't create using constraint
+	Envelop          Envelop               `json:"-" gorm:"foreignKey:EnvelopID"`
+	Account          *accountModel.Account `json:"-" gorm:"foreignKey:AccountID"`
+	UserID           uuid.UUID             `json:"userID" gorm:"type:char(36);index:idx_user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Budget           budgetModel.Budget    `json:"-" gorm:"foreignKey:BudgetID"`
+	BudgetID         uuid.UUID             `json:"budgetID" gorm:"type:char(36);index:idx_budget_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TenantID         uuid.UUID             `json:"-" gorm:"type:char(36);index:idx_tenant_id"`
+	EnvelopID        uuid.UUID             `json:"envelopID" gorm:"type:char(36);index:idx_envelop_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	AccountID        *uuid.UUID            `json:"accountID" gorm:"type:char(36);index:idx_account_id;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Payee            string                `json:"payee" gorm:"type:varchar(100);not_null"`
+	Amount           float64               `json:"amount" gorm:"type:decimal(10,2);not_null"`
+	Date             general.Date          `json:"date" gorm:"type:datetime;not_null"`
+	TransactionType  string                `json:"transactionType" gorm:"type:varchar(255)"`
+	Description      *encryption.String    `json:"description" gorm:"type:text"`
+	Status           string                `json:"status" gorm:"type:varchar(20);default:pending;not_null"`
+	ReconciliationID *uuid.UUID            `json:"reconciliationID" gorm:"type:char(36);index:idx_reconciliation_id"`
 }
 
+// Transaction types.
+const (
+	TransactionTypeCredit = "credit"
+	TransactionTypeDebit  = "debit"
+)
+
+// Transaction statuses.
+const (
+	TransactionStatusPending    = "pending"
+	TransactionStatusCleared    = "cleared"
+	TransactionStatusReconciled = "reconciled"
+)
+
+// maxDescriptionLength is maximum length of description of transaction.
+// Column is wider as description is stored encrypted.
+const maxDescriptionLength = 1000
+
 // TableName will specify table name for transaction struct.
 func (*Transaction) TableName() string {
 	return "transactions"
@@ -36,6 +63,10 @@ func (t *Transaction) Validate() error {
 		return errors.NewValidationError("user must be specified")
 	}
 
+	if t.BudgetID == uuid.Nil {
+		return errors.NewValidationError("budget must be specified")
+	}
+
 	if t.EnvelopID == uuid.Nil {
 		return errors.NewValidationError("envelop must be specified")
 	}
@@ -54,23 +85,46 @@ func (t *Transaction) Validate() error {
 		return errors.NewValidationError("transaction type must be specified")
 	}
 
-	if len(t.Date) == 0 {
+	if t.Date.IsZero() {
 		return errors.NewValidationError("date must be specified")
 	}
 
+	if t.Description != nil && len(*t.Description) > maxDescriptionLength {
+		return errors.NewValidationError("description must not be longer than 1000 characters")
+	}
+
+	if len(t.Status) == 0 {
+		t.Status = TransactionStatusPending
+	}
+
+	// reconciled status can only be set by completing a reconciliation.
+	if t.Status != TransactionStatusPending && t.Status != TransactionStatusCleared {
+		return errors.NewValidationError("status must be either pending or cleared")
+	}
+
 	return nil
 }
 
+// IsReconciled returns true if transaction is locked by a reconciliation.
+func (t *Transaction) IsReconciled() bool {
+	return t.Status == TransactionStatusReconciled
+}
+
 // TransactionDTO contains fields for DTO specifically.
 type TransactionDTO struct {
 	general.BaseDTO
-	Payee           string     `json:"payee"`
-	Amount          float64    `json:"amount"`
-	Date            time.Time  `json:"date"`
-	TransactionType string     `json:"transactionType"`
-	Description     *string    `json:"description"`
-	Envelop         EnvelopDTO `json:"envelop" gorm:"foreignKey:EnvelopID"`
-	EnvelopID       uuid.UUID  `json:"envelopID"`
+	Payee            string             `json:"payee"`
+	Amount           float64            `json:"amount"`
+	Date             time.Time          `json:"date"`
+	TransactionType  string             `json:"transactionType"`
+	Description      *encryption.String `json:"description"`
+	Status           string             `json:"status"`
+	Envelop          EnvelopDTO         `json:"envelop" gorm:"foreignKey:EnvelopID"`
+	EnvelopID        uuid.UUID          `json:"envelopID"`
+	BudgetID         uuid.UUID          `json:"budgetID"`
+	TenantID         uuid.UUID          `json:"-"`
+	AccountID        *uuid.UUID         `json:"accountID"`
+	ReconciliationID *uuid.UUID         `json:"reconciliationID"`
 }
 
 // TableName will specify table name for transaction struct.
@@ -0,0 +1,46 @@
+package envelop
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Trash contains envelops and transactions deleted by user which can still be restored.
+type Trash struct {
+	Envelops     []TrashEnvelop     `json:"envelops"`
+	Transactions []TrashTransaction `json:"transactions"`
+}
+
+// TrashEnvelop contains fields of deleted envelop.
+type TrashEnvelop struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Amount    float64   `json:"amount"`
+	DeletedAt time.Time `json:"deletedAt"`
+	PurgeAt   time.Time `json:"purgeAt" gorm:"-"`
+	TenantID  uuid.UUID `json:"-"`
+}
+
+// TableName will specify table name for trash envelop struct.
+func (*TrashEnvelop) TableName() string {
+	return "envelops"
+}
+
+// TrashTransaction contains fields of deleted transaction.
+type TrashTransaction struct {
+	ID              uuid.UUID `json:"id"`
+	EnvelopID       uuid.UUID `json:"envelopID"`
+	Payee           string    `json:"payee"`
+	Amount          float64   `json:"amount"`
+	Date            time.Time `json:"date"`
+	TransactionType string    `json:"transactionType"`
+	DeletedAt       time.Time `json:"deletedAt"`
+	PurgeAt         time.Time `json:"purgeAt" gorm:"-"`
+	TenantID        uuid.UUID `json:"-"`
+}
+
+// TableName will specify table name for trash transaction struct.
+func (*TrashTransaction) TableName() string {
+	return "transactions"
+}
@@ -0,0 +1,49 @@
+package general
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// actorKey is the context key under which the acting user is stored.
+type actorKey struct{}
+
+// WithActor will return a copy of ctx carrying the ID of the user performing the request.
+func WithActor(ctx context.Context, actorID uuid.UUID) context.Context {
+	return context.WithValue(ctx, actorKey{}, actorID)
+}
+
+// ActorFromContext will return the ID of the user performing the request, if any.
+func ActorFromContext(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+
+	actorID, ok := ctx.Value(actorKey{}).(uuid.UUID)
+	if !ok || actorID == uuid.Nil {
+		return uuid.Nil, false
+	}
+	return actorID, true
+}
+
+// impersonatorKey is the context key under which the admin impersonating the acting user is stored.
+type impersonatorKey struct{}
+
+// WithImpersonator will return a copy of ctx carrying the ID of the admin impersonating the acting user.
+func WithImpersonator(ctx context.Context, impersonatorID uuid.UUID) context.Context {
+	return context.WithValue(ctx, impersonatorKey{}, impersonatorID)
+}
+
+// ImpersonatorFromContext will return the ID of the admin impersonating the acting user, if any.
+func ImpersonatorFromContext(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+
+	impersonatorID, ok := ctx.Value(impersonatorKey{}).(uuid.UUID)
+	if !ok || impersonatorID == uuid.Nil {
+		return uuid.Nil, false
+	}
+	return impersonatorID, true
+}
@@ -1,6 +1,7 @@
 package general
 
 import (
+	"reflect"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,9 +15,9 @@ type Base struct {
 	UpdatedAt  time.Time  `json:"-"`
 	DeletedAt  *time.Time `gorm:"index:idx_deleted_at" json:"-"`
 	IgnoreHook bool       `gorm:"-" json:"-"`
-	// CreatedBy  uuid.UUID  `gorm:"type:varchar(36)" json:"-"`
-	// UpdatedBy  uuid.UUID  `gorm:"type:varchar(36)" json:"-"`
-	// DeletedBy  uuid.UUID  `gorm:"type:varchar(36)" json:"-"`
+	CreatedBy  *uuid.UUID `gorm:"type:varchar(36)" json:"-"`
+	UpdatedBy  *uuid.UUID `gorm:"type:varchar(36)" json:"-"`
+	DeletedBy  *uuid.UUID `gorm:"type:varchar(36)" json:"-"`
 }
 
 // BeforeCreate will be called before the entity is added to db.
@@ -26,6 +27,73 @@ func (b *Base) BeforeCreate(scope *gorm.DB) error {
 	}
 
 	b.ID = uuid.New()
+	if actorID, ok := ActorFromContext(scope.Statement.Context); ok {
+		b.CreatedBy = &actorID
+	}
+	if tenantID, ok := TenantFromContext(scope.Statement.Context); ok {
+		assignTenant(scope, tenantID)
+	}
+	return nil
+}
+
+// assignTenant will set tenant of the record being created when the entity belongs
+// to a tenant and tenant is not already specified.
+func assignTenant(scope *gorm.DB, tenantID uuid.UUID) {
+	if scope.Statement.Schema == nil {
+		return
+	}
+
+	field := scope.Statement.Schema.LookUpField("TenantID")
+	if field == nil {
+		return
+	}
+
+	value := scope.Statement.ReflectValue
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		value = value.Index(scope.Statement.CurDestIndex)
+	}
+
+	if _, isZero := field.ValueOf(scope.Statement.Context, value); isZero {
+		scope.Statement.SetColumn("TenantID", tenantID)
+	}
+}
+
+// BeforeUpdate will be called before the entity is updated in db.
+// It records the acting user as UpdatedBy, and as DeletedBy when DeletedAt is being set.
+func (b *Base) BeforeUpdate(scope *gorm.DB) error {
+	if b.IgnoreHook {
+		return nil
+	}
+
+	values, isMap := scope.Statement.Dest.(map[string]interface{})
+	if !isMap {
+		// full saves must not overwrite the creator or the tenant of the record.
+		scope.Statement.Omits = append(scope.Statement.Omits, "CreatedBy", "TenantID")
+	}
+
+	actorID, ok := ActorFromContext(scope.Statement.Context)
+	if !ok {
+		return nil
+	}
+	scope.Statement.SetColumn("UpdatedBy", &actorID)
+
+	if !isMap {
+		return nil
+	}
+
+	deletedAt, found := values["DeletedAt"]
+	if !found {
+		deletedAt, found = values["deleted_at"]
+	}
+	if !found {
+		return nil
+	}
+
+	if deletedAt == nil {
+		scope.Statement.SetColumn("DeletedBy", nil)
+		return nil
+	}
+	scope.Statement.SetColumn("DeletedBy", &actorID)
 	return nil
 }
 
@@ -0,0 +1,115 @@
+package general
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+)
+
+// Layouts of dates accepted by ParseDate.
+const (
+	DateLayout          = "2006-01-02"
+	LocalDateTimeLayout = "2006-01-02T15:04:05.999999999"
+)
+
+// Date is point in time given either as date like 2006-01-02, as local time like 2006-01-02T15:04:05
+// or as timestamp with offset like 2006-01-02T15:04:05+05:30. Date and local time are taken in timezone
+// of the user once Localize is called, timestamps are only converted to it.
+type Date struct {
+	time.Time
+	dateOnly bool
+	floating bool
+}
+
+// ParseDate will strictly parse date in any of the accepted formats.
+func ParseDate(value string) (Date, error) {
+	value = strings.TrimSpace(value)
+
+	if len(value) == 0 {
+		return Date{}, errors.NewValidationError("date must be specified")
+	}
+
+	date, err := time.Parse(DateLayout, value)
+	if err == nil {
+		return Date{Time: date, dateOnly: true, floating: true}, nil
+	}
+
+	date, err = time.Parse(LocalDateTimeLayout, value)
+	if err == nil {
+		return Date{Time: date, floating: true}, nil
+	}
+
+	date, err = time.Parse(time.RFC3339Nano, value)
+	if err == nil {
+		return Date{Time: date}, nil
+	}
+
+	return Date{}, errors.NewValidationError(fmt.Sprintf("date %q is not valid, it must be like 2006-01-02,"+
+		" 2006-01-02T15:04:05 or 2006-01-02T15:04:05Z07:00", value))
+}
+
+// IsDateOnly returns true if date was given without time of day.
+func (d Date) IsDateOnly() bool {
+	return d.dateOnly
+}
+
+// Localize will take date and local time in the location and convert timestamp to it.
+func (d *Date) Localize(location *time.Location) {
+	if d.floating {
+		d.Time = time.Date(d.Year(), d.Month(), d.Day(), d.Hour(), d.Minute(), d.Second(), d.Nanosecond(), location)
+		d.floating = false
+	}
+	d.Time = d.Time.In(location)
+}
+
+// UnmarshalJSON will parse date from JSON string.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = Date{}
+		return nil
+	}
+
+	var value string
+
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return errors.NewValidationError("date must be a string like 2006-01-02 or 2006-01-02T15:04:05Z07:00")
+	}
+
+	*d, err = ParseDate(value)
+	return err
+}
+
+// MarshalJSON will format date as RFC 3339 timestamp.
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return d.Time.MarshalJSON()
+}
+
+// Value will store date as time, it is written in UTC.
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return d.Time, nil
+}
+
+// Scan will read date stored as time.
+func (d *Date) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*d = Date{}
+		return nil
+	case time.Time:
+		*d = Date{Time: v}
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into date", value)
+	}
+}
@@ -0,0 +1,28 @@
+package general
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// tenantKey is the context key under which the tenant of the request is stored.
+type tenantKey struct{}
+
+// WithTenant will return a copy of ctx carrying the ID of the tenant the request is made for.
+func WithTenant(ctx context.Context, tenantID uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext will return the ID of the tenant the request is made for, if any.
+func TenantFromContext(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+
+	tenantID, ok := ctx.Value(tenantKey{}).(uuid.UUID)
+	if !ok || tenantID == uuid.Nil {
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
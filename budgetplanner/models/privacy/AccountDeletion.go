@@ -0,0 +1,66 @@
+package privacy
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	accountModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/account"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
+	envelopModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/envelop"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+)
+
+// AccountDeletion is request of user to delete their account. Account is erased once grace period
+// is over unless the request is cancelled. After erasure the record is kept as anonymized audit record,
+// user can only be matched to it by hash of their ID.
+type AccountDeletion struct {
+	general.Base
+	UserID      uuid.UUID  `json:"-" gorm:"type:char(36);index:idx_user_id"`
+	SubjectHash string     `json:"-" gorm:"type:varchar(64);index:idx_subject_hash"`
+	WithExport  bool       `json:"withExport" gorm:"type:tinyint;default:0"`
+	ScheduledAt time.Time  `json:"scheduledAt" gorm:"type:datetime;index:idx_scheduled_at"`
+	CancelledAt *time.Time `json:"-" gorm:"type:datetime"`
+	ErasedAt    *time.Time `json:"-" gorm:"type:datetime"`
+	Summary     string     `json:"-" gorm:"type:varchar(255)"`
+}
+
+// TableName will specify table name for account deletion struct.
+func (*AccountDeletion) TableName() string {
+	return "account_deletions"
+}
+
+// AccountDeletionRequest contains credentials of user confirming deletion of their account.
+// Code is required only when two factor authentication is enabled.
+type AccountDeletionRequest struct {
+	UserID   uuid.UUID `json:"-"`
+	Password string    `json:"password"`
+	Code     string    `json:"code"`
+	Export   bool      `json:"export"`
+}
+
+// Validate will verify compulsory fields of account deletion request.
+func (r *AccountDeletionRequest) Validate() error {
+	if len(strings.TrimSpace(r.Password)) == 0 {
+		return errors.NewValidationError("password must be specified")
+	}
+
+	r.Code = strings.TrimSpace(r.Code)
+	return nil
+}
+
+// AccountExport contains all data of the user, sent to them before their account is erased.
+type AccountExport struct {
+	ExportedAt      time.Time                     `json:"exportedAt"`
+	User            userModel.UserDTO             `json:"user"`
+	Preference      *userModel.Preference         `json:"preference"`
+	Budgets         []budgetModel.BudgetDTO       `json:"budgets"`
+	Envelops        []envelopModel.Envelop        `json:"envelops"`
+	Accounts        []accountModel.Account        `json:"accounts"`
+	Transactions    []envelopModel.Transaction    `json:"transactions"`
+	Reconciliations []accountModel.Reconciliation `json:"reconciliations"`
+	Attachments     []envelopModel.AttachmentDTO  `json:"attachments"`
+	Goals           []envelopModel.GoalDTO        `json:"goals"`
+}
@@ -0,0 +1,36 @@
+package privacy
+
+import (
+	"sync"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	"gorm.io/gorm"
+)
+
+// ModuleConfig use for Automigrant Tables.
+type ModuleConfig struct {
+	db *gorm.DB
+}
+
+// NewPrivacyModuleConfig Return New Module Config.
+func NewPrivacyModuleConfig(db *gorm.DB) *ModuleConfig {
+	return &ModuleConfig{
+		db: db,
+	}
+}
+
+// TableMigration Update Table Structure with Latest Version.
+func (config *ModuleConfig) TableMigration(wg *sync.WaitGroup) {
+	var models []interface{} = []interface{}{
+		&AccountDeletion{},
+	}
+
+	for _, model := range models {
+		err := config.db.Debug().AutoMigrate(model)
+		if err != nil {
+			log.GetLogger().Errorf("Auto Migration ==> %s", err.Error())
+		}
+	}
+
+	log.GetLogger().Info("Privacy Module Configured.")
+}
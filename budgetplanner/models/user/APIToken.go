@@ -0,0 +1,110 @@
+package user
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+)
+
+// APITokenPrefix is prefix of every personal access token, used to tell them apart from JWTs.
+const APITokenPrefix = "bp_pat_"
+
+// Scopes which can be granted to personal access tokens.
+const (
+	ScopeRead              = "read"
+	ScopeTransactionsWrite = "transactions:write"
+	ScopeEnvelopsWrite     = "envelops:write"
+	ScopeAccountsWrite     = "accounts:write"
+)
+
+// Scopes contains every valid scope.
+var Scopes = []string{ScopeRead, ScopeTransactionsWrite, ScopeEnvelopsWrite, ScopeAccountsWrite}
+
+// APIToken is a personal access token created by user for scripts and integrations.
+type APIToken struct {
+	general.Base
+	User       User       `json:"-" gorm:"foreignKey:UserID"` // added to create foregin key. can't create using constraint
+	UserID     uuid.UUID  `json:"userID" gorm:"type:char(36);index:idx_user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Name       string     `json:"name" gorm:"type:varchar(100)"`
+	TokenHash  string     `json:"-" gorm:"type:varchar(64);unique;index:idx_token_hash"`
+	Prefix     string     `json:"prefix" gorm:"type:varchar(20)"`
+	Scopes     string     `json:"-" gorm:"type:varchar(255)"`
+	ScopeList  []string   `json:"scopes" gorm:"-"`
+	ExpiresAt  *time.Time `json:"expiresAt" gorm:"type:datetime"`
+	LastUsedAt *time.Time `json:"lastUsedAt" gorm:"type:datetime"`
+}
+
+// TableName will specify table name for api token struct.
+func (*APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// Validate will verify compulsory fields of api token.
+func (a *APIToken) Validate() error {
+	a.Name = strings.TrimSpace(a.Name)
+	if len(a.Name) == 0 {
+		return errors.NewValidationError("name must be specified")
+	}
+
+	if len(a.ScopeList) == 0 {
+		return errors.NewValidationError("at least one scope must be specified")
+	}
+
+	for _, scope := range a.ScopeList {
+		if !IsValidScope(scope) {
+			return errors.NewValidationError(fmt.Sprintf("invalid scope %s", scope))
+		}
+	}
+
+	if a.ExpiresAt != nil && !a.ExpiresAt.After(time.Now()) {
+		return errors.NewValidationError("expiry must be in future")
+	}
+
+	a.Scopes = strings.Join(a.ScopeList, ",")
+	return nil
+}
+
+// APITokenDTO contains fields for DTO specifically.
+type APITokenDTO struct {
+	general.BaseDTO
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     string     `json:"-"`
+	ScopeList  []string   `json:"scopes" gorm:"-"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+}
+
+// TableName will specify table name for api token struct.
+func (*APITokenDTO) TableName() string {
+	return "api_tokens"
+}
+
+// CreatedAPIToken is sent once when token is created, token can't be seen again.
+type CreatedAPIToken struct {
+	APIToken
+	Token string `json:"token"`
+}
+
+// IsValidScope will return true if scope can be granted to a token.
+func IsValidScope(scope string) bool {
+	for _, validScope := range Scopes {
+		if scope == validScope {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitScopes will return scopes stored in comma separated form.
+func SplitScopes(scopes string) []string {
+	if len(scopes) == 0 {
+		return []string{}
+	}
+	return strings.Split(scopes, ",")
+}
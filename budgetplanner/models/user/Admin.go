@@ -0,0 +1,65 @@
+package user
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+)
+
+// Roles of users in the system. Admins manage users of every tenant.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// IsValidRole returns true if role can be given to a user.
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
+}
+
+// UserRole is used by admin to change role of a user.
+type UserRole struct {
+	UserID uuid.UUID `json:"-"`
+	Role   string    `json:"role"`
+}
+
+// Validate will verify role of user.
+func (r *UserRole) Validate() error {
+	r.Role = strings.ToLower(strings.TrimSpace(r.Role))
+	if !IsValidRole(r.Role) {
+		return errors.NewValidationError("role must be either user or admin")
+	}
+	return nil
+}
+
+// UserStatusChange is used by admin to disable or enable a user.
+type UserStatusChange struct {
+	UserID uuid.UUID `json:"-"`
+	Reason string    `json:"reason"`
+}
+
+// Validate will verify fields of status change.
+func (s *UserStatusChange) Validate() error {
+	s.Reason = strings.TrimSpace(s.Reason)
+	if len(s.Reason) > 255 {
+		return errors.NewValidationError("reason must not be longer than 255 characters")
+	}
+	return nil
+}
+
+// AdminStats is the system wide overview shown to admins.
+type AdminStats struct {
+	Tenants           int64 `json:"tenants"`
+	Users             int64 `json:"users"`
+	VerifiedUsers     int64 `json:"verifiedUsers"`
+	DisabledUsers     int64 `json:"disabledUsers"`
+	TwoFactorUsers    int64 `json:"twoFactorUsers"`
+	NewUsersLastWeek  int64 `json:"newUsersLastWeek"`
+	ActiveSessions    int64 `json:"activeSessions"`
+	Budgets           int64 `json:"budgets"`
+	Envelops          int64 `json:"envelops"`
+	Accounts          int64 `json:"accounts"`
+	Transactions      int64 `json:"transactions"`
+	TransactionsToday int64 `json:"transactionsToday"`
+}
@@ -1,22 +1,46 @@
 package user
 
 import (
+	"time"
+
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 )
 
 // Authentication contains details which would to sent as response on login.
+// When two factor authentication is required only ChallengeToken is sent.
 type Authentication struct {
-	UserID       uuid.UUID `json:"userID"`
-	Name         string    `json:"name"`
-	Email        string    `json:"email"`
-	Token        string    `json:"token"`
-	IsFirstLogin bool      `json:"isFirstLogin,omitempty"`
+	UserID            uuid.UUID  `json:"userID"`
+	Name              string     `json:"name"`
+	Email             string     `json:"email"`
+	Token             string     `json:"token,omitempty"`
+	ExpiresAt         *time.Time `json:"expiresAt,omitempty"`
+	RefreshToken      string     `json:"refreshToken,omitempty"`
+	TwoFactorRequired bool       `json:"twoFactorRequired,omitempty"`
+	ChallengeToken    string     `json:"challengeToken,omitempty"`
+	SessionID         uuid.UUID  `json:"-"`
+	UserAgent         string     `json:"-"`
+	IPAddress         string     `json:"-"`
+	IsFirstLogin      bool       `json:"isFirstLogin,omitempty"`
 }
 
 // Create a struct that will be encoded to a JWT.
 // We add jwt.RegisteredClaims as an embedded type, to provide fields like expiry time
 type Claims struct {
-	UserID uuid.UUID `json:"userID"`
+	UserID    uuid.UUID `json:"userID"`
+	SessionID uuid.UUID `json:"sid,omitempty"`
+	Purpose   string    `json:"purpose,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	// Scopes are set only when request is authenticated with personal access token.
+	Scopes     []string `json:"-"`
+	IsAPIToken bool     `json:"-"`
+	// ImpersonatorID is set only for access token issued to admin impersonating the user.
+	ImpersonatorID *uuid.UUID `json:"impersonatorID,omitempty"`
 	jwt.RegisteredClaims
 }
+
+// Purposes of tokens which can not be used to access the api.
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposeTwoFactor         = "two_factor"
+)
@@ -0,0 +1,26 @@
+package user
+
+import (
+	"time"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+)
+
+// LoginAttempt keeps count of consecutive failed logins for a username or an IP address.
+type LoginAttempt struct {
+	general.Base
+	Key           string     `json:"-" gorm:"type:varchar(255);unique;index:idx_key"`
+	Failures      int        `json:"-" gorm:"type:int;default:0"`
+	LastFailureAt time.Time  `json:"-" gorm:"type:datetime;index:idx_last_failure_at"`
+	LockedUntil   *time.Time `json:"-" gorm:"type:datetime"`
+}
+
+// TableName will specify table name for login attempt struct.
+func (*LoginAttempt) TableName() string {
+	return "login_attempts"
+}
+
+// IsLocked will return true if logins are not allowed at the moment.
+func (l *LoginAttempt) IsLocked() bool {
+	return l.LockedUntil != nil && l.LockedUntil.After(time.Now())
+}
@@ -1,8 +1,10 @@
 package user
 
 import (
+	"strings"
 	"sync"
 
+	"github.com/google/uuid"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
 	"gorm.io/gorm"
 )
@@ -22,7 +24,8 @@ func NewUserModuleConfig(db *gorm.DB) *ModuleConfig {
 // TableMigration Update Table Structure with Latest Version.
 func (config *ModuleConfig) TableMigration(wg *sync.WaitGroup) {
 	var models []interface{} = []interface{}{
-		&User{},
+		&Tenant{}, &User{}, &Session{}, &RefreshToken{}, &RevokedToken{}, &PasswordReset{}, &LoginAttempt{}, &RecoveryCode{}, &APIToken{},
+		&UserIdentity{}, &OIDCState{}, &Preference{},
 	}
 
 	for _, model := range models {
@@ -34,3 +37,81 @@ func (config *ModuleConfig) TableMigration(wg *sync.WaitGroup) {
 
 	log.GetLogger().Info("User Module Configured.")
 }
+
+// DefaultTenantMigration assigns records which were created before tenants to the default tenant.
+// It must run after tables of every module are migrated.
+type DefaultTenantMigration struct {
+	db *gorm.DB
+}
+
+// NewDefaultTenantMigration Return New Default Tenant Migration.
+func NewDefaultTenantMigration(db *gorm.DB) *DefaultTenantMigration {
+	return &DefaultTenantMigration{
+		db: db,
+	}
+}
+
+// tenantTables are tables whose records belong to a tenant.
+var tenantTables = []string{"users", "budgets", "envelops", "accounts", "transactions", "reconciliations"}
+
+// TableMigration will create default tenant if it doesn't exist and assign records without tenant to it.
+func (config *DefaultTenantMigration) TableMigration(wg *sync.WaitGroup) {
+	tenant := Tenant{}
+
+	err := config.db.Where(Tenant{Slug: DefaultTenantSlug}).
+		Attrs(Tenant{Name: "Default", IsActive: true}).FirstOrCreate(&tenant).Error
+	if err != nil {
+		log.GetLogger().Errorf("Default Tenant Migration ==> %s", err.Error())
+		return
+	}
+
+	for _, table := range tenantTables {
+		err = config.db.Exec("UPDATE "+table+" SET `tenant_id` = ?"+
+			" WHERE `tenant_id` IS NULL OR `tenant_id` = '' OR `tenant_id` = ?", tenant.ID, uuid.Nil).Error
+		if err != nil {
+			log.GetLogger().Errorf("Default Tenant Migration ==> %s", err.Error())
+		}
+	}
+
+	log.GetLogger().Info("Default Tenant Migrated.")
+}
+
+// AdminMigration makes users with configured emails admin, so that the first admin
+// can be created without changing the database by hand.
+type AdminMigration struct {
+	db     *gorm.DB
+	emails []string
+}
+
+// NewAdminMigration Return New Admin Migration.
+func NewAdminMigration(db *gorm.DB, emails []string) *AdminMigration {
+	return &AdminMigration{
+		db:     db,
+		emails: emails,
+	}
+}
+
+// TableMigration will give admin role to users with configured emails.
+func (config *AdminMigration) TableMigration(wg *sync.WaitGroup) {
+	var emails []string
+
+	for _, email := range config.emails {
+		email = strings.TrimSpace(email)
+		if len(email) > 0 {
+			emails = append(emails, email)
+		}
+	}
+
+	if len(emails) == 0 {
+		return
+	}
+
+	err := config.db.Model(&User{}).Where("users.`email` IN (?) AND users.`deleted_at` IS NULL", emails).
+		UpdateColumn("role", RoleAdmin).Error
+	if err != nil {
+		log.GetLogger().Errorf("Admin Migration ==> %s", err.Error())
+		return
+	}
+
+	log.GetLogger().Info("Admins Migrated.")
+}
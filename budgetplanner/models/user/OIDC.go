@@ -0,0 +1,67 @@
+package user
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+)
+
+// UserIdentity links account of user at an OpenID Connect provider to the user.
+type UserIdentity struct {
+	general.Base
+	User     User      `json:"-" gorm:"foreignKey:UserID"` // added to create foregin key. can't create using constraint
+	UserID   uuid.UUID `json:"userID" gorm:"type:char(36);index:idx_user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Provider string    `json:"provider" gorm:"type:varchar(50);uniqueIndex:idx_provider_subject"`
+	Subject  string    `json:"-" gorm:"type:varchar(255);uniqueIndex:idx_provider_subject"`
+	Email    string    `json:"email" gorm:"type:varchar(255)"`
+}
+
+// TableName will specify table name for user identity struct.
+func (*UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// OIDCState is stored when login with provider starts and is used once when provider redirects back.
+// Nonce and code verifier are sent to provider later, so only state is hashed.
+type OIDCState struct {
+	general.Base
+	Provider     string    `json:"-" gorm:"type:varchar(50)"`
+	StateHash    string    `json:"-" gorm:"type:varchar(64);unique;index:idx_state_hash"`
+	Nonce        string    `json:"-" gorm:"type:varchar(64)"`
+	CodeVerifier string    `json:"-" gorm:"type:varchar(128)"`
+	ExpiresAt    time.Time `json:"-" gorm:"type:datetime"`
+}
+
+// TableName will specify table name for oidc state struct.
+func (*OIDCState) TableName() string {
+	return "oidc_states"
+}
+
+// OIDCAuthorization contains url to which user should be sent to login with provider.
+type OIDCAuthorization struct {
+	AuthorizationURL string `json:"authorizationURL"`
+}
+
+// OIDCCallback contains parameters with which provider redirected user back.
+type OIDCCallback struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+// Validate will verify compulsory fields of oidc callback.
+func (c *OIDCCallback) Validate() error {
+	c.Code = strings.TrimSpace(c.Code)
+	c.State = strings.TrimSpace(c.State)
+
+	if len(c.Code) == 0 {
+		return errors.NewValidationError("code must be specified")
+	}
+
+	if len(c.State) == 0 {
+		return errors.NewValidationError("state must be specified")
+	}
+	return nil
+}
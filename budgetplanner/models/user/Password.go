@@ -0,0 +1,134 @@
+package user
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+)
+
+// MaxPasswordLength is the longest password bcrypt can hash.
+const MaxPasswordLength = 72
+
+// PasswordPolicy contains rules every new password must follow.
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// Validate will verify password against the policy.
+func (p *PasswordPolicy) Validate(password string) error {
+	if len(password) < p.MinLength {
+		return errors.NewValidationError(fmt.Sprintf("password must be at least %d characters long", p.MinLength))
+	}
+
+	if len(password) > MaxPasswordLength {
+		return errors.NewValidationError(fmt.Sprintf("password must be at most %d characters long", MaxPasswordLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUpper && !hasUpper {
+		return errors.NewValidationError("password must contain an uppercase letter")
+	}
+	if p.RequireLower && !hasLower {
+		return errors.NewValidationError("password must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		return errors.NewValidationError("password must contain a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		return errors.NewValidationError("password must contain a special character")
+	}
+	return nil
+}
+
+// PasswordReset will store hash of single-use token sent for resetting password.
+type PasswordReset struct {
+	general.Base
+	User      User       `json:"-" gorm:"foreignKey:UserID"` // added to create foregin key. can't create using constraint
+	UserID    uuid.UUID  `json:"-" gorm:"type:char(36);index:idx_user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TokenHash string     `json:"-" gorm:"type:varchar(64);unique;index:idx_token_hash"`
+	ExpiresAt time.Time  `json:"-" gorm:"type:datetime;index:idx_expires_at"`
+	UsedAt    *time.Time `json:"-" gorm:"type:datetime"`
+}
+
+// TableName will specify table name for password reset struct.
+func (*PasswordReset) TableName() string {
+	return "password_resets"
+}
+
+// IsActive will return true if the token can still be used.
+func (p *PasswordReset) IsActive() bool {
+	return p.UsedAt == nil && p.ExpiresAt.After(time.Now())
+}
+
+// ForgotPassword contains email of user who forgot password.
+type ForgotPassword struct {
+	Email string `json:"email"`
+}
+
+// Validate will verify compulsory fields of forgot password.
+func (f *ForgotPassword) Validate() error {
+	f.Email = strings.TrimSpace(f.Email)
+	if len(f.Email) == 0 {
+		return errors.NewValidationError("email must be specified")
+	}
+	return nil
+}
+
+// ResetPassword contains token sent in email along with new password.
+type ResetPassword struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// Validate will verify compulsory fields of reset password.
+func (r *ResetPassword) Validate(policy *PasswordPolicy) error {
+	r.Token = strings.TrimSpace(r.Token)
+	if len(r.Token) == 0 {
+		return errors.NewValidationError("token must be specified")
+	}
+
+	r.Password = strings.TrimSpace(r.Password)
+	return policy.Validate(r.Password)
+}
+
+// ChangePassword contains current password and new password of user.
+type ChangePassword struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+// Validate will verify compulsory fields of change password.
+func (c *ChangePassword) Validate(policy *PasswordPolicy) error {
+	c.CurrentPassword = strings.TrimSpace(c.CurrentPassword)
+	if len(c.CurrentPassword) == 0 {
+		return errors.NewValidationError("current password must be specified")
+	}
+
+	c.NewPassword = strings.TrimSpace(c.NewPassword)
+	if c.NewPassword == c.CurrentPassword {
+		return errors.NewValidationError("new password must be different from current password")
+	}
+	return policy.Validate(c.NewPassword)
+}
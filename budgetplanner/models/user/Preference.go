@@ -0,0 +1,147 @@
+package user
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+)
+
+// Default preferences of users who have not set them.
+const (
+	DefaultTimezone       = "UTC"
+	DefaultLocale         = "en-US"
+	DefaultCurrency       = "USD"
+	DefaultFirstDayOfWeek = time.Monday
+	DefaultPeriodStartDay = 1
+)
+
+// maxPeriodStartDay is last day budget period can start on, so that every month has the day.
+const maxPeriodStartDay = 28
+
+var (
+	// localePattern matches BCP 47 language tags like en, en-US or zh-Hant-TW.
+	localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+	// currencyPattern matches ISO 4217 currency codes like USD or INR.
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// Preference contains settings of the user used to show and interpret their dates and amounts.
+type Preference struct {
+	general.Base
+	User           User         `json:"-" gorm:"foreignKey:UserID"` // added to create foregin key. can't create using constraint
+	UserID         uuid.UUID    `json:"userID" gorm:"type:char(36);unique;index:idx_user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Timezone       string       `json:"timezone" gorm:"type:varchar(64);not_null"`
+	Locale         string       `json:"locale" gorm:"type:varchar(35);not_null"`
+	Currency       string       `json:"currency" gorm:"type:char(3);not_null"`
+	FirstDayOfWeek time.Weekday `json:"firstDayOfWeek" gorm:"type:tinyint;not_null"`
+	PeriodStartDay int          `json:"periodStartDay" gorm:"type:tinyint;not_null"`
+}
+
+// TableName will specify table name for preference struct.
+func (*Preference) TableName() string {
+	return "user_preferences"
+}
+
+// DefaultPreference returns preferences used for the user until they set their own.
+func DefaultPreference(userID uuid.UUID) *Preference {
+	return &Preference{
+		UserID:         userID,
+		Timezone:       DefaultTimezone,
+		Locale:         DefaultLocale,
+		Currency:       DefaultCurrency,
+		FirstDayOfWeek: DefaultFirstDayOfWeek,
+		PeriodStartDay: DefaultPeriodStartDay,
+	}
+}
+
+// Validate will verify preferences of the user. Empty fields are set to their default.
+func (p *Preference) Validate() error {
+	p.Timezone = strings.TrimSpace(p.Timezone)
+	if len(p.Timezone) == 0 {
+		p.Timezone = DefaultTimezone
+	}
+
+	_, err := time.LoadLocation(p.Timezone)
+	if err != nil || p.Timezone == "Local" {
+		return errors.NewValidationError("timezone must be a valid IANA timezone like Europe/London")
+	}
+
+	p.Locale = strings.TrimSpace(p.Locale)
+	if len(p.Locale) == 0 {
+		p.Locale = DefaultLocale
+	}
+
+	if len(p.Locale) > 35 || !localePattern.MatchString(p.Locale) {
+		return errors.NewValidationError("locale must be a valid language tag like en-US")
+	}
+
+	p.Currency = strings.ToUpper(strings.TrimSpace(p.Currency))
+	if len(p.Currency) == 0 {
+		p.Currency = DefaultCurrency
+	}
+
+	if !currencyPattern.MatchString(p.Currency) {
+		return errors.NewValidationError("currency must be a three letter ISO 4217 code like USD")
+	}
+
+	if p.FirstDayOfWeek < time.Sunday || p.FirstDayOfWeek > time.Saturday {
+		return errors.NewValidationError("first day of week must be between 0 (sunday) and 6 (saturday)")
+	}
+
+	if p.PeriodStartDay == 0 {
+		p.PeriodStartDay = DefaultPeriodStartDay
+	}
+
+	if p.PeriodStartDay < 1 || p.PeriodStartDay > maxPeriodStartDay {
+		return errors.NewValidationError("period start day must be between 1 and 28")
+	}
+
+	return nil
+}
+
+// Location returns timezone of the user, UTC is returned when timezone is not valid.
+func (p *Preference) Location() *time.Location {
+	location, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// StartOfDay returns midnight of the day of t in timezone of the user.
+func (p *Preference) StartOfDay(t time.Time) time.Time {
+	t = t.In(p.Location())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// StartOfPeriod returns start of the budget period containing t. Budget periods are a month long
+// and start on period start day of the user.
+func (p *Preference) StartOfPeriod(t time.Time) time.Time {
+	day := p.StartOfDay(t)
+	start := time.Date(day.Year(), day.Month(), p.PeriodStartDay, 0, 0, 0, 0, day.Location())
+	if start.After(day) {
+		start = start.AddDate(0, -1, 0)
+	}
+	return start
+}
+
+// Period returns start and exclusive end of the budget period containing t.
+func (p *Preference) Period(t time.Time) (time.Time, time.Time) {
+	start := p.StartOfPeriod(t)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// ParseDate will parse date in any format accepted by general.ParseDate, in timezone of the user.
+func (p *Preference) ParseDate(value string) (general.Date, error) {
+	date, err := general.ParseDate(value)
+	if err != nil {
+		return date, err
+	}
+
+	date.Localize(p.Location())
+	return date, nil
+}
@@ -0,0 +1,69 @@
+package user
+
+import (
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+)
+
+// Variants of profile image. Original is kept as uploaded, rest are square JPEG thumbnails.
+const (
+	ProfileImageOriginal = "original"
+	ProfileImageMedium   = "medium"
+	ProfileImageSmall    = "small"
+)
+
+// ProfileImageSizes contains width of every thumbnail variant of profile image.
+var ProfileImageSizes = map[string]int{
+	ProfileImageMedium: 256,
+	ProfileImageSmall:  64,
+}
+
+// DefaultProfileImageMaxBytes is maximum size of uploaded profile image when PROFILE_IMAGE_MAX_BYTES is not set.
+const DefaultProfileImageMaxBytes = 5 << 20
+
+// ProfileImageKey will return storage key of variant of profile image stored under folder.
+func ProfileImageKey(folder, variant string) string {
+	return folder + "/" + variant
+}
+
+// ProfileImageKeys will return storage keys of every variant of profile image of the user.
+func (u *User) ProfileImageKeys() []string {
+	if u.ProfileImageKey == nil {
+		return nil
+	}
+
+	keys := []string{ProfileImageKey(*u.ProfileImageKey, ProfileImageOriginal)}
+	for variant := range ProfileImageSizes {
+		keys = append(keys, ProfileImageKey(*u.ProfileImageKey, variant))
+	}
+	return keys
+}
+
+// ProfileImageUpload contains image uploaded by user.
+type ProfileImageUpload struct {
+	UserID uuid.UUID
+	Data   []byte
+}
+
+// ProfileImageFile contains variant of profile image being downloaded.
+// Content must be closed after it is read.
+type ProfileImageFile struct {
+	UserID      uuid.UUID
+	Variant     string
+	ContentType string
+	Content     io.ReadCloser
+}
+
+// Validate will verify variant of profile image, medium thumbnail is used when it is not specified.
+func (f *ProfileImageFile) Validate() error {
+	if len(f.Variant) == 0 {
+		f.Variant = ProfileImageMedium
+	}
+
+	if _, ok := ProfileImageSizes[f.Variant]; !ok && f.Variant != ProfileImageOriginal {
+		return errors.NewValidationError("size must be original, medium or small")
+	}
+	return nil
+}
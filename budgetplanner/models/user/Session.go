@@ -0,0 +1,44 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+)
+
+// MaxUserAgentLength is length of user agent stored for session, longer user agents are truncated.
+const MaxUserAgentLength = 255
+
+// Session is created for every login and lives as long as its refresh tokens are rotated.
+type Session struct {
+	general.Base
+	User       User       `json:"-" gorm:"foreignKey:UserID"` // added to create foregin key. can't create using constraint
+	UserID     uuid.UUID  `json:"userID" gorm:"type:char(36);index:idx_user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserAgent  string     `json:"userAgent" gorm:"type:varchar(255)"`
+	IPAddress  string     `json:"ipAddress" gorm:"type:varchar(45)"`
+	LastSeenAt time.Time  `json:"lastSeenAt" gorm:"type:datetime"`
+	ExpiresAt  time.Time  `json:"expiresAt" gorm:"type:datetime;index:idx_expires_at"`
+	RevokedAt  *time.Time `json:"revokedAt" gorm:"type:datetime"`
+}
+
+// TableName will specify table name for session struct.
+func (*Session) TableName() string {
+	return "sessions"
+}
+
+// SessionDTO contains fields for DTO specifically.
+type SessionDTO struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"userAgent"`
+	IPAddress  string    `json:"ipAddress"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	IsCurrent  bool      `json:"isCurrent" gorm:"-"`
+}
+
+// TableName will specify table name for session struct.
+func (*SessionDTO) TableName() string {
+	return "sessions"
+}
@@ -0,0 +1,18 @@
+package user
+
+// JSONWebKey is public key used to verify signature of tokens, as per RFC 7517.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JSONWebKeySet contains every key which can be used to verify tokens.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
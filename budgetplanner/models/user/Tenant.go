@@ -0,0 +1,79 @@
+package user
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+)
+
+// DefaultTenantSlug is slug of tenant which users registering on their own belong to.
+const DefaultTenantSlug = "default"
+
+// slugPattern allows lower case letters, digits and hyphens in slug of tenant.
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Tenant is an organization which has its own users and their data. Users and data
+// of one tenant are never visible to users of another tenant.
+type Tenant struct {
+	general.Base
+	Name     string `json:"name" gorm:"type:varchar(100);not_null"`
+	Slug     string `json:"slug" gorm:"type:varchar(100);unique;index:idx_slug"`
+	IsActive bool   `json:"isActive" gorm:"type:tinyint;default:1"`
+}
+
+// TableName will specify table name for tenant struct.
+func (*Tenant) TableName() string {
+	return "tenants"
+}
+
+// Validate will verify compulsory fields of tenant.
+func (t *Tenant) Validate() error {
+	t.Name = strings.TrimSpace(t.Name)
+	if len(t.Name) == 0 {
+		return errors.NewValidationError("tenant name must be specified")
+	}
+
+	t.Slug = strings.ToLower(strings.TrimSpace(t.Slug))
+	if !slugPattern.MatchString(t.Slug) {
+		return errors.NewValidationError("slug must contain only lower case letters, digits and hyphens")
+	}
+	return nil
+}
+
+// TenantDTO contains fields for DTO specifically.
+type TenantDTO struct {
+	general.BaseDTO
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	IsActive bool   `json:"isActive"`
+}
+
+// TableName will specify table name for tenant struct.
+func (*TenantDTO) TableName() string {
+	return "tenants"
+}
+
+// TenantRegistration contains details of organization signing up along with its first admin.
+type TenantRegistration struct {
+	Tenant Tenant `json:"tenant"`
+	Admin  User   `json:"admin"`
+}
+
+// Validate will verify tenant and its admin.
+func (r *TenantRegistration) Validate(policy *PasswordPolicy) error {
+	err := r.Tenant.Validate()
+	if err != nil {
+		return err
+	}
+	return r.Admin.ValidateRegistration(policy)
+}
+
+// TenantUserRole is used by tenant admin to change role of user of the tenant.
+type TenantUserRole struct {
+	TenantID      uuid.UUID `json:"-"`
+	UserID        uuid.UUID `json:"-"`
+	IsTenantAdmin bool      `json:"isTenantAdmin"`
+}
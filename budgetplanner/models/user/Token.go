@@ -0,0 +1,61 @@
+package user
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+)
+
+// RefreshToken will store hash of refresh token issued for a session.
+// Refresh tokens are rotated on every use, all tokens of a session share same SessionID.
+type RefreshToken struct {
+	general.Base
+	User      User       `json:"-" gorm:"foreignKey:UserID"` // added to create foregin key. can't create using constraint
+	Session   Session    `json:"-" gorm:"foreignKey:SessionID"`
+	UserID    uuid.UUID  `json:"-" gorm:"type:char(36);index:idx_user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	SessionID uuid.UUID  `json:"-" gorm:"type:char(36);index:idx_session_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TokenHash string     `json:"-" gorm:"type:varchar(64);unique;index:idx_token_hash"`
+	ExpiresAt time.Time  `json:"-" gorm:"type:datetime;index:idx_expires_at"`
+	UsedAt    *time.Time `json:"-" gorm:"type:datetime"`
+	RevokedAt *time.Time `json:"-" gorm:"type:datetime"`
+}
+
+// TableName will specify table name for refresh token struct.
+func (*RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// IsActive will return true if refresh token can be exchanged for new tokens.
+func (r *RefreshToken) IsActive() bool {
+	return r.RevokedAt == nil && r.UsedAt == nil && r.ExpiresAt.After(time.Now())
+}
+
+// RevokedToken is an entry of revocation list checked for every access token.
+// TokenID is either ID of an access token or ID of a session.
+type RevokedToken struct {
+	general.Base
+	TokenID   uuid.UUID `json:"-" gorm:"type:char(36);index:idx_token_id"`
+	ExpiresAt time.Time `json:"-" gorm:"type:datetime;index:idx_expires_at"`
+}
+
+// TableName will specify table name for revoked token struct.
+func (*RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
+
+// Refresh contains refresh token to be exchanged for new tokens.
+type Refresh struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// Validate will verify compulsory fields of refresh.
+func (r *Refresh) Validate() error {
+	r.RefreshToken = strings.TrimSpace(r.RefreshToken)
+	if len(r.RefreshToken) == 0 {
+		return errors.NewValidationError("refresh token must be specified")
+	}
+	return nil
+}
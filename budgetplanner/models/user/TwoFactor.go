@@ -0,0 +1,67 @@
+package user
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+)
+
+// RecoveryCodeCount is number of recovery codes generated for user.
+const RecoveryCodeCount = 10
+
+// RecoveryCode will store hash of one-time code which can be used instead of TOTP code.
+type RecoveryCode struct {
+	general.Base
+	User     User       `json:"-" gorm:"foreignKey:UserID"` // added to create foregin key. can't create using constraint
+	UserID   uuid.UUID  `json:"-" gorm:"type:char(36);index:idx_user_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CodeHash string     `json:"-" gorm:"type:varchar(64);index:idx_code_hash"`
+	UsedAt   *time.Time `json:"-" gorm:"type:datetime"`
+}
+
+// TableName will specify table name for recovery code struct.
+func (*RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
+
+// TwoFactorEnrollment contains secret to be added to authenticator app.
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// RecoveryCodes contains recovery codes, these are shown to user only once.
+type RecoveryCodes struct {
+	Codes []string `json:"codes"`
+}
+
+// TwoFactorCode contains code from authenticator app or a recovery code.
+type TwoFactorCode struct {
+	Code string `json:"code"`
+}
+
+// Validate will verify compulsory fields of two factor code.
+func (t *TwoFactorCode) Validate() error {
+	t.Code = strings.TrimSpace(t.Code)
+	if len(t.Code) == 0 {
+		return errors.NewValidationError("code must be specified")
+	}
+	return nil
+}
+
+// TwoFactorLogin contains challenge token received on login along with code.
+type TwoFactorLogin struct {
+	ChallengeToken string `json:"challengeToken"`
+	TwoFactorCode
+}
+
+// Validate will verify compulsory fields of two factor login.
+func (t *TwoFactorLogin) Validate() error {
+	t.ChallengeToken = strings.TrimSpace(t.ChallengeToken)
+	if len(t.ChallengeToken) == 0 {
+		return errors.NewValidationError("challenge token must be specified")
+	}
+	return t.TwoFactorCode.Validate()
+}
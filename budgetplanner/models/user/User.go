@@ -2,23 +2,40 @@ package user
 
 import (
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/encryption"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
 )
 
+// maxContactLength is maximum length of contact of user. Column is wider as contact is stored encrypted.
+const maxContactLength = 15
+
 // User will store all the information required of a user.
 type User struct {
 	general.Base
-	Name         string  `json:"name" gorm:"type:varchar(100)"`
-	Username     string  `json:"username" gorm:"type:varchar(200);unique;index:idx_username"`
-	Email        string  `json:"email" gorm:"type:varchar(255);unique;index:idx_email"`
-	Password     string  `json:"password" gorm:"type:varchar(255);index:idx_password"`
-	DateOfBirth  *string `json:"dateOfBirth" gorm:"type:varchar(10)"`
-	Gender       *string `json:"gender" gorm:"type:varchar(20)"`
-	Contact      *string `json:"contact" gorm:"type:varchar(15)"`
-	ProfileImage *string `json:"profileImage" gorm:"type:varchar(255)"`
-	IsVerified   bool    `json:"isVerified" gorm:"type:tinyint;default:0"`
+	Name               string             `json:"name" gorm:"type:varchar(100)"`
+	Username           string             `json:"username" gorm:"type:varchar(200);unique;index:idx_username"`
+	Email              string             `json:"email" gorm:"type:varchar(255);unique;index:idx_email"`
+	Password           string             `json:"password" gorm:"type:varchar(255);index:idx_password"`
+	DateOfBirth        *encryption.String `json:"dateOfBirth" gorm:"type:varchar(255)"`
+	Gender             *string            `json:"gender" gorm:"type:varchar(20)"`
+	Contact            *encryption.String `json:"contact" gorm:"type:varchar(255)"`
+	ProfileImage       *string            `json:"profileImage" gorm:"type:varchar(255)"`
+	ProfileImageKey    *string            `json:"-" gorm:"type:varchar(255)"`
+	ProfileImageType   *string            `json:"-" gorm:"type:varchar(50)"`
+	IsVerified         bool               `json:"isVerified" gorm:"type:tinyint;default:0"`
+	VerificationSentAt *time.Time         `json:"-" gorm:"type:datetime"`
+	TOTPSecret         *encryption.String `json:"-" gorm:"type:varchar(255)"`
+	TOTPLastCounter    int64              `json:"-" gorm:"type:bigint;default:0"`
+	IsTwoFactorEnabled bool               `json:"isTwoFactorEnabled" gorm:"type:tinyint;default:0"`
+	Tenant             Tenant             `json:"-" gorm:"foreignKey:TenantID"`
+	TenantID           uuid.UUID          `json:"-" gorm:"type:char(36);index:idx_tenant_id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	IsTenantAdmin      bool               `json:"-" gorm:"type:tinyint;default:0"`
+	Role               string             `json:"-" gorm:"type:varchar(20);default:user"`
+	DisabledAt         *time.Time         `json:"-" gorm:"type:datetime"`
 }
 
 // TableName will specify table name for user struct.
@@ -29,15 +46,20 @@ func (*User) TableName() string {
 // UserDTO contains fields for DTO specifically.
 type UserDTO struct {
 	general.BaseDTO
-	Name         string  `json:"name"`
-	Username     string  `json:"username"`
-	Email        string  `json:"email"`
-	Password     string  `json:"-"`
-	DateOfBirth  *string `json:"dateOfBirth"`
-	Gender       *string `json:"gender"`
-	Contact      *string `json:"contact"`
-	ProfileImage *string `json:"profileImage"`
-	IsVerified   bool    `json:"isVerified"`
+	Name               string             `json:"name"`
+	Username           string             `json:"username"`
+	Email              string             `json:"email"`
+	Password           string             `json:"-"`
+	DateOfBirth        *encryption.String `json:"dateOfBirth"`
+	Gender             *string            `json:"gender"`
+	Contact            *encryption.String `json:"contact"`
+	ProfileImage       *string            `json:"profileImage"`
+	IsVerified         bool               `json:"isVerified"`
+	IsTwoFactorEnabled bool               `json:"isTwoFactorEnabled"`
+	TenantID           uuid.UUID          `json:"tenantID"`
+	IsTenantAdmin      bool               `json:"isTenantAdmin"`
+	Role               string             `json:"role"`
+	DisabledAt         *time.Time         `json:"disabledAt"`
 }
 
 // TableName will specify table name for user struct.
@@ -45,8 +67,8 @@ func (*UserDTO) TableName() string {
 	return "users"
 }
 
-// ValidateRegistration will verify compulsory fields of user.
-func (u *User) ValidateRegistration() error {
+// ValidateRegistration will verify compulsory fields of user and strength of password.
+func (u *User) ValidateRegistration(policy *PasswordPolicy) error {
 	if len(strings.TrimSpace(u.Name)) == 0 {
 		return errors.NewValidationError("name must be specified")
 	}
@@ -70,10 +92,21 @@ func (u *User) ValidateRegistration() error {
 	}
 
 	u.Password = strings.TrimSpace(u.Password)
+	err := policy.Validate(u.Password)
+	if err != nil {
+		return err
+	}
+
 	if u.Contact != nil {
-		*u.Contact = strings.TrimSpace(*u.Contact)
+		*u.Contact = encryption.String(strings.TrimSpace(string(*u.Contact)))
+		if len(*u.Contact) > maxContactLength {
+			return errors.NewValidationError("contact must not be longer than 15 characters")
+		}
 	}
 
+	// profile image can only be set by uploading it.
+	u.ProfileImage = nil
+
 	return nil
 }
 
@@ -98,7 +131,10 @@ func (u *User) ValidateUser() error {
 	u.Email = strings.TrimSpace(u.Email)
 
 	if u.Contact != nil {
-		*u.Contact = strings.TrimSpace(*u.Contact)
+		*u.Contact = encryption.String(strings.TrimSpace(string(*u.Contact)))
+		if len(*u.Contact) > maxContactLength {
+			return errors.NewValidationError("contact must not be longer than 15 characters")
+		}
 	}
 
 	return nil
@@ -0,0 +1,35 @@
+package user
+
+import (
+	"strings"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+)
+
+// EmailVerification contains token sent to user for verifying email.
+type EmailVerification struct {
+	Token string `json:"token"`
+}
+
+// Validate will verify compulsory fields of email verification.
+func (e *EmailVerification) Validate() error {
+	e.Token = strings.TrimSpace(e.Token)
+	if len(e.Token) == 0 {
+		return errors.NewValidationError("token must be specified")
+	}
+	return nil
+}
+
+// VerificationRequest contains email to which verification email should be resent.
+type VerificationRequest struct {
+	Email string `json:"email"`
+}
+
+// Validate will verify compulsory fields of verification request.
+func (v *VerificationRequest) Validate() error {
+	v.Email = strings.TrimSpace(v.Email)
+	if len(v.Email) == 0 {
+		return errors.NewValidationError("email must be specified")
+	}
+	return nil
+}
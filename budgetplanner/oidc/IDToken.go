@@ -0,0 +1,241 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+)
+
+// keysRefreshInterval is minimum time between two fetches of keys of provider,
+// keys are fetched again only when token is signed with unknown kid.
+const keysRefreshInterval = time.Minute
+
+// IDTokenClaims are claims of id token which are used to identify user.
+type IDTokenClaims struct {
+	Nonce             string      `json:"nonce"`
+	Email             string      `json:"email"`
+	EmailVerified     interface{} `json:"email_verified"`
+	Name              string      `json:"name"`
+	PreferredUsername string      `json:"preferred_username"`
+	AuthorizedParty   string      `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+// IsEmailVerified will return true if provider has verified email of user.
+// Some providers send email_verified as string.
+func (c *IDTokenClaims) IsEmailVerified() bool {
+	switch verified := c.EmailVerified.(type) {
+	case bool:
+		return verified
+	case string:
+		return verified == "true"
+	}
+	return false
+}
+
+// verifyIDToken will verify signature, issuer, audience, expiry and nonce of id token.
+func (p *Provider) verifyIDToken(ctx context.Context, idToken, nonce string) (*IDTokenClaims, error) {
+	claims := IDTokenClaims{}
+
+	_, err := jwt.ParseWithClaims(idToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		return p.verificationKey(ctx, token)
+	})
+	if err != nil {
+		log.GetLogger().Errorf("id token of OIDC provider %s is invalid: %s", p.Name, err.Error())
+		return nil, errors.NewValidationError("Login with provider failed")
+	}
+
+	if claims.Issuer != p.Issuer && claims.Issuer != p.Issuer+"/" {
+		return nil, errors.NewValidationError("Login with provider failed")
+	}
+
+	if !claims.VerifyAudience(p.ClientID, true) {
+		return nil, errors.NewValidationError("Login with provider failed")
+	}
+
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.ClientID {
+		return nil, errors.NewValidationError("Login with provider failed")
+	}
+
+	if !claims.VerifyExpiresAt(time.Now(), true) {
+		return nil, errors.NewValidationError("Login with provider failed")
+	}
+
+	if claims.Nonce != nonce || len(claims.Subject) == 0 {
+		return nil, errors.NewValidationError("Login with provider failed")
+	}
+	return &claims, nil
+}
+
+// verificationKey will return key of provider with which token is signed. HMAC and none are rejected
+// as only asymmetric signatures prove that token is issued by provider.
+func (p *Provider) verificationKey(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA, *jwt.SigningMethodEd25519:
+	default:
+		return nil, errors.NewValidationError("unsupported signing method")
+	}
+
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := p.getKey(kid)
+	if !ok && p.canRefreshKeys() {
+		err := p.fetchKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		key, ok = p.getKey(kid)
+	}
+
+	if !ok {
+		return nil, errors.NewValidationError("unknown signing key")
+	}
+	return key, nil
+}
+
+// getKey will return key with kid. When token has no kid, key is used only if provider has single key.
+func (p *Provider) getKey(kid string) (interface{}, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if len(kid) == 0 && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// canRefreshKeys will return true if keys were not fetched recently.
+func (p *Provider) canRefreshKeys() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return time.Since(p.keysAt) > keysRefreshInterval
+}
+
+// fetchKeys will fetch JWKS of provider. Keys of unsupported types are ignored.
+func (p *Provider) fetchKeys(ctx context.Context) error {
+	endpoints, err := p.getDiscovery(ctx)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoints.JWKSURI, nil)
+	if err != nil {
+		return err
+	}
+
+	keySet := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+
+	err = p.do(request, &keySet)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]interface{}, len(keySet.Keys))
+	for _, jwk := range keySet.Keys {
+		if len(jwk.Use) > 0 && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			log.GetLogger().Warnf("key %s of OIDC provider %s is ignored: %s", jwk.KeyID, p.Name, err.Error())
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+
+	p.mutex.Lock()
+	p.keys = keys
+	p.keysAt = time.Now()
+	p.mutex.Unlock()
+	return nil
+}
+
+// jsonWebKey is public key of provider as per RFC 7517.
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	Curve   string `json:"crv"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// publicKey will decode RSA, EC or Ed25519 public key.
+func (k *jsonWebKey) publicKey() (interface{}, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.NewValidationError("unsupported curve " + k.Curve)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.NewValidationError("point is not on curve")
+		}
+		return key, nil
+
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, errors.NewValidationError("unsupported curve " + k.Curve)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.NewValidationError("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, errors.NewValidationError("unsupported key type " + k.KeyType)
+}
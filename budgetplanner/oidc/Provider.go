@@ -0,0 +1,248 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+)
+
+// defaultScopes are requested when scopes of provider are not configured.
+const defaultScopes = "openid email profile"
+
+// httpTimeout is timeout of every request made to provider.
+const httpTimeout = 10 * time.Second
+
+// Provider is an OpenID Connect identity provider with which users can login.
+// Provider is configured with OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID, OIDC_<NAME>_CLIENT_SECRET,
+// OIDC_<NAME>_REDIRECT_URL and optional OIDC_<NAME>_SCOPES. Issuer can be a http url,
+// so that login can be tried against a local mock OIDC server.
+type Provider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       string
+
+	client *http.Client
+
+	mutex     sync.RWMutex
+	discovery *discovery
+	keys      map[string]interface{}
+	keysAt    time.Time
+}
+
+// discovery contains endpoints of provider from its openid-configuration.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Registry contains every configured provider by name.
+type Registry struct {
+	providers map[string]*Provider
+}
+
+// NewRegistry will create providers listed in OIDC_PROVIDERS as comma separated names.
+// Providers which are not configured completely are skipped.
+func NewRegistry(conf config.ConfReader) *Registry {
+	registry := Registry{
+		providers: map[string]*Provider{},
+	}
+
+	for _, name := range strings.Split(conf.GetString(config.OIDCProviders), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if len(name) == 0 {
+			continue
+		}
+
+		key := func(field string) config.EnvKey {
+			return config.EnvKey(fmt.Sprintf("OIDC_%s_%s", strings.ToUpper(name), field))
+		}
+
+		provider := Provider{
+			Name:         name,
+			Issuer:       strings.TrimRight(conf.GetString(key("ISSUER")), "/"),
+			ClientID:     conf.GetString(key("CLIENT_ID")),
+			ClientSecret: conf.GetString(key("CLIENT_SECRET")),
+			RedirectURL:  conf.GetString(key("REDIRECT_URL")),
+			Scopes:       defaultScopes,
+			client:       &http.Client{Timeout: httpTimeout},
+		}
+
+		if conf.IsSet(key("SCOPES")) {
+			provider.Scopes = conf.GetString(key("SCOPES"))
+		}
+
+		if len(provider.Issuer) == 0 || len(provider.ClientID) == 0 || len(provider.RedirectURL) == 0 {
+			log.GetLogger().Warnf("OIDC provider %s is not configured completely, it is skipped", name)
+			continue
+		}
+
+		registry.providers[name] = &provider
+	}
+	return &registry
+}
+
+// Get will return provider with specified name.
+func (r *Registry) Get(name string) (*Provider, error) {
+	provider, ok := r.providers[strings.ToLower(name)]
+	if !ok {
+		return nil, errors.NewValidationError("Login provider not found")
+	}
+	return provider, nil
+}
+
+// Names will return names of every configured provider.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AuthorizationURL will return url to which user is sent to login with provider.
+// PKCE code challenge is derived from codeVerifier using S256 method.
+func (p *Provider) AuthorizationURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	endpoints, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	values := url.Values{}
+	values.Set("response_type", "code")
+	values.Set("client_id", p.ClientID)
+	values.Set("redirect_uri", p.RedirectURL)
+	values.Set("scope", p.Scopes)
+	values.Set("state", state)
+	values.Set("nonce", nonce)
+	values.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	values.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(endpoints.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return endpoints.AuthorizationEndpoint + separator + values.Encode(), nil
+}
+
+// Exchange will exchange authorization code for tokens and return verified claims of id token.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*IDTokenClaims, error) {
+	endpoints, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	values.Set("grant_type", "authorization_code")
+	values.Set("code", code)
+	values.Set("redirect_uri", p.RedirectURL)
+	values.Set("client_id", p.ClientID)
+	values.Set("code_verifier", codeVerifier)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoints.TokenEndpoint,
+		strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	if len(p.ClientSecret) > 0 {
+		request.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+
+	tokens := struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}{}
+
+	err = p.do(request, &tokens)
+	if err != nil {
+		if len(tokens.Error) > 0 {
+			log.GetLogger().Errorf("OIDC provider %s rejected code: %s", p.Name, tokens.Error)
+			return nil, errors.NewValidationError("Login with provider failed")
+		}
+		return nil, err
+	}
+
+	if len(tokens.IDToken) == 0 {
+		return nil, errors.NewValidationError("Login with provider failed")
+	}
+
+	return p.verifyIDToken(ctx, tokens.IDToken, nonce)
+}
+
+// getDiscovery will fetch openid-configuration of provider once.
+func (p *Provider) getDiscovery(ctx context.Context) (*discovery, error) {
+	p.mutex.RLock()
+	endpoints := p.discovery
+	p.mutex.RUnlock()
+
+	if endpoints != nil {
+		return endpoints, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints = &discovery{}
+
+	err = p.do(request, endpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimRight(endpoints.Issuer, "/") != p.Issuer {
+		return nil, fmt.Errorf("issuer %s of provider %s does not match %s", endpoints.Issuer, p.Name, p.Issuer)
+	}
+
+	if len(endpoints.AuthorizationEndpoint) == 0 || len(endpoints.TokenEndpoint) == 0 || len(endpoints.JWKSURI) == 0 {
+		return nil, fmt.Errorf("openid-configuration of provider %s is incomplete", p.Name)
+	}
+
+	p.mutex.Lock()
+	p.discovery = endpoints
+	p.mutex.Unlock()
+	return endpoints, nil
+}
+
+// do will send request to provider and decode its json response into out.
+func (p *Provider) do(request *http.Request, out interface{}) error {
+	response, err := p.client.Do(request)
+	if err != nil {
+		return errors.NewUnexpectedError(errors.ErrorCodeAPICallFailure, err)
+	}
+	defer response.Body.Close()
+
+	err = json.NewDecoder(response.Body).Decode(out)
+	if err != nil {
+		return errors.NewUnexpectedError(errors.ErrorCodeAPICallFailure, err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return errors.NewUnexpectedError(errors.ErrorCodeAPICallFailure,
+			fmt.Errorf("%s responded with status %d", request.URL.String(), response.StatusCode))
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	privacyModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/privacy"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/privacy/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// AccountDeletionController provides methods for users to delete their account.
+type AccountDeletionController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	requestDeletion(ctx *gin.Context)
+	getDeletion(ctx *gin.Context)
+	cancelDeletion(ctx *gin.Context)
+}
+
+// accountDeletionController.
+type accountDeletionController struct {
+	service service.AccountDeletionService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewAccountDeletionController create new AccountDeletionController
+func NewAccountDeletionController(ser service.AccountDeletionService, log log.Logger,
+	auth *security.Authentication) AccountDeletionController {
+	return &accountDeletionController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for account deletion controller.
+func (c *accountDeletionController) RegisterRoutes(router *gin.RouterGroup) {
+	guarded := router.Group("/users", c.auth.Middleware(), c.auth.AuthorizeUser(), c.auth.RejectImpersonation())
+	guarded.POST("/:userID/deletion", c.requestDeletion)
+	guarded.GET("/:userID/deletion", c.getDeletion)
+	guarded.DELETE("/:userID/deletion", c.cancelDeletion)
+}
+
+// requestDeletion will schedule deletion of the account after verifying password of the user.
+func (c *accountDeletionController) requestDeletion(ctx *gin.Context) {
+	request := privacyModel.AccountDeletionRequest{}
+	deletion := privacyModel.AccountDeletion{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &request)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	request.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = request.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.RequestDeletion(ctx.Request.Context(), &request, &deletion)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusCreated, deletion)
+}
+
+// getDeletion will fetch pending deletion of the account.
+func (c *accountDeletionController) getDeletion(ctx *gin.Context) {
+	deletion := privacyModel.AccountDeletion{}
+	parser := web.NewParser(ctx)
+	var err error
+
+	deletion.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetDeletion(ctx.Request.Context(), &deletion)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, deletion)
+}
+
+// cancelDeletion will cancel pending deletion of the account.
+func (c *accountDeletionController) cancelDeletion(ctx *gin.Context) {
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.CancelDeletion(ctx.Request.Context(), userID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
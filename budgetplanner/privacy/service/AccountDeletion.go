@@ -0,0 +1,541 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/email"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	accountModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/account"
+	auditModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/audit"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
+	envelopModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/envelop"
+	privacyModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/privacy"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/storage"
+	"gorm.io/gorm"
+)
+
+// defaultDeletionGraceDays is used when ACCOUNT_DELETION_GRACE_DAYS is not set.
+const defaultDeletionGraceDays = 30
+
+// AccountDeletionService consist of all methods AccountDeletionService should implement.
+type AccountDeletionService interface {
+	RequestDeletion(ctx context.Context, request *privacyModel.AccountDeletionRequest,
+		deletion *privacyModel.AccountDeletion) error
+	GetDeletion(ctx context.Context, deletion *privacyModel.AccountDeletion) error
+	CancelDeletion(ctx context.Context, userID uuid.UUID) error
+	EraseDue() error
+}
+
+// accountDeletionService provides methods to delete account of user along with all their data.
+type accountDeletionService struct {
+	db     *gorm.DB
+	repo   repository.Repository
+	conf   config.ConfReader
+	sender email.Sender
+	store  storage.Storage
+}
+
+// NewAccountDeletionService returns new instance of AccountDeletionService.
+func NewAccountDeletionService(db *gorm.DB, repo repository.Repository, conf config.ConfReader,
+	sender email.Sender, store storage.Storage) AccountDeletionService {
+	return &accountDeletionService{
+		db:     db,
+		repo:   repo,
+		conf:   conf,
+		sender: sender,
+		store:  store,
+	}
+}
+
+// RequestDeletion will schedule erasure of account after verifying credentials of the user.
+// Account is erased once the grace period is over unless deletion is cancelled.
+func (ser *accountDeletionService) RequestDeletion(ctx context.Context, request *privacyModel.AccountDeletionRequest,
+	deletion *privacyModel.AccountDeletion) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user := userModel.User{}
+
+	err := ser.repo.GetRecord(uow, &user, repository.Filter("users.`id` = ? AND users.`deleted_at` IS NULL",
+		request.UserID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.reauthenticate(uow, &user, request)
+	if err != nil {
+		return err
+	}
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), privacyModel.AccountDeletion{},
+		repository.Filter("account_deletions.`user_id` = ? AND account_deletions.`cancelled_at` IS NULL"+
+			" AND account_deletions.`erased_at` IS NULL", user.ID))
+	if err != nil {
+		return err
+	}
+	if exist {
+		return errors.NewValidationError("Deletion of your account is already scheduled")
+	}
+
+	deletion.UserID = user.ID
+	deletion.WithExport = request.Export
+	deletion.ScheduledAt = time.Now().AddDate(0, 0, ser.graceDays())
+
+	err = ser.repo.Add(uow, deletion)
+	if err != nil {
+		return err
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		return err
+	}
+
+	err = ser.sender.Send(&email.Message{
+		To:      []string{user.Email},
+		Subject: "Your account is scheduled for deletion",
+		Body: fmt.Sprintf("Hi %s,\n\nYour account and all your data will be permanently deleted on %s.\n\n"+
+			"If you change your mind, login and cancel the deletion before then. If you did not ask to delete"+
+			" your account, cancel the deletion and change your password immediately.",
+			user.Name, deletion.ScheduledAt.Format("2 January 2006")),
+	})
+	if err != nil {
+		log.GetLogger().Error(err)
+	}
+	return nil
+}
+
+// GetDeletion will fetch pending deletion of the account.
+func (ser *accountDeletionService) GetDeletion(ctx context.Context, deletion *privacyModel.AccountDeletion) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err := ser.repo.GetRecord(uow, deletion, repository.Filter("account_deletions.`user_id` = ?"+
+		" AND account_deletions.`cancelled_at` IS NULL AND account_deletions.`erased_at` IS NULL", deletion.UserID))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewValidationError("Deletion of your account is not scheduled")
+		}
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// CancelDeletion will cancel pending deletion of the account.
+func (ser *accountDeletionService) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+
+	deletion := privacyModel.AccountDeletion{UserID: userID}
+
+	err := ser.GetDeletion(ctx, &deletion)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.UpdateWithMap(uow, &privacyModel.AccountDeletion{}, map[string]interface{}{
+		"CancelledAt": time.Now(),
+	}, repository.Filter("account_deletions.`id` = ?", deletion.ID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// EraseDue will erase accounts whose grace period is over. It is run periodically by the erasure job.
+// Failure to erase one account does not stop erasure of others.
+func (ser *accountDeletionService) EraseDue() error {
+
+	var deletions []privacyModel.AccountDeletion
+
+	uow := repository.NewUnitOfWork(ser.db)
+	defer uow.RollBack()
+
+	err := ser.repo.GetAll(uow, &deletions, repository.Filter("account_deletions.`scheduled_at` <= ?"+
+		" AND account_deletions.`cancelled_at` IS NULL AND account_deletions.`erased_at` IS NULL", time.Now()))
+	if err != nil {
+		return err
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		return err
+	}
+
+	for i := range deletions {
+		err = ser.erase(&deletions[i])
+		if err != nil {
+			log.GetLogger().Errorf("Account erasure of deletion %s ==> %s", deletions[i].ID, err.Error())
+		}
+	}
+	return nil
+}
+
+// erase will hard delete the user along with their budgets, envelops, transactions, sessions and tokens
+// in a single transaction and anonymize the deletion record. Final export is emailed when it was asked for.
+func (ser *accountDeletionService) erase(deletion *privacyModel.AccountDeletion) error {
+
+	uow := repository.NewUnitOfWork(ser.db)
+	defer uow.RollBack()
+
+	user := userModel.User{}
+
+	err := ser.repo.GetRecord(uow, &user, repository.Filter("users.`id` = ?", deletion.UserID))
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	var message *email.Message
+	var attachmentKeys []string
+	summary := "user was already removed"
+
+	if err == nil {
+		message = &email.Message{
+			To:      []string{user.Email},
+			Subject: "Your account has been deleted",
+			Body: fmt.Sprintf("Hi %s,\n\nYour account and all your data have been permanently deleted"+
+				" as you asked.", user.Name),
+		}
+
+		if deletion.WithExport {
+			attachment, err := ser.export(uow, &user)
+			if err != nil {
+				return err
+			}
+
+			message.Body += " Copy of your data is attached to this email."
+			message.Attachments = append(message.Attachments, *attachment)
+		}
+
+		summary, attachmentKeys, err = ser.eraseUser(uow, &user)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &privacyModel.AccountDeletion{}, map[string]interface{}{
+		"UserID":      uuid.Nil,
+		"SubjectHash": security.HashToken(deletion.UserID.String()),
+		"ErasedAt":    time.Now(),
+		"Summary":     summary,
+	}, repository.Filter("account_deletions.`id` = ?", deletion.ID))
+	if err != nil {
+		return err
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		return err
+	}
+
+	// files are deleted only once the user is erased, files left behind are no longer referred to.
+	for _, key := range append(user.ProfileImageKeys(), attachmentKeys...) {
+		err = ser.store.Delete(context.Background(), key)
+		if err != nil {
+			log.GetLogger().Error(err)
+		}
+	}
+
+	if message != nil {
+		err = ser.sender.Send(message)
+		if err != nil {
+			log.GetLogger().Error(err)
+		}
+	}
+	return nil
+}
+
+// eraseUser will delete budgets of which user is the only member, hand over their records in shared
+// budgets to another member and delete the user along with their sessions and tokens.
+// It returns summary of what was erased, which does not identify the user, along with keys of attachment
+// files of erased budgets.
+func (ser *accountDeletionService) eraseUser(uow *repository.UnitOfWork,
+	user *userModel.User) (string, []string, error) {
+
+	var memberships []budgetModel.BudgetMember
+	var attachmentKeys []string
+
+	err := ser.repo.GetAll(uow, &memberships, repository.Filter("budget_members.`user_id` = ?", user.ID))
+	if err != nil {
+		return "", nil, err
+	}
+
+	erasedBudgets, sharedBudgets := 0, 0
+
+	for _, membership := range memberships {
+		var others []budgetModel.BudgetMember
+
+		err = ser.repo.GetAllInOrder(uow, &others, "budget_members.`created_at`",
+			repository.Filter("budget_members.`budget_id` = ? AND budget_members.`user_id` != ?",
+				membership.BudgetID, user.ID))
+		if err != nil {
+			return "", nil, err
+		}
+
+		if len(others) == 0 {
+			keys, err := ser.eraseBudget(uow, membership.BudgetID)
+			if err != nil {
+				return "", nil, err
+			}
+			attachmentKeys = append(attachmentKeys, keys...)
+			erasedBudgets++
+			continue
+		}
+
+		err = ser.handOver(uow, &membership, others)
+		if err != nil {
+			return "", nil, err
+		}
+		sharedBudgets++
+	}
+
+	userTables := []interface{}{
+		&userModel.RefreshToken{}, &userModel.Session{}, &userModel.APIToken{}, &userModel.RecoveryCode{},
+		&userModel.UserIdentity{}, &userModel.PasswordReset{}, &userModel.Preference{},
+	}
+
+	for _, table := range userTables {
+		err = ser.repo.Delete(uow, table, "`user_id` = ?", user.ID)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	err = ser.repo.Delete(uow, &budgetModel.BudgetInvite{}, "`email` = ?", user.Email)
+	if err != nil {
+		return "", nil, err
+	}
+
+	err = ser.repo.Delete(uow, &userModel.User{}, "`id` = ?", user.ID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return fmt.Sprintf("erased %d budgets, left %d shared budgets", erasedBudgets, sharedBudgets),
+		attachmentKeys, nil
+}
+
+// eraseBudget will delete the budget along with its envelops, accounts, transactions, their attachments
+// and history. It returns keys of attachment files which must be deleted once erasure is committed.
+func (ser *accountDeletionService) eraseBudget(uow *repository.UnitOfWork, budgetID uuid.UUID) ([]string, error) {
+
+	var attachments []envelopModel.Attachment
+
+	err := ser.repo.GetAll(uow, &attachments, repository.Filter("attachments.`budget_id` = ?", budgetID),
+		repository.Select("attachments.`storage_key`"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		keys = append(keys, attachment.StorageKey)
+	}
+
+	err = ser.repo.Delete(uow, &auditModel.ChangeLog{},
+		"`entity_id` IN (SELECT `id` FROM `transactions` WHERE `budget_id` = ?)"+
+			" OR `entity_id` IN (SELECT `id` FROM `envelops` WHERE `budget_id` = ?)"+
+			" OR `entity_id` IN (SELECT `id` FROM `accounts` WHERE `budget_id` = ?)", budgetID, budgetID, budgetID)
+	if err != nil {
+		return nil, err
+	}
+
+	// records are deleted before the records they refer to.
+	budgetTables := []interface{}{
+		&envelopModel.Attachment{}, &envelopModel.Transaction{}, &accountModel.Reconciliation{},
+		&accountModel.Account{}, &envelopModel.Goal{}, &envelopModel.Envelop{}, &budgetModel.BudgetInvite{},
+		&budgetModel.BudgetMember{},
+	}
+
+	for _, table := range budgetTables {
+		err = ser.repo.Delete(uow, table, "`budget_id` = ?", budgetID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = ser.repo.Delete(uow, &budgetModel.Budget{}, "`id` = ?", budgetID)
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// handOver will give records created by the user in shared budget to an owner of the budget and remove
+// the user from it. Longest standing member is made owner when the user was the only owner.
+func (ser *accountDeletionService) handOver(uow *repository.UnitOfWork, membership *budgetModel.BudgetMember,
+	others []budgetModel.BudgetMember) error {
+
+	heir := others[0]
+	for _, other := range others {
+		if other.Role == budgetModel.RoleOwner {
+			heir = other
+			break
+		}
+	}
+
+	if heir.Role != budgetModel.RoleOwner {
+		err := ser.repo.UpdateWithMap(uow, &budgetModel.BudgetMember{}, map[string]interface{}{
+			"Role": budgetModel.RoleOwner,
+		}, repository.Filter("budget_members.`id` = ?", heir.ID))
+		if err != nil {
+			return err
+		}
+	}
+
+	budgetTables := []interface{}{
+		&envelopModel.Envelop{}, &accountModel.Account{}, &envelopModel.Transaction{}, &accountModel.Reconciliation{},
+		&envelopModel.Attachment{}, &envelopModel.Goal{},
+	}
+
+	for _, table := range budgetTables {
+		err := ser.repo.UpdateWithMap(uow, table, map[string]interface{}{
+			"UserID": heir.UserID,
+		}, repository.Filter("`budget_id` = ? AND `user_id` = ?", membership.BudgetID, membership.UserID))
+		if err != nil {
+			return err
+		}
+	}
+
+	return ser.repo.Delete(uow, &budgetModel.BudgetMember{}, "`id` = ?", membership.ID)
+}
+
+// export will create JSON file with profile of the user and data of every budget they are member of.
+func (ser *accountDeletionService) export(uow *repository.UnitOfWork,
+	user *userModel.User) (*email.Attachment, error) {
+
+	export := privacyModel.AccountExport{
+		ExportedAt: time.Now(),
+	}
+
+	err := ser.repo.GetRecord(uow, &export.User, repository.Filter("users.`id` = ?", user.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	preference := userModel.Preference{}
+
+	err = ser.repo.GetRecord(uow, &preference, repository.Filter("user_preferences.`user_id` = ?", user.ID))
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	if err == nil {
+		export.Preference = &preference
+	}
+
+	err = ser.repo.GetAll(uow, &export.Budgets,
+		repository.Join("INNER JOIN budget_members ON budget_members.`budget_id` = budgets.`id`"),
+		repository.Select("budgets.*, budget_members.`role`"),
+		repository.Filter("budget_members.`user_id` = ? AND budgets.`deleted_at` IS NULL", user.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	memberBudgets := "`budget_id` IN (SELECT `budget_id` FROM `budget_members` WHERE `user_id` = ?)"
+
+	err = ser.repo.GetAll(uow, &export.Envelops, repository.Filter(memberBudgets, user.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	err = ser.repo.GetAll(uow, &export.Accounts, repository.Filter(memberBudgets, user.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	err = ser.repo.GetAll(uow, &export.Transactions, repository.Filter(memberBudgets, user.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	err = ser.repo.GetAll(uow, &export.Reconciliations, repository.Filter(memberBudgets, user.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	err = ser.repo.GetAll(uow, &export.Attachments, repository.Filter(memberBudgets, user.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	err = ser.repo.GetAll(uow, &export.Goals, repository.Filter(memberBudgets, user.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, errors.NewUnexpectedError(errors.ErrorCodeJSONMarshalFailure, err)
+	}
+
+	return &email.Attachment{
+		Filename:    "budget-planner-export.json",
+		ContentType: "application/json",
+		Data:        data,
+	}, nil
+}
+
+// reauthenticate will verify password of the user, and code from authenticator app when two factor
+// authentication is enabled. Users who login only with identity provider have to set a password first.
+func (ser *accountDeletionService) reauthenticate(uow *repository.UnitOfWork, user *userModel.User,
+	request *privacyModel.AccountDeletionRequest) error {
+
+	if len(user.Password) == 0 {
+		return errors.NewValidationError("Please set a password using forgot password before deleting your account")
+	}
+
+	err := security.ComparePassword(user.Password, request.Password)
+	if err != nil {
+		return errors.NewValidationError("Password is incorrect")
+	}
+
+	if !user.IsTwoFactorEnabled || user.TOTPSecret == nil {
+		return nil
+	}
+
+	if len(request.Code) == 0 {
+		return errors.NewValidationError("code must be specified")
+	}
+
+	counter, ok := security.ValidateTOTP(string(*user.TOTPSecret), request.Code, user.TOTPLastCounter)
+	if !ok {
+		return errors.NewValidationError("Invalid code")
+	}
+
+	// counter is stored only if it is later than the stored one, so that code can't be used by concurrent requests.
+	var updated int64
+
+	err = ser.repo.UpdateWithMapCount(uow, &userModel.User{}, map[string]interface{}{
+		"TOTPLastCounter": counter,
+	}, &updated, repository.Filter("users.`id` = ? AND users.`totp_last_counter` < ?", user.ID, counter))
+	if err != nil {
+		return err
+	}
+
+	if updated != 1 {
+		return errors.NewValidationError("Invalid code")
+	}
+	return nil
+}
+
+// graceDays will return number of days after which account is erased.
+func (ser *accountDeletionService) graceDays() int {
+	if ser.conf.IsSet(config.AccountDeletionGraceDays) && ser.conf.GetInt64(config.AccountDeletionGraceDays) > 0 {
+		return int(ser.conf.GetInt64(config.AccountDeletionGraceDays))
+	}
+	return defaultDeletionGraceDays
+}
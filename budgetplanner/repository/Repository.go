@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository defines all methods to be present in repository.
@@ -19,8 +20,11 @@ type Repository interface {
 
 	// Other CRUD operations.
 	Add(uow *UnitOfWork, out interface{}) error
+	Upsert(uow *UnitOfWork, out interface{}, conflictColumns []string, assignments []clause.Assignment) error
 	Updates(uow *UnitOfWork, out interface{}) error
 	UpdateWithMap(uow *UnitOfWork, model interface{}, value map[string]interface{}, queryProcessors ...QueryProcessor) error
+	UpdateWithMapCount(uow *UnitOfWork, model interface{}, value map[string]interface{}, count *int64,
+		queryProcessors ...QueryProcessor) error
 	// BatchUpdate(uow *UnitOfWork, value, condition, out interface{}) error
 
 	Save(uow *UnitOfWork, value interface{}) error
@@ -46,7 +50,7 @@ func NewGormRepository() *GormRepository {
 // Get returns record from table by ID.
 func (repository *GormRepository) Get(uow *UnitOfWork, id uuid.UUID, out interface{}, queryProcessors ...QueryProcessor) error {
 	db := uow.DB
-	db, err := executeQueryProcessors(db, out, queryProcessors...)
+	db, err := executeQueryProcessors(db, out, append([]QueryProcessor{tenantQuery(db)}, queryProcessors...)...)
 	if err != nil {
 		return err
 	}
@@ -56,7 +60,7 @@ func (repository *GormRepository) Get(uow *UnitOfWork, id uuid.UUID, out interfa
 // GetAll returns all records from the table.
 func (repository *GormRepository) GetAll(uow *UnitOfWork, out interface{}, queryProcessors ...QueryProcessor) error {
 	db := uow.DB
-	db, err := executeQueryProcessors(db, out, queryProcessors...)
+	db, err := executeQueryProcessors(db, out, append([]QueryProcessor{tenantQuery(db)}, queryProcessors...)...)
 	if err != nil {
 		return err
 	}
@@ -66,7 +70,7 @@ func (repository *GormRepository) GetAll(uow *UnitOfWork, out interface{}, query
 // GetRecord returns a specific record from table with the given filter.
 func (repository *GormRepository) GetRecord(uow *UnitOfWork, out interface{}, queryProcessors ...QueryProcessor) error {
 	db := uow.DB
-	db, err := executeQueryProcessors(db, out, queryProcessors...)
+	db, err := executeQueryProcessors(db, out, append([]QueryProcessor{tenantQuery(db)}, queryProcessors...)...)
 	if err != nil {
 		return err
 	}
@@ -77,7 +81,7 @@ func (repository *GormRepository) GetRecord(uow *UnitOfWork, out interface{}, qu
 func (repository *GormRepository) GetAllInOrder(uow *UnitOfWork, out, orderBy interface{}, queryProcessors ...QueryProcessor) error {
 	db := uow.DB
 
-	db, err := executeQueryProcessors(db, out, queryProcessors...)
+	db, err := executeQueryProcessors(db, out, append([]QueryProcessor{tenantQuery(db)}, queryProcessors...)...)
 	if err != nil {
 		return err
 	}
@@ -87,7 +91,7 @@ func (repository *GormRepository) GetAllInOrder(uow *UnitOfWork, out, orderBy in
 // GetCount gives number of records in database.
 func (repository *GormRepository) GetCount(uow *UnitOfWork, out interface{}, count *int64, queryProcessors ...QueryProcessor) error {
 	db := uow.DB
-	db, err := executeQueryProcessors(db, out, queryProcessors...)
+	db, err := executeQueryProcessors(db, out, append([]QueryProcessor{tenantQuery(db)}, queryProcessors...)...)
 	if err != nil {
 		return err
 	}
@@ -97,21 +101,45 @@ func (repository *GormRepository) GetCount(uow *UnitOfWork, out interface{}, cou
 // GetCountUnscoped gives number of records in database.
 func (repository *GormRepository) GetCountUnscoped(uow *UnitOfWork, out interface{}, count *int64, queryProcessors ...QueryProcessor) error {
 	db := uow.DB.Unscoped()
-	db, err := executeQueryProcessors(db, out, queryProcessors...)
+	db, err := executeQueryProcessors(db, out, append([]QueryProcessor{tenantQuery(db)}, queryProcessors...)...)
 	if err != nil {
 		return err
 	}
 	return db.Debug().Model(out).Count(count).Error
 }
 
-// Add adds record to table.
+// Add adds record to table. Tenant of the request is assigned to the record by the Base hook.
 func (repository *GormRepository) Add(uow *UnitOfWork, out interface{}) error {
 	return uow.DB.Debug().Create(out).Error
 }
 
-// Update updates the record in table.
+// Upsert adds record to table. When the record conflicts with an existing record on conflictColumns,
+// the existing record is updated with assignments instead, in the same statement.
+//
+//	Upsert(uow, &attempt, []string{"key"}, []clause.Assignment{{Column: clause.Column{Name: "failures"},
+//		Value: gorm.Expr("`failures` + 1")}})
+//
+// Assignments are applied in the given order, so they can refer to columns assigned after them.
+func (repository *GormRepository) Upsert(uow *UnitOfWork, out interface{}, conflictColumns []string,
+	assignments []clause.Assignment) error {
+	columns := make([]clause.Column, len(conflictColumns))
+	for index, column := range conflictColumns {
+		columns[index] = clause.Column{Name: column}
+	}
+
+	return uow.DB.Debug().Clauses(clause.OnConflict{
+		Columns:   columns,
+		DoUpdates: clause.Set(assignments),
+	}).Create(out).Error
+}
+
+// Update updates the record in table. Record of another tenant is not updated.
 func (repository *GormRepository) Updates(uow *UnitOfWork, out interface{}) error {
-	return uow.DB.Debug().Model(out).Updates(out).Error
+	db, err := executeQueryProcessors(uow.DB, out, tenantQuery(uow.DB))
+	if err != nil {
+		return err
+	}
+	return db.Debug().Model(out).Updates(out).Error
 }
 
 // UpdateWithMap updates the record in table using map.
@@ -128,17 +156,53 @@ func (repository *GormRepository) UpdateWithMap(uow *UnitOfWork, model interface
 	if err != nil {
 		return err
 	}
+	db, err = executeQueryProcessors(db, model, tenantQuery(db))
+	if err != nil {
+		return err
+	}
 	return db.Debug().Model(model).Updates(value).Error
 }
 
+// UpdateWithMapCount updates the record in table using map like UpdateWithMap and fills count
+// with number of rows updated. It is used for conditional updates, where the filter decides
+// whether the update is done, as only one of concurrent requests can update the row.
+//
+//	UpdateWithMapCount(uow, &token{}, map[string]interface{}{"UsedAt": now}, &count,
+//		Filter("`id` = ? AND `used_at` IS NULL", id))
+func (repository *GormRepository) UpdateWithMapCount(uow *UnitOfWork, model interface{}, value map[string]interface{},
+	count *int64, queryProcessors ...QueryProcessor) error {
+	db := uow.DB
+	db, err := executeQueryProcessors(db, value, queryProcessors...)
+	if err != nil {
+		return err
+	}
+	db, err = executeQueryProcessors(db, model, tenantQuery(db))
+	if err != nil {
+		return err
+	}
+
+	db = db.Debug().Model(model).Updates(value)
+	*count = db.RowsAffected
+	return db.Error
+}
+
 // Save updates the record in table. If value doesn't have primary key, new record will be inserted.
+// Record of another tenant is not updated, saving it fails as its primary key is taken.
 func (repository *GormRepository) Save(uow *UnitOfWork, value interface{}) error {
-	return uow.DB.Debug().Save(value).Error
+	db, err := executeQueryProcessors(uow.DB, value, tenantQuery(uow.DB))
+	if err != nil {
+		return err
+	}
+	return db.Debug().Save(value).Error
 }
 
 // Delete deletes a record from table.
 func (repository *GormRepository) Delete(uow *UnitOfWork, out interface{}, where ...interface{}) error {
-	return uow.DB.Debug().Delete(out, where...).Error
+	db, err := executeQueryProcessors(uow.DB, out, tenantQuery(uow.DB))
+	if err != nil {
+		return err
+	}
+	return db.Debug().Delete(out, where...).Error
 }
 
 // ReplaceAssociations replaces associations from the given entity.
@@ -152,9 +216,10 @@ func (repository *GormRepository) RemoveAssociations(uow *UnitOfWork, out interf
 }
 
 // Scan will fill the out interface with data(fields) based on the given QP conditions.
+// Tenant scope is applied last as model of the query is usually specified by Model.
 func (repository *GormRepository) Scan(uow *UnitOfWork, out interface{}, queryProcessors ...QueryProcessor) error {
 	db := uow.DB
-	db, err := executeQueryProcessors(db, out, queryProcessors...)
+	db, err := executeQueryProcessors(db, out, append(queryProcessors, tenantQuery(db))...)
 	if err != nil {
 		return err
 	}
@@ -205,6 +270,17 @@ func Model(value interface{}) QueryProcessor {
 	}
 }
 
+// ForUpdate will lock the rows read until the unit of work is committed. Rows are read as last committed,
+// not as of the start of the unit of work.
+//
+//	Query: SELECT * FROM users WHERE `id`="101" FOR UPDATE;
+func ForUpdate() QueryProcessor {
+	return func(db *gorm.DB, out interface{}) (*gorm.DB, error) {
+		db = db.Clauses(clause.Locking{Strength: "UPDATE"})
+		return db, nil
+	}
+}
+
 // Filter will filter the results based on condition.
 //
 //	Filter("name= ?","Ramesh")
@@ -337,7 +413,7 @@ func CombineQueries(queryProcessors []QueryProcessor) QueryProcessor {
 //	If ID is to be checked then populate it in the model
 func DoesRecordExist(db *gorm.DB, out interface{}, queryProcessors ...QueryProcessor) (bool, error) {
 	var count int64 = 0
-	db, err := executeQueryProcessors(db, out, queryProcessors...)
+	db, err := executeQueryProcessors(db, out, append([]QueryProcessor{tenantQuery(db)}, queryProcessors...)...)
 	if err != nil {
 		return false, err
 	}
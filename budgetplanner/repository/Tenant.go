@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// tenantSchemas caches parsed schemas of models checked for tenant scoping.
+var tenantSchemas sync.Map
+
+// TenantScope will restrict the query to records of the tenant. Models which do not
+// belong to a tenant (have no TenantID field) are left unrestricted.
+//
+//	TenantScope(tenantID)
+//
+// Query : WHERE envelops.`tenant_id` = "tenantID"
+func TenantScope(tenantID uuid.UUID) QueryProcessor {
+	return func(db *gorm.DB, out interface{}) (*gorm.DB, error) {
+		model := db.Statement.Model
+		if model == nil {
+			model = out
+		}
+		if model == nil {
+			return db, nil
+		}
+		if _, isMap := model.(map[string]interface{}); isMap {
+			return db, nil
+		}
+
+		modelSchema, err := schema.Parse(model, &tenantSchemas, db.NamingStrategy)
+		if err != nil {
+			return db, err
+		}
+
+		if modelSchema.LookUpField("TenantID") == nil {
+			return db, nil
+		}
+
+		db = db.Where(fmt.Sprintf("%s.`tenant_id` = ?", modelSchema.Table), tenantID)
+		return db, nil
+	}
+}
+
+// tenantQuery will return query processor restricting the query to tenant of the request
+// the unit of work was created for. It returns nil when request is not made for a tenant.
+func tenantQuery(db *gorm.DB) QueryProcessor {
+	tenantID, ok := general.TenantFromContext(db.Statement.Context)
+	if !ok {
+		return nil
+	}
+	return TenantScope(tenantID)
+}
@@ -19,11 +19,13 @@ func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
 }
 
 // Commit use to commit after a successful transaction.
-func (uow *UnitOfWork) Commit() {
+// Error is returned if the transaction could not be committed.
+func (uow *UnitOfWork) Commit() error {
 	if !uow.committed {
 		uow.committed = true
-		uow.DB.Commit()
+		return uow.DB.Commit().Error
 	}
+	return nil
 }
 
 // RollBack is used to rollback a transaction on failure.
@@ -12,7 +12,9 @@ import (
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/general"
 	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
 	"gorm.io/gorm"
 )
@@ -23,20 +25,40 @@ type Authentication struct {
 	Config                  config.ConfReader
 	authorizationTypeBearer string
 	authorizationClaims     string
+	keys                    *keySet
 }
 
 // NewAuthentication returns new instance of Authentication
 func NewAuthentication(db *gorm.DB, config config.ConfReader) *Authentication {
-	return &Authentication{
+	auth := &Authentication{
 		db:                      db,
 		Config:                  config,
 		authorizationTypeBearer: "bearer",
 		authorizationClaims:     "authorizationClaims",
+		keys:                    &keySet{},
 	}
+
+	err := auth.ReloadSigningKeys()
+	if err != nil {
+		log.GetLogger().Fatalf("Unable to load signing keys Error:[%s]", err.Error())
+	}
+	return auth
 }
 
 // Middleware will fetch jwt token from header and verify it.
+// Personal access tokens are not accepted, use ScopedMiddleware for routes which allow them.
 func (auth *Authentication) Middleware() gin.HandlerFunc {
+	return auth.authenticate(false)
+}
+
+// ScopedMiddleware will accept jwt token as well as personal access token.
+// Every route of the group must declare the scope it needs with RequireScope.
+func (auth *Authentication) ScopedMiddleware() gin.HandlerFunc {
+	return auth.authenticate(true)
+}
+
+// authenticate will fetch token from header and verify it.
+func (auth *Authentication) authenticate(allowAPITokens bool) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 
 		authorizationHeader := ctx.GetHeader("Authorization")
@@ -63,14 +85,29 @@ func (auth *Authentication) Middleware() gin.HandlerFunc {
 		accessToken := fields[1]
 		claims := userModel.Claims{}
 
-		payload, err := jwt.ParseWithClaims(
-			accessToken, &claims,
-			func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, errors.NewHTTPError(errors.ErrorCodeInternalError, http.StatusInternalServerError)
-				}
-				return []byte(auth.Config.GetString(config.JWTKey)), nil
-			})
+		if strings.HasPrefix(accessToken, userModel.APITokenPrefix) {
+			if !allowAPITokens {
+				log.GetLogger().Error("personal access token used for route which does not allow it")
+				web.RespondErrorMessage(ctx, http.StatusForbidden, "personal access tokens are not allowed")
+				return
+			}
+
+			err := auth.verifyAPIToken(accessToken, &claims)
+			if err != nil {
+				log.GetLogger().Error(err)
+				web.RespondErrorMessage(ctx, http.StatusUnauthorized, err.Error())
+				return
+			}
+
+			if !auth.setRequestContext(ctx, claims) {
+				return
+			}
+
+			ctx.Next()
+			return
+		}
+
+		payload, err := jwt.ParseWithClaims(accessToken, &claims, auth.keyFunc)
 		if err != nil {
 			if err == jwt.ErrSignatureInvalid {
 				log.GetLogger().Error(err.Error())
@@ -96,6 +133,26 @@ func (auth *Authentication) Middleware() gin.HandlerFunc {
 
 		fmt.Printf("Key: userID, value: %v\n", claims.UserID)
 
+		if len(claims.Purpose) > 0 {
+			log.GetLogger().Error(fmt.Sprintf("%s token used as access token", claims.Purpose))
+			web.RespondErrorMessage(ctx, http.StatusUnauthorized, "invalid token provided")
+			return
+		}
+
+		revoked, err := auth.isRevoked(&claims)
+		if err != nil {
+			log.GetLogger().Error(err)
+			web.RespondErrorMessage(ctx, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if revoked {
+			log.GetLogger().Error("token has been revoked")
+			web.RespondErrorMessage(ctx, http.StatusUnauthorized, "Session expired! Please login again")
+			return
+		}
+
+		auth.touchSession(claims.SessionID)
+
 		// if token is valid then it will be redirected to the endpoint
 		if payload.Valid {
 			if ctx.Request.Method == "OPTION" {
@@ -104,24 +161,345 @@ func (auth *Authentication) Middleware() gin.HandlerFunc {
 				return
 			}
 
-			ctx.Set(auth.authorizationClaims, claims)
+			if !auth.setRequestContext(ctx, claims) {
+				return
+			}
+
+			ctx.Next()
+			return
+		}
+	}
+}
+
+// setRequestContext will store claims of the user and attach acting user and their tenant to context
+// of the request, so that records created are attributed to them and queries are scoped to the tenant.
+// Admin impersonating the user is attached as well, so that changes made by them are audited.
+// It responds with an error and returns false if user or their tenant is no longer active.
+func (auth *Authentication) setRequestContext(ctx *gin.Context, claims userModel.Claims) bool {
+
+	tenantID, err := auth.resolveTenant(claims.UserID)
+	if err != nil {
+		log.GetLogger().Error(err)
+		web.RespondErrorMessage(ctx, http.StatusForbidden, err.Error())
+		return false
+	}
+
+	if claims.ImpersonatorID != nil {
+		err = auth.verifyImpersonator(*claims.ImpersonatorID)
+		if err != nil {
+			log.GetLogger().Error(err)
+			web.RespondErrorMessage(ctx, http.StatusForbidden, err.Error())
+			return false
+		}
+
+		log.GetLogger().Infof("user %s impersonated by admin %s: %s %s", claims.UserID, *claims.ImpersonatorID,
+			ctx.Request.Method, ctx.Request.URL.Path)
+	}
+
+	requestCtx := general.WithTenant(general.WithActor(ctx.Request.Context(), claims.UserID), tenantID)
+	if claims.ImpersonatorID != nil {
+		requestCtx = general.WithImpersonator(requestCtx, *claims.ImpersonatorID)
+	}
+
+	ctx.Set(auth.authorizationClaims, claims)
+	ctx.Request = ctx.Request.WithContext(requestCtx)
+	return true
+}
+
+// resolveTenant will return tenant of the user. Disabled users and users of deactivated tenants are denied access.
+func (auth *Authentication) resolveTenant(userID uuid.UUID) (uuid.UUID, error) {
+	user := userModel.User{}
+
+	err := auth.db.Joins("INNER JOIN tenants ON tenants.`id` = users.`tenant_id`").
+		Where("users.`id` = ? AND users.`deleted_at` IS NULL AND users.`disabled_at` IS NULL"+
+			" AND tenants.`is_active` = 1 AND tenants.`deleted_at` IS NULL", userID).
+		Select("users.`tenant_id`").First(&user).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return uuid.Nil, errors.NewValidationError("Access denied")
+		}
+		return uuid.Nil, err
+	}
+	return user.TenantID, nil
+}
+
+// RequireScope will allow personal access tokens only if they are granted the scope.
+// Login tokens have access to every scope. It must be used after ScopedMiddleware.
+func (auth *Authentication) RequireScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		claims, err := auth.ExtractClaims(ctx)
+		if err != nil {
+			log.GetLogger().Error(err)
+			web.RespondErrorMessage(ctx, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if claims.IsAPIToken && !hasScope(claims.Scopes, scope) {
+			log.GetLogger().Error(fmt.Sprintf("personal access token does not have %s scope", scope))
+			web.RespondErrorMessage(ctx, http.StatusForbidden, fmt.Sprintf("token does not have %s scope", scope))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// verifyAPIToken will verify personal access token and fill claims of its owner.
+func (auth *Authentication) verifyAPIToken(token string, claims *userModel.Claims) error {
+	apiToken := userModel.APIToken{}
+
+	err := auth.db.Where("api_tokens.`token_hash` = ? AND api_tokens.`deleted_at` IS NULL", HashToken(token)).
+		First(&apiToken).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewValidationError("invalid token provided")
+		}
+		return err
+	}
+
+	now := time.Now()
+	if apiToken.ExpiresAt != nil && apiToken.ExpiresAt.Before(now) {
+		return errors.NewValidationError("token has expired")
+	}
+
+	// last used time is updated at most once a minute.
+	if apiToken.LastUsedAt == nil || apiToken.LastUsedAt.Before(now.Add(-time.Minute)) {
+		err = auth.db.Model(&userModel.APIToken{}).Where("api_tokens.`id` = ?", apiToken.ID).
+			UpdateColumn("last_used_at", now).Error
+		if err != nil {
+			log.GetLogger().Error(err)
+		}
+	}
+
+	claims.UserID = apiToken.UserID
+	claims.Scopes = userModel.SplitScopes(apiToken.Scopes)
+	claims.IsAPIToken = true
+	return nil
+}
+
+// hasScope will return true if scope is in scopes.
+func hasScope(scopes []string, scope string) bool {
+	for _, granted := range scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// keyFunc will return key used to verify signature of tokens. Tokens with kid are verified with
+// the asymmetric key of that kid, tokens without kid are verified with HMAC JWT_KEY if it is set.
+func (auth *Authentication) keyFunc(token *jwt.Token) (interface{}, error) {
+	if kid, ok := token.Header["kid"].(string); ok {
+		return auth.verificationKey(kid, token.Method)
+	}
+
+	key := auth.Config.GetString(config.JWTKey)
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || len(key) == 0 {
+		return nil, errors.NewHTTPError(errors.ErrorCodeInternalError, http.StatusInternalServerError)
+	}
+	return []byte(key), nil
+}
+
+// AuthorizeUser will verify that the :userID path param, when present, belongs to the token's subject.
+// It must be used after Middleware so that claims are available.
+func (auth *Authentication) AuthorizeUser() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
 
+		pathUserID := ctx.Param("userID")
+		if len(pathUserID) == 0 {
 			ctx.Next()
 			return
 		}
+
+		userID, err := auth.ExtractUserID(ctx)
+		if err != nil {
+			log.GetLogger().Error(err)
+			web.RespondErrorMessage(ctx, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		requestedUserID, err := uuid.Parse(pathUserID)
+		if err != nil || requestedUserID != userID {
+			log.GetLogger().Error(fmt.Sprintf("user %s is not allowed to access resources of user %s", userID, pathUserID))
+			web.RespondErrorMessage(ctx, http.StatusForbidden, "Access denied")
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// verifyImpersonator will return error if admin who issued impersonation token is no longer an active admin.
+func (auth *Authentication) verifyImpersonator(adminID uuid.UUID) error {
+	isAdmin, err := auth.hasRole(adminID, userModel.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return errors.NewValidationError("Access denied")
+	}
+	return nil
+}
+
+// hasRole will return true if user is active and has the role.
+func (auth *Authentication) hasRole(userID uuid.UUID, role string) (bool, error) {
+	return repository.DoesRecordExist(auth.db, userModel.User{},
+		repository.Filter("users.`id` = ? AND users.`role` = ? AND users.`deleted_at` IS NULL"+
+			" AND users.`disabled_at` IS NULL", userID, role))
+}
+
+// RequireRole will allow only users with the role. Impersonation tokens are never allowed,
+// so that admin can't act as admin on behalf of another user. It must be used after Middleware.
+func (auth *Authentication) RequireRole(role string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		claims, err := auth.ExtractClaims(ctx)
+		if err != nil {
+			log.GetLogger().Error(err)
+			web.RespondErrorMessage(ctx, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if claims.ImpersonatorID != nil {
+			log.GetLogger().Error(fmt.Sprintf("impersonation token of user %s used for %s route", claims.UserID, role))
+			web.RespondErrorMessage(ctx, http.StatusForbidden, "Access denied")
+			return
+		}
+
+		hasRole, err := auth.hasRole(claims.UserID, role)
+		if err != nil {
+			log.GetLogger().Error(err)
+			web.RespondErrorMessage(ctx, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !hasRole {
+			log.GetLogger().Error(fmt.Sprintf("user %s does not have %s role", claims.UserID, role))
+			web.RespondErrorMessage(ctx, http.StatusForbidden, "Access denied")
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// RejectImpersonation will deny impersonation tokens, so that admin impersonating a user can't change
+// credentials, sessions or account of the user. It must be used after Middleware.
+func (auth *Authentication) RejectImpersonation() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		claims, err := auth.ExtractClaims(ctx)
+		if err != nil {
+			log.GetLogger().Error(err)
+			web.RespondErrorMessage(ctx, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if claims.ImpersonatorID != nil {
+			log.GetLogger().Error(fmt.Sprintf("impersonation token of user %s used by admin %s for %s %s",
+				claims.UserID, *claims.ImpersonatorID, ctx.Request.Method, ctx.Request.URL.Path))
+			web.RespondErrorMessage(ctx, http.StatusForbidden, "Not allowed while impersonating")
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// AcrossTenants will remove tenant scope of the request so that queries see records of every tenant.
+// It must only be used after the caller is authorized to act on every tenant, like with RequireRole.
+func (auth *Authentication) AcrossTenants() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Request = ctx.Request.WithContext(general.WithTenant(ctx.Request.Context(), uuid.Nil))
+		ctx.Next()
+	}
+}
+
+// AuthorizeTenantAdmin will verify that the :tenantID path param is tenant of the token's subject
+// and that the subject is admin of the tenant. It must be used after Middleware.
+func (auth *Authentication) AuthorizeTenantAdmin() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		userID, err := auth.ExtractUserID(ctx)
+		if err != nil {
+			log.GetLogger().Error(err)
+			web.RespondErrorMessage(ctx, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		tenantID, err := web.NewParser(ctx).GetTenantID()
+		if err != nil {
+			log.GetLogger().Error(err)
+			web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		isAdmin, err := repository.DoesRecordExist(auth.db, userModel.User{},
+			repository.Filter("users.`id` = ? AND users.`tenant_id` = ? AND users.`is_tenant_admin` = 1"+
+				" AND users.`deleted_at` IS NULL", userID, tenantID))
+		if err != nil {
+			log.GetLogger().Error(err)
+			web.RespondErrorMessage(ctx, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !isAdmin {
+			log.GetLogger().Error(fmt.Sprintf("user %s is not admin of tenant %s", userID, tenantID))
+			web.RespondErrorMessage(ctx, http.StatusForbidden, "Access denied")
+			return
+		}
+
+		ctx.Next()
 	}
 }
 
 // ExtractUserID will extract userID from payload.
 func (auth *Authentication) ExtractUserID(ctx *gin.Context) (uuid.UUID, error) {
 
-	var claims userModel.Claims
+	claims, err := auth.ExtractClaims(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return claims.UserID, nil
+}
+
+// ExtractClaims will extract claims of the access token from payload.
+func (auth *Authentication) ExtractClaims(ctx *gin.Context) (userModel.Claims, error) {
 
 	payload, ok := ctx.Get(auth.authorizationClaims)
 	if !ok {
-		return uuid.Nil, errors.NewValidationError("claims not found.")
+		return userModel.Claims{}, errors.NewValidationError("claims not found.")
 	}
 
-	claims = payload.(userModel.Claims)
-	return claims.UserID, nil
+	return payload.(userModel.Claims), nil
+}
+
+// isRevoked will check access token and its session against the revocation list.
+func (auth *Authentication) isRevoked(claims *userModel.Claims) (bool, error) {
+	tokenIDs := []uuid.UUID{claims.SessionID}
+
+	tokenID, err := uuid.Parse(claims.ID)
+	if err == nil {
+		tokenIDs = append(tokenIDs, tokenID)
+	}
+
+	return repository.DoesRecordExist(auth.db, userModel.RevokedToken{},
+		repository.Filter("revoked_tokens.`token_id` IN (?) AND revoked_tokens.`expires_at` > ?",
+			tokenIDs, time.Now()))
+}
+
+// touchSession will update last seen time of the session. It is updated at most once a minute.
+func (auth *Authentication) touchSession(sessionID uuid.UUID) {
+	if sessionID == uuid.Nil {
+		return
+	}
+
+	now := time.Now()
+
+	err := auth.db.Model(&userModel.Session{}).
+		Where("sessions.`id` = ? AND sessions.`last_seen_at` < ?", sessionID, now.Add(-time.Minute)).
+		UpdateColumn("last_seen_at", now).Error
+	if err != nil {
+		log.GetLogger().Error(err)
+	}
 }
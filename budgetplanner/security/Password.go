@@ -1,6 +1,20 @@
 package security
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"sync"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// defaultPasswordMinLength is used when minimum length of password is not configured.
+const defaultPasswordMinLength = 8
+
+var (
+	dummyHash     []byte
+	dummyHashOnce sync.Once
+)
 
 func HashPassword(password string) ([]byte, error) {
 	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -9,3 +23,28 @@ func HashPassword(password string) ([]byte, error) {
 func ComparePassword(hashedPassword, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
+
+// ComparePasswordWithDummy will take same time as ComparePassword, it is used when user does not exist
+// so that response time does not reveal registered usernames.
+func ComparePasswordWithDummy(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("budget-planner-dummy-password"), bcrypt.DefaultCost)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
+
+// PasswordPolicy will return password policy read from config.
+func (auth *Authentication) PasswordPolicy() *userModel.PasswordPolicy {
+	policy := userModel.PasswordPolicy{
+		MinLength:     defaultPasswordMinLength,
+		RequireUpper:  auth.Config.GetBool(config.PasswordRequireUpper),
+		RequireLower:  auth.Config.GetBool(config.PasswordRequireLower),
+		RequireDigit:  auth.Config.GetBool(config.PasswordRequireDigit),
+		RequireSymbol: auth.Config.GetBool(config.PasswordRequireSymbol),
+	}
+
+	if auth.Config.IsSet(config.PasswordMinLength) && auth.Config.GetInt64(config.PasswordMinLength) > 0 {
+		policy.MinLength = int(auth.Config.GetInt64(config.PasswordMinLength))
+	}
+	return &policy
+}
@@ -0,0 +1,189 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+)
+
+// signingKeyExtension is extension of key files, name of the file without extension is used as kid.
+const signingKeyExtension = ".pem"
+
+// signingKey is asymmetric key used to sign or verify tokens.
+// Keys loaded from a public key file can only be used to verify tokens.
+type signingKey struct {
+	id         string
+	method     jwt.SigningMethod
+	privateKey crypto.PrivateKey
+	publicKey  crypto.PublicKey
+}
+
+// keySet contains asymmetric keys by kid along with kid of the key used for signing new tokens.
+type keySet struct {
+	sync.RWMutex
+	keys      map[string]*signingKey
+	activeKID string
+}
+
+// ReloadSigningKeys will load asymmetric keys from JWT_KEYS_DIR. Every <kid>.pem file in the directory
+// can be RSA (RS256) or Ed25519 (EdDSA) private or public key. New tokens are signed with JWT_SIGNING_KEY_ID
+// or, when it is not set, with the private key having greatest kid. Keys are rotated by adding a new key,
+// switching JWT_SIGNING_KEY_ID to it and removing old key once tokens signed with it have expired.
+// Keys are left unchanged if loading fails. HMAC JWT_KEY is used when the directory is not configured.
+func (auth *Authentication) ReloadSigningKeys() error {
+	dir := auth.Config.GetString(config.JWTKeysDir)
+	if len(dir) == 0 {
+		return nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+signingKeyExtension))
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*signingKey, len(paths))
+	kids := []string{}
+
+	for _, path := range paths {
+		key, err := loadSigningKey(path)
+		if err != nil {
+			return err
+		}
+
+		keys[key.id] = key
+		if key.privateKey != nil {
+			kids = append(kids, key.id)
+		}
+	}
+
+	activeKID := auth.Config.GetString(config.JWTSigningKeyID)
+	if len(activeKID) == 0 {
+		if len(kids) == 0 {
+			return fmt.Errorf("no private key found in %s", dir)
+		}
+		sort.Strings(kids)
+		activeKID = kids[len(kids)-1]
+	}
+
+	if key, ok := keys[activeKID]; !ok || key.privateKey == nil {
+		return fmt.Errorf("private key %s not found in %s", activeKID, dir)
+	}
+
+	auth.keys.Lock()
+	defer auth.keys.Unlock()
+
+	auth.keys.keys = keys
+	auth.keys.activeKID = activeKID
+	return nil
+}
+
+// KeySet will return public keys which can be used to verify tokens.
+func (auth *Authentication) KeySet() *userModel.JSONWebKeySet {
+	auth.keys.RLock()
+	defer auth.keys.RUnlock()
+
+	kids := make([]string, 0, len(auth.keys.keys))
+	for kid := range auth.keys.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	keySet := userModel.JSONWebKeySet{
+		Keys: make([]userModel.JSONWebKey, 0, len(kids)),
+	}
+
+	for _, kid := range kids {
+		key := auth.keys.keys[kid]
+		jwk := userModel.JSONWebKey{
+			KeyID:     key.id,
+			Use:       "sig",
+			Algorithm: key.method.Alg(),
+		}
+
+		switch publicKey := key.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		}
+
+		keySet.Keys = append(keySet.Keys, jwk)
+	}
+	return &keySet
+}
+
+// activeSigningKey will return key used for signing new tokens, nil when asymmetric keys are not configured.
+func (auth *Authentication) activeSigningKey() *signingKey {
+	auth.keys.RLock()
+	defer auth.keys.RUnlock()
+
+	if len(auth.keys.activeKID) == 0 {
+		return nil
+	}
+	return auth.keys.keys[auth.keys.activeKID]
+}
+
+// verificationKey will return public key of kid if it can verify tokens signed using method.
+func (auth *Authentication) verificationKey(kid string, method jwt.SigningMethod) (crypto.PublicKey, error) {
+	auth.keys.RLock()
+	defer auth.keys.RUnlock()
+
+	key, ok := auth.keys.keys[kid]
+	if !ok || key.method.Alg() != method.Alg() {
+		return nil, errors.NewValidationError("invalid token provided")
+	}
+	return key.publicKey, nil
+}
+
+// loadSigningKey will parse RSA or Ed25519 private or public key from PEM file.
+func loadSigningKey(path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := signingKey{
+		id: strings.TrimSuffix(filepath.Base(path), signingKeyExtension),
+	}
+
+	if rsaKey, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		key.method, key.privateKey, key.publicKey = jwt.SigningMethodRS256, rsaKey, &rsaKey.PublicKey
+		return &key, nil
+	}
+
+	if rsaKey, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		key.method, key.publicKey = jwt.SigningMethodRS256, rsaKey
+		return &key, nil
+	}
+
+	if edKey, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+		if privateKey, ok := edKey.(ed25519.PrivateKey); ok {
+			key.method, key.privateKey, key.publicKey = jwt.SigningMethodEdDSA, privateKey, privateKey.Public()
+			return &key, nil
+		}
+	}
+
+	if edKey, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		key.method, key.publicKey = jwt.SigningMethodEdDSA, edKey
+		return &key, nil
+	}
+
+	return nil, fmt.Errorf("%s is not a RSA or Ed25519 key", path)
+}
@@ -0,0 +1,119 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+)
+
+// TOTP parameters as per RFC 6238, these are the defaults supported by authenticator apps.
+const (
+	totpIssuer = "budget-planner"
+	totpDigits = 6
+	totpPeriod = 30
+	// totpSkew is number of periods before and after current period in which code is accepted.
+	totpSkew = 1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret will create new base32 encoded secret for TOTP.
+func GenerateTOTPSecret() (string, error) {
+	buf := make([]byte, 20)
+
+	_, err := rand.Read(buf)
+	if err != nil {
+		log.GetLogger().Error(err.Error())
+		return "", errors.NewHTTPError("unable to generate secret", http.StatusInternalServerError)
+	}
+	return totpEncoding.EncodeToString(buf), nil
+}
+
+// TOTPURI will return otpauth:// URI of the secret which can be shown as QR code.
+func TOTPURI(accountName, secret string) string {
+	values := url.Values{}
+	values.Set("secret", secret)
+	values.Set("issuer", totpIssuer)
+	values.Set("algorithm", "SHA1")
+	values.Set("digits", fmt.Sprint(totpDigits))
+	values.Set("period", fmt.Sprint(totpPeriod))
+
+	return "otpauth://totp/" + url.PathEscape(totpIssuer+":"+accountName) + "?" + values.Encode()
+}
+
+// ValidateTOTP will verify code against the secret. Code is accepted only for a period
+// after lastCounter so that same code can not be used twice. Counter of the matched period is returned.
+func ValidateTOTP(secret, code string, lastCounter int64) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := time.Now().Unix() / totpPeriod
+	for counter := current - totpSkew; counter <= current+totpSkew; counter++ {
+		if counter <= lastCounter {
+			continue
+		}
+		if hmac.Equal([]byte(totpCode(key, counter)), []byte(code)) {
+			return counter, true
+		}
+	}
+	return 0, false
+}
+
+// totpCode will generate code of the key for the counter.
+func totpCode(key []byte, counter int64) string {
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%modulo)
+}
+
+// GenerateRecoveryCode will create new one-time recovery code like "k3j9d-x8m2q".
+func GenerateRecoveryCode() (string, error) {
+	buf := make([]byte, 7)
+
+	_, err := rand.Read(buf)
+	if err != nil {
+		log.GetLogger().Error(err.Error())
+		return "", errors.NewHTTPError("unable to generate recovery code", http.StatusInternalServerError)
+	}
+
+	code := strings.ToLower(totpEncoding.EncodeToString(buf))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// NormalizeRecoveryCode will format recovery code entered by user the same way it was generated.
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.ReplaceAll(code, " ", ""), "-", ""))
+	if len(code) != 10 {
+		return code
+	}
+	return code[:5] + "-" + code[5:]
+}
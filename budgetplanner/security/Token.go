@@ -1,10 +1,15 @@
 package security
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"net/http"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
@@ -12,13 +17,22 @@ import (
 )
 
 // GenerateToken take userID, email, tablename as Role  Return Token
+// Token is signed with active asymmetric key when configured, else with HMAC JWT_KEY.
 func (auth *Authentication) generateToken(claims jwt.Claims) (string, error) {
 
+	var key interface{} = []byte(auth.Config.GetString(config.JWTKey))
+
 	// NewWithClaims returns token
 	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
 
+	if signingKey := auth.activeSigningKey(); signingKey != nil {
+		token = jwt.NewWithClaims(signingKey.method, claims)
+		token.Header["kid"] = signingKey.id
+		key = signingKey.privateKey
+	}
+
 	// access token string based on token
-	tokenString, err := token.SignedString([]byte(auth.Config.GetString(config.JWTKey)))
+	tokenString, err := token.SignedString(key)
 	if err != nil {
 		log.GetLogger().Error(err.Error())
 		return "", errors.NewHTTPError("unable to generate token", http.StatusInternalServerError)
@@ -26,7 +40,13 @@ func (auth *Authentication) generateToken(claims jwt.Claims) (string, error) {
 	return tokenString, nil
 }
 
-// GenerateLoginToken will create new login token
+// Default lifetime of tokens, used when not configured.
+const (
+	defaultAccessTokenTTLMinutes = 15
+	defaultRefreshTokenTTLDays   = 30
+)
+
+// GenerateLoginToken will create new short-lived access token for session of the user.
 func (auth *Authentication) GenerateLoginToken(a *userModel.Authentication) error {
 
 	// Create a claims map
@@ -38,13 +58,41 @@ func (auth *Authentication) GenerateLoginToken(a *userModel.Authentication) erro
 	// 	"exp":     time.Now().Add(time.Hour * 20).Unix(),
 	// }
 
+	expiresAt := time.Now().Add(auth.AccessTokenTTL())
+	a.ExpiresAt = &expiresAt
+
+	claims := userModel.Claims{
+		UserID:    a.UserID,
+		SessionID: a.SessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "budget-planner",
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        uuid.New().String(),
+		},
+	}
+
+	var err error
+	a.Token, err = auth.generateToken(claims)
+
+	return err
+}
+
+// GenerateImpersonationToken will create short-lived access token of the user for admin impersonating them.
+// Token is not bound to a session and no refresh token is issued for it.
+func (auth *Authentication) GenerateImpersonationToken(a *userModel.Authentication, impersonatorID uuid.UUID) error {
+
+	expiresAt := time.Now().Add(auth.AccessTokenTTL())
+	a.ExpiresAt = &expiresAt
+
 	claims := userModel.Claims{
-		UserID: a.UserID,
+		UserID:         a.UserID,
+		ImpersonatorID: &impersonatorID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    "budget-planner",
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24)),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ID:        a.UserID.String(),
+			ID:        uuid.New().String(),
 		},
 	}
 
@@ -53,3 +101,61 @@ func (auth *Authentication) GenerateLoginToken(a *userModel.Authentication) erro
 
 	return err
 }
+
+// GeneratePurposeToken will create signed token which can only be used for specified purpose.
+func (auth *Authentication) GeneratePurposeToken(claims *userModel.Claims, ttl time.Duration) (string, error) {
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    "budget-planner",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ID:        uuid.New().String(),
+	}
+
+	return auth.generateToken(claims)
+}
+
+// ParsePurposeToken will verify signature, expiry and purpose of token and fill its claims.
+func (auth *Authentication) ParsePurposeToken(token, purpose string, claims *userModel.Claims) error {
+	_, err := jwt.ParseWithClaims(token, claims, auth.keyFunc)
+	if err != nil || claims.Purpose != purpose {
+		return errors.NewValidationError("Invalid or expired token")
+	}
+	return nil
+}
+
+// GenerateRandomToken will create new opaque token, like refresh token, along with its hash.
+// Only the hash should be stored.
+func (auth *Authentication) GenerateRandomToken() (string, string, error) {
+	buf := make([]byte, 32)
+
+	_, err := rand.Read(buf)
+	if err != nil {
+		log.GetLogger().Error(err.Error())
+		return "", "", errors.NewHTTPError("unable to generate token", http.StatusInternalServerError)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken will return hex encoded sha256 hash of token.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// AccessTokenTTL will return lifetime of access token.
+func (auth *Authentication) AccessTokenTTL() time.Duration {
+	if auth.Config.IsSet(config.AccessTokenTTLMinutes) && auth.Config.GetInt64(config.AccessTokenTTLMinutes) > 0 {
+		return time.Duration(auth.Config.GetInt64(config.AccessTokenTTLMinutes)) * time.Minute
+	}
+	return defaultAccessTokenTTLMinutes * time.Minute
+}
+
+// RefreshTokenTTL will return lifetime of refresh token.
+func (auth *Authentication) RefreshTokenTTL() time.Duration {
+	if auth.Config.IsSet(config.RefreshTokenTTLDays) && auth.Config.GetInt64(config.RefreshTokenTTLDays) > 0 {
+		return time.Duration(auth.Config.GetInt64(config.RefreshTokenTTLDays)) * 24 * time.Hour
+	}
+	return defaultRefreshTokenTTLDays * 24 * time.Hour
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// s3RequestTimeout is maximum time taken by a request to S3 server.
+const s3RequestTimeout = 30 * time.Second
+
+// s3Storage keeps files in a bucket of S3 compatible server. Requests are signed with AWS signature version 4.
+type s3Storage struct {
+	endpoint  string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+}
+
+// s3Client is shared by requests so that connections are reused.
+var s3Client = &http.Client{Timeout: s3RequestTimeout}
+
+// Put will upload the file to the bucket.
+func (s *s3Storage) Put(ctx context.Context, key, contentType string, data []byte) error {
+	response, err := s.do(ctx, http.MethodPut, key, contentType, data)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return s.responseError(response)
+	}
+	return nil
+}
+
+// Get will download the file from the bucket.
+func (s *s3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	response, err := s.do(ctx, http.MethodGet, key, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		response.Body.Close()
+		return nil, ErrNotFound
+	}
+
+	if response.StatusCode != http.StatusOK {
+		defer response.Body.Close()
+		return nil, s.responseError(response)
+	}
+	return response.Body, nil
+}
+
+// Delete will remove the file from the bucket. S3 does not report missing files on delete.
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	response, err := s.do(ctx, http.MethodDelete, key, "", nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusOK {
+		return s.responseError(response)
+	}
+	return nil
+}
+
+// do will send signed request for the object of key.
+func (s *s3Storage) do(ctx context.Context, method, key, contentType string, data []byte) (*http.Response, error) {
+	err := validateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.endpoint) == 0 || len(s.bucket) == 0 {
+		return nil, fmt.Errorf("S3 storage endpoint and bucket must be configured")
+	}
+
+	endpoint, err := url.Parse(s.endpoint + "/" + s.bucket + "/" + key)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, endpoint.String(), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{
+		"host":                 endpoint.Host,
+		"x-amz-content-sha256": hashHex(data),
+		"x-amz-date":           time.Now().UTC().Format("20060102T150405Z"),
+	}
+	if len(contentType) > 0 {
+		headers["content-type"] = contentType
+	}
+
+	for name, value := range headers {
+		if name != "host" {
+			request.Header.Set(name, value)
+		}
+	}
+
+	request.Header.Set("Authorization", s.authorization(method, endpoint.EscapedPath(), headers))
+
+	return s3Client.Do(request)
+}
+
+// authorization will create AWS signature version 4 authorization header for the request.
+func (s *s3Storage) authorization(method, path string, headers map[string]string) string {
+	amzDate := headers["x-amz-date"]
+	date := amzDate[:8]
+	scope := date + "/" + s.region + "/s3/aws4_request"
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := strings.Builder{}
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{method, path, "", canonicalHeaders.String(), signedHeaders,
+		headers["x-amz-content-sha256"]}, "\n")
+
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope,
+		hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	return fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature)
+}
+
+// responseError will create error from failed response of S3 server.
+func (s *s3Storage) responseError(response *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	return fmt.Errorf("S3 storage responded with %s: %s", response.Status, strings.TrimSpace(string(body)))
+}
+
+// hashHex will return hex encoded SHA-256 hash of data.
+func hashHex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// hmacSHA256 will return HMAC-SHA256 of data with key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
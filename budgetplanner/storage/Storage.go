@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+)
+
+// Drivers of storage.
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// defaultLocalDir is used when STORAGE_LOCAL_DIR is not set.
+const defaultLocalDir = "uploads"
+
+// defaultS3Region is used when STORAGE_S3_REGION is not set, MinIO accepts it by default.
+const defaultS3Region = "us-east-1"
+
+// validKey allows keys made of path segments with letters, digits, dot, dash and underscore.
+var validKey = regexp.MustCompile(`^[a-zA-Z0-9_-][a-zA-Z0-9_.-]*(/[a-zA-Z0-9_-][a-zA-Z0-9_.-]*)*$`)
+
+// ErrNotFound is returned when there is no file for the key.
+var ErrNotFound = errors.NewValidationError("File not found")
+
+// Storage is implemented by every place where uploaded files are kept. Files are addressed by
+// slash separated keys, which are generated by the app and never taken from user.
+type Storage interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStorage will return storage of STORAGE_DRIVER, files are kept in STORAGE_LOCAL_DIR when it is not set.
+func NewStorage(conf config.ConfReader) Storage {
+	if conf.GetString(config.StorageDriver) != DriverS3 {
+		dir := defaultLocalDir
+		if conf.IsSet(config.StorageLocalDir) {
+			dir = conf.GetString(config.StorageLocalDir)
+		}
+		return &localStorage{dir: dir}
+	}
+
+	region := defaultS3Region
+	if conf.IsSet(config.StorageS3Region) {
+		region = conf.GetString(config.StorageS3Region)
+	}
+
+	return &s3Storage{
+		endpoint:  strings.TrimRight(conf.GetString(config.StorageS3Endpoint), "/"),
+		region:    region,
+		bucket:    conf.GetString(config.StorageS3Bucket),
+		accessKey: conf.GetString(config.StorageS3AccessKey),
+		secretKey: conf.GetString(config.StorageS3SecretKey),
+	}
+}
+
+// validateKey will return error if key is not a valid relative path.
+func validateKey(key string) error {
+	if !validKey.MatchString(key) {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return fmt.Errorf("invalid storage key %q", key)
+		}
+	}
+	return nil
+}
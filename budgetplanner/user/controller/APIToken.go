@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// APITokenController provides methods to manage personal access tokens.
+type APITokenController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	createAPIToken(ctx *gin.Context)
+	getAPITokens(ctx *gin.Context)
+	deleteAPIToken(ctx *gin.Context)
+}
+
+// apiTokenController.
+type apiTokenController struct {
+	service service.APITokenService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewAPITokenController create new APITokenController
+func NewAPITokenController(ser service.APITokenService, log log.Logger,
+	auth *security.Authentication) APITokenController {
+	return &apiTokenController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for api token controller.
+// Personal access tokens can't be used to manage tokens.
+func (c *apiTokenController) RegisterRoutes(router *gin.RouterGroup) {
+	guarded := router.Group("/users", c.auth.Middleware(), c.auth.AuthorizeUser(), c.auth.RejectImpersonation())
+	guarded.GET("/:userID/api-tokens", c.getAPITokens)
+	guarded.POST("/:userID/api-tokens", c.createAPIToken)
+	guarded.DELETE("/:userID/api-tokens/:tokenID", c.deleteAPIToken)
+}
+
+// createAPIToken will create new personal access token for the user.
+func (c *apiTokenController) createAPIToken(ctx *gin.Context) {
+	apiToken := userModal.CreatedAPIToken{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &apiToken.APIToken)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	apiToken.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = apiToken.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.CreateAPIToken(ctx.Request.Context(), &apiToken)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusCreated, apiToken)
+}
+
+// getAPITokens will fetch all personal access tokens of the user.
+func (c *apiTokenController) getAPITokens(ctx *gin.Context) {
+	apiTokens := []userModal.APITokenDTO{}
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetAPITokens(ctx.Request.Context(), &apiTokens, userID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, apiTokens)
+}
+
+// deleteAPIToken will revoke specified personal access token of the user.
+func (c *apiTokenController) deleteAPIToken(ctx *gin.Context) {
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tokenID, err := parser.GetUUID("tokenID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.DeleteAPIToken(ctx.Request.Context(), userID, tokenID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, nil)
+}
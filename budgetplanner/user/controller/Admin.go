@@ -0,0 +1,294 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	auditModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/audit"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// AdminController provides methods for admins to manage users of every tenant.
+type AdminController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	searchUsers(ctx *gin.Context)
+	getUser(ctx *gin.Context)
+	disableUser(ctx *gin.Context)
+	enableUser(ctx *gin.Context)
+	changeRole(ctx *gin.Context)
+	forcePasswordReset(ctx *gin.Context)
+	impersonate(ctx *gin.Context)
+	getActions(ctx *gin.Context)
+	getStats(ctx *gin.Context)
+}
+
+// adminController.
+type adminController struct {
+	service service.AdminService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewAdminController create new AdminController
+func NewAdminController(ser service.AdminService, log log.Logger,
+	auth *security.Authentication) AdminController {
+	return &adminController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for admin controller. Admin routes are not scoped to a tenant.
+func (c *adminController) RegisterRoutes(router *gin.RouterGroup) {
+	guarded := router.Group("/admin", c.auth.Middleware(), c.auth.RequireRole(userModal.RoleAdmin),
+		c.auth.AcrossTenants())
+
+	guarded.GET("/users", c.searchUsers)
+	guarded.GET("/users/:userID", c.getUser)
+	guarded.POST("/users/:userID/disable", c.disableUser)
+	guarded.POST("/users/:userID/enable", c.enableUser)
+	guarded.PUT("/users/:userID/role", c.changeRole)
+	guarded.POST("/users/:userID/password-reset", c.forcePasswordReset)
+	guarded.POST("/users/:userID/impersonate", c.impersonate)
+	guarded.GET("/actions", c.getActions)
+	guarded.GET("/stats", c.getStats)
+}
+
+// searchUsers will fetch users matching search, tenantID, role and status query params.
+func (c *adminController) searchUsers(ctx *gin.Context) {
+	users := []userModal.UserDTO{}
+	parser := web.NewParser(ctx)
+
+	var totalCount int64
+
+	err := c.service.SearchUsers(ctx.Request.Context(), &users, &totalCount, parser)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSONWithXTotalCount(ctx, http.StatusOK, int(totalCount), users)
+}
+
+// getUser will fetch details of the user.
+func (c *adminController) getUser(ctx *gin.Context) {
+	user := userModal.UserDTO{}
+	parser := web.NewParser(ctx)
+	var err error
+
+	user.ID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetUser(ctx.Request.Context(), &user)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, user)
+}
+
+// disableUser will block the user from logging in.
+func (c *adminController) disableUser(ctx *gin.Context) {
+	status := userModal.UserStatusChange{}
+
+	action, ok := c.newAction(ctx)
+	if !ok {
+		return
+	}
+
+	err := web.UnmarshalJSON(ctx.Request, &status)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = status.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.DisableUser(ctx.Request.Context(), action, &status)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// enableUser will allow disabled user to login again.
+func (c *adminController) enableUser(ctx *gin.Context) {
+	status := userModal.UserStatusChange{}
+
+	action, ok := c.newAction(ctx)
+	if !ok {
+		return
+	}
+
+	err := web.UnmarshalJSON(ctx.Request, &status)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = status.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.EnableUser(ctx.Request.Context(), action, &status)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// changeRole will change role of the user.
+func (c *adminController) changeRole(ctx *gin.Context) {
+	role := userModal.UserRole{}
+
+	action, ok := c.newAction(ctx)
+	if !ok {
+		return
+	}
+
+	err := web.UnmarshalJSON(ctx.Request, &role)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	role.UserID = action.TargetUserID
+
+	err = role.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.ChangeRole(ctx.Request.Context(), action, &role)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// forcePasswordReset will make the user reset their password before logging in again.
+func (c *adminController) forcePasswordReset(ctx *gin.Context) {
+	action, ok := c.newAction(ctx)
+	if !ok {
+		return
+	}
+
+	err := c.service.ForcePasswordReset(ctx.Request.Context(), action)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// impersonate will issue access token of the user to the admin.
+func (c *adminController) impersonate(ctx *gin.Context) {
+	auth := userModal.Authentication{}
+
+	action, ok := c.newAction(ctx)
+	if !ok {
+		return
+	}
+
+	err := c.service.Impersonate(ctx.Request.Context(), action, &auth)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, auth)
+}
+
+// getActions will fetch actions performed by admins filtered by adminID and targetUserID query params.
+func (c *adminController) getActions(ctx *gin.Context) {
+	actions := []auditModel.AdminActionDTO{}
+	parser := web.NewParser(ctx)
+
+	var totalCount int64
+
+	err := c.service.GetActions(ctx.Request.Context(), &actions, &totalCount, parser)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSONWithXTotalCount(ctx, http.StatusOK, int(totalCount), actions)
+}
+
+// getStats will fetch system wide overview.
+func (c *adminController) getStats(ctx *gin.Context) {
+	stats := userModal.AdminStats{}
+
+	err := c.service.GetStats(ctx.Request.Context(), &stats)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, stats)
+}
+
+// newAction will create admin action on user of the :userID path param by admin making the request.
+// It responds with error and returns false if the request does not specify them.
+func (c *adminController) newAction(ctx *gin.Context) (*auditModel.AdminAction, bool) {
+	parser := web.NewParser(ctx)
+
+	adminID, err := c.auth.ExtractUserID(ctx)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusUnauthorized, err.Error())
+		return nil, false
+	}
+
+	targetUserID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	return &auditModel.AdminAction{
+		AdminID:      adminID,
+		TargetUserID: targetUserID,
+		IPAddress:    ctx.ClientIP(),
+	}, true
+}
@@ -43,8 +43,8 @@ func (c *authenticationController) RegisterRoutes(router *gin.RouterGroup) {
 	router.POST("/register", c.register)
 	router.POST("/login", c.login)
 
-	guarded := router.Group("/users", c.auth.Middleware())
-	guarded.PUT("/:userID", c.updateUser)
+	guarded := router.Group("/users", c.auth.Middleware(), c.auth.AuthorizeUser())
+	guarded.PUT("/:userID", c.auth.RejectImpersonation(), c.updateUser)
 	guarded.GET("/:userID", c.getUser)
 }
 
@@ -52,7 +52,10 @@ func (c *authenticationController) RegisterRoutes(router *gin.RouterGroup) {
 func (c *authenticationController) register(ctx *gin.Context) {
 	// parser := web.NewParser(ctx)
 	user := userModal.User{}
-	auth := userModal.Authentication{}
+	auth := userModal.Authentication{
+		UserAgent: ctx.Request.UserAgent(),
+		IPAddress: ctx.ClientIP(),
+	}
 
 	err := web.UnmarshalJSON(ctx.Request, &user)
 	if err != nil {
@@ -61,14 +64,14 @@ func (c *authenticationController) register(ctx *gin.Context) {
 		return
 	}
 
-	err = user.ValidateRegistration()
+	err = user.ValidateRegistration(c.auth.PasswordPolicy())
 	if err != nil {
 		c.log.Error(err)
 		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	err = c.service.Register(&user, &auth)
+	err = c.service.Register(ctx.Request.Context(), &user, &auth)
 	if err != nil {
 		c.log.Error(err)
 		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
@@ -81,7 +84,10 @@ func (c *authenticationController) register(ctx *gin.Context) {
 // login will verify user details and login into the system
 func (c *authenticationController) login(ctx *gin.Context) {
 	login := userModal.Login{}
-	auth := userModal.Authentication{}
+	auth := userModal.Authentication{
+		UserAgent: ctx.Request.UserAgent(),
+		IPAddress: ctx.ClientIP(),
+	}
 
 	err := web.UnmarshalJSON(ctx.Request, &login)
 	if err != nil {
@@ -97,7 +103,7 @@ func (c *authenticationController) login(ctx *gin.Context) {
 		return
 	}
 
-	err = c.service.Login(&login, &auth)
+	err = c.service.Login(ctx.Request.Context(), &login, &auth)
 	if err != nil {
 		c.log.Error(err)
 		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
@@ -140,7 +146,7 @@ func (c *authenticationController) updateUser(ctx *gin.Context) {
 		return
 	}
 
-	err = c.service.UpdateUser(&user)
+	err = c.service.UpdateUser(ctx.Request.Context(), &user)
 	if err != nil {
 		c.log.Error(err)
 		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
@@ -163,7 +169,7 @@ func (c *authenticationController) getUser(ctx *gin.Context) {
 		return
 	}
 
-	err = c.service.GetUser(&user)
+	err = c.service.GetUser(ctx.Request.Context(), &user)
 	if err != nil {
 		c.log.Error(err)
 		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// KeySetController provides public keys used to verify tokens.
+type KeySetController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	getKeySet(ctx *gin.Context)
+}
+
+// keySetController.
+type keySetController struct {
+	log  log.Logger
+	auth *security.Authentication
+}
+
+// NewKeySetController create new KeySetController
+func NewKeySetController(log log.Logger, auth *security.Authentication) KeySetController {
+	return &keySetController{
+		log:  log,
+		auth: auth,
+	}
+}
+
+// RegisterRoutes will register routes for key set controller.
+func (c *keySetController) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/jwks.json", c.getKeySet)
+}
+
+// getKeySet will return JWKS containing public keys of every active signing key.
+func (c *keySetController) getKeySet(ctx *gin.Context) {
+	// keys can be cached by verifiers, new keys are added well before they are used for signing.
+	ctx.Header("Cache-Control", "public, max-age=300")
+	web.RespondJSON(ctx, http.StatusOK, c.auth.KeySet())
+}
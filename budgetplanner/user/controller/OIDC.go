@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// OIDCController provides methods to login with OpenID Connect providers.
+type OIDCController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	getProviders(ctx *gin.Context)
+	authorize(ctx *gin.Context)
+	callback(ctx *gin.Context)
+}
+
+// oidcController.
+type oidcController struct {
+	service service.OIDCService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewOIDCController create new OIDCController
+func NewOIDCController(ser service.OIDCService, log log.Logger,
+	auth *security.Authentication) OIDCController {
+	return &oidcController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for oidc controller.
+func (c *oidcController) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/oidc/providers", c.getProviders)
+	router.GET("/oidc/:provider/authorize", c.authorize)
+	router.POST("/oidc/:provider/callback", c.callback)
+}
+
+// getProviders will return names of providers with which user can login.
+func (c *oidcController) getProviders(ctx *gin.Context) {
+	web.RespondJSON(ctx, http.StatusOK, c.service.GetProviders())
+}
+
+// authorize will return url of provider to which user should be sent for login.
+func (c *oidcController) authorize(ctx *gin.Context) {
+	authorization := userModal.OIDCAuthorization{}
+
+	err := c.service.Authorize(ctx.Request.Context(), ctx.Param("provider"), &authorization)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, authorization)
+}
+
+// callback will login user with code and state with which provider redirected back.
+func (c *oidcController) callback(ctx *gin.Context) {
+	callback := userModal.OIDCCallback{}
+	auth := userModal.Authentication{
+		UserAgent: ctx.Request.UserAgent(),
+		IPAddress: ctx.ClientIP(),
+	}
+
+	err := web.UnmarshalJSON(ctx.Request, &callback)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = callback.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.Callback(ctx.Request.Context(), ctx.Param("provider"), &callback, &auth)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, auth)
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// PasswordController provides methods to reset and change password.
+type PasswordController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	forgotPassword(ctx *gin.Context)
+	resetPassword(ctx *gin.Context)
+	changePassword(ctx *gin.Context)
+}
+
+// passwordController.
+type passwordController struct {
+	service service.PasswordService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewPasswordController create new PasswordController
+func NewPasswordController(ser service.PasswordService, log log.Logger,
+	auth *security.Authentication) PasswordController {
+	return &passwordController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for password controller.
+func (c *passwordController) RegisterRoutes(router *gin.RouterGroup) {
+
+	router.POST("/password/forgot", c.forgotPassword)
+	router.POST("/password/reset", c.resetPassword)
+
+	guarded := router.Group("/users", c.auth.Middleware(), c.auth.AuthorizeUser(), c.auth.RejectImpersonation())
+	guarded.PUT("/:userID/password", c.changePassword)
+}
+
+// forgotPassword will email password reset link to the user.
+func (c *passwordController) forgotPassword(ctx *gin.Context) {
+	forgot := userModal.ForgotPassword{}
+
+	err := web.UnmarshalJSON(ctx.Request, &forgot)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = forgot.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.ForgotPassword(ctx.Request.Context(), &forgot)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, nil)
+}
+
+// resetPassword will set new password using token sent in email.
+func (c *passwordController) resetPassword(ctx *gin.Context) {
+	reset := userModal.ResetPassword{}
+
+	err := web.UnmarshalJSON(ctx.Request, &reset)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = reset.Validate(c.auth.PasswordPolicy())
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.ResetPassword(ctx.Request.Context(), &reset)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, nil)
+}
+
+// changePassword will change password of the user.
+func (c *passwordController) changePassword(ctx *gin.Context) {
+	change := userModal.ChangePassword{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &change)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = change.Validate(c.auth.PasswordPolicy())
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.ChangePassword(ctx.Request.Context(), userID, &change)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// PreferenceController provides methods to manage preferences of user.
+type PreferenceController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	getPreference(ctx *gin.Context)
+	updatePreference(ctx *gin.Context)
+}
+
+// preferenceController.
+type preferenceController struct {
+	service service.PreferenceService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewPreferenceController create new PreferenceController
+func NewPreferenceController(ser service.PreferenceService, log log.Logger,
+	auth *security.Authentication) PreferenceController {
+	return &preferenceController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for preference controller.
+func (c *preferenceController) RegisterRoutes(router *gin.RouterGroup) {
+	guarded := router.Group("/users", c.auth.Middleware(), c.auth.AuthorizeUser())
+	guarded.GET("/:userID/preferences", c.getPreference)
+	guarded.PUT("/:userID/preferences", c.updatePreference)
+}
+
+// getPreference will fetch preferences of the user.
+func (c *preferenceController) getPreference(ctx *gin.Context) {
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	preference, err := c.service.GetPreference(ctx.Request.Context(), userID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, preference)
+}
+
+// updatePreference will replace preferences of the user.
+func (c *preferenceController) updatePreference(ctx *gin.Context) {
+	preference := userModal.Preference{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &preference)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	preference.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = preference.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.UpdatePreference(ctx.Request.Context(), &preference)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, preference)
+}
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// multipartOverhead is allowed size of multipart body apart from the uploaded file.
+const multipartOverhead = 64 << 10
+
+// ProfileImageController provides methods to upload profile image of user.
+type ProfileImageController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	uploadProfileImage(ctx *gin.Context)
+	getProfileImage(ctx *gin.Context)
+	deleteProfileImage(ctx *gin.Context)
+}
+
+// profileImageController.
+type profileImageController struct {
+	service service.ProfileImageService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewProfileImageController create new ProfileImageController
+func NewProfileImageController(ser service.ProfileImageService, log log.Logger,
+	auth *security.Authentication) ProfileImageController {
+	return &profileImageController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for profile image controller.
+func (c *profileImageController) RegisterRoutes(router *gin.RouterGroup) {
+	guarded := router.Group("/users", c.auth.Middleware(), c.auth.AuthorizeUser())
+	guarded.PUT("/:userID/profile-image", c.uploadProfileImage)
+	guarded.GET("/:userID/profile-image", c.getProfileImage)
+	guarded.DELETE("/:userID/profile-image", c.deleteProfileImage)
+}
+
+// uploadProfileImage will replace profile image of the user with image of multipart field image.
+func (c *profileImageController) uploadProfileImage(ctx *gin.Context) {
+	upload := userModal.ProfileImageUpload{}
+	parser := web.NewParser(ctx)
+	var err error
+
+	upload.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	maxSize := c.service.MaxUploadSize()
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxSize+multipartOverhead)
+
+	header, err := ctx.FormFile("image")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest,
+			fmt.Sprintf("Image of at most %d bytes must be uploaded as multipart field image", maxSize))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondError(ctx, errors.NewDataReadWriteError(err))
+		return
+	}
+	defer file.Close()
+
+	upload.Data, err = io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		c.log.Error(err)
+		web.RespondError(ctx, errors.NewDataReadWriteError(err))
+		return
+	}
+
+	err = c.service.UploadProfileImage(ctx.Request.Context(), &upload)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// getProfileImage will download profile image of the user, size query param can be original, medium or small.
+func (c *profileImageController) getProfileImage(ctx *gin.Context) {
+	file := userModal.ProfileImageFile{}
+	parser := web.NewParser(ctx)
+	var err error
+
+	file.UserID, err = parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	file.Variant = parser.Form.Get("size")
+
+	err = file.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetProfileImage(ctx.Request.Context(), &file)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+	defer file.Content.Close()
+
+	ctx.DataFromReader(http.StatusOK, -1, file.ContentType, file.Content, map[string]string{
+		"Cache-Control":          "private, max-age=86400",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// deleteProfileImage will remove profile image of the user.
+func (c *profileImageController) deleteProfileImage(ctx *gin.Context) {
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.DeleteProfileImage(ctx.Request.Context(), userID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
@@ -0,0 +1,274 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// TenantController provides methods to sign up organizations and manage their users.
+type TenantController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	createTenant(ctx *gin.Context)
+	getTenant(ctx *gin.Context)
+	updateTenant(ctx *gin.Context)
+	getUsers(ctx *gin.Context)
+	addUser(ctx *gin.Context)
+	updateUserRole(ctx *gin.Context)
+	removeUser(ctx *gin.Context)
+}
+
+// tenantController.
+type tenantController struct {
+	service service.TenantService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewTenantController create new TenantController
+func NewTenantController(ser service.TenantService, log log.Logger,
+	auth *security.Authentication) TenantController {
+	return &tenantController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for tenant controller. Only admins of the tenant can manage it.
+func (c *tenantController) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/tenants", c.createTenant)
+
+	guarded := router.Group("/tenants", c.auth.Middleware(), c.auth.RejectImpersonation(), c.auth.AuthorizeTenantAdmin())
+	guarded.GET("/:tenantID", c.getTenant)
+	guarded.PUT("/:tenantID", c.updateTenant)
+	guarded.GET("/:tenantID/users", c.getUsers)
+	guarded.POST("/:tenantID/users", c.addUser)
+	guarded.PUT("/:tenantID/users/:memberID", c.updateUserRole)
+	guarded.DELETE("/:tenantID/users/:memberID", c.removeUser)
+}
+
+// createTenant will sign up new organization along with its first admin.
+func (c *tenantController) createTenant(ctx *gin.Context) {
+	registration := userModal.TenantRegistration{}
+	auth := userModal.Authentication{
+		UserAgent: ctx.Request.UserAgent(),
+		IPAddress: ctx.ClientIP(),
+	}
+
+	err := web.UnmarshalJSON(ctx.Request, &registration)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = registration.Validate(c.auth.PasswordPolicy())
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.CreateTenant(ctx.Request.Context(), &registration, &auth)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusCreated, auth)
+}
+
+// getTenant will fetch details of the tenant.
+func (c *tenantController) getTenant(ctx *gin.Context) {
+	tenant := userModal.TenantDTO{}
+	parser := web.NewParser(ctx)
+	var err error
+
+	tenant.ID, err = parser.GetTenantID()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetTenant(ctx.Request.Context(), &tenant)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, tenant)
+}
+
+// updateTenant will update details of the tenant.
+func (c *tenantController) updateTenant(ctx *gin.Context) {
+	tenant := userModal.Tenant{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &tenant)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tenant.ID, err = parser.GetTenantID()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = tenant.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.UpdateTenant(ctx.Request.Context(), &tenant)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// getUsers will fetch all users of the tenant.
+func (c *tenantController) getUsers(ctx *gin.Context) {
+	users := []userModal.UserDTO{}
+	parser := web.NewParser(ctx)
+
+	tenantID, err := parser.GetTenantID()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetUsers(ctx.Request.Context(), &users, tenantID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, users)
+}
+
+// addUser will create new user in the tenant.
+func (c *tenantController) addUser(ctx *gin.Context) {
+	user := userModal.User{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &user)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tenantID, err := parser.GetTenantID()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = user.ValidateRegistration(c.auth.PasswordPolicy())
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.AddUser(ctx.Request.Context(), tenantID, &user)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusCreated, nil)
+}
+
+// updateUserRole will make user admin of the tenant or revoke it.
+func (c *tenantController) updateUserRole(ctx *gin.Context) {
+	role := userModal.TenantUserRole{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &role)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	role.TenantID, err = parser.GetTenantID()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	role.UserID, err = parser.GetUUID("memberID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.UpdateUserRole(ctx.Request.Context(), &role)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
+
+// removeUser will delete user from the tenant.
+func (c *tenantController) removeUser(ctx *gin.Context) {
+	parser := web.NewParser(ctx)
+
+	tenantID, err := parser.GetTenantID()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, err := parser.GetUUID("memberID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	adminID, err := c.auth.ExtractUserID(ctx)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	err = c.service.RemoveUser(ctx.Request.Context(), tenantID, adminID, userID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusAccepted, nil)
+}
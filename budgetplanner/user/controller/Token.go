@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// TokenController provides methods to refresh and revoke tokens.
+type TokenController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	refreshTokens(ctx *gin.Context)
+	logout(ctx *gin.Context)
+	getSessions(ctx *gin.Context)
+	revokeSession(ctx *gin.Context)
+	revokeAllSessions(ctx *gin.Context)
+}
+
+// tokenController.
+type tokenController struct {
+	service service.TokenService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewTokenController create new TokenController
+func NewTokenController(ser service.TokenService, log log.Logger,
+	auth *security.Authentication) TokenController {
+	return &tokenController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for token controller.
+func (c *tokenController) RegisterRoutes(router *gin.RouterGroup) {
+
+	router.POST("/token/refresh", c.refreshTokens)
+
+	guarded := router.Group("/users", c.auth.Middleware(), c.auth.AuthorizeUser())
+	guarded.POST("/:userID/logout", c.logout)
+	guarded.GET("/:userID/sessions", c.auth.RejectImpersonation(), c.getSessions)
+	guarded.DELETE("/:userID/sessions/:sessionID", c.auth.RejectImpersonation(), c.revokeSession)
+	guarded.DELETE("/:userID/sessions", c.auth.RejectImpersonation(), c.revokeAllSessions)
+}
+
+// refreshTokens will exchange refresh token for new access token and refresh token.
+func (c *tokenController) refreshTokens(ctx *gin.Context) {
+	refresh := userModal.Refresh{}
+	auth := userModal.Authentication{
+		UserAgent: ctx.Request.UserAgent(),
+		IPAddress: ctx.ClientIP(),
+	}
+
+	err := web.UnmarshalJSON(ctx.Request, &refresh)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = refresh.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.RefreshTokens(ctx.Request.Context(), &refresh, &auth)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, auth)
+}
+
+// logout will revoke current access token and its session.
+func (c *tokenController) logout(ctx *gin.Context) {
+	claims, err := c.auth.ExtractClaims(ctx)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.Logout(ctx.Request.Context(), &claims)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, nil)
+}
+
+// getSessions will fetch active sessions of the user.
+func (c *tokenController) getSessions(ctx *gin.Context) {
+	sessions := []userModal.SessionDTO{}
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	claims, err := c.auth.ExtractClaims(ctx)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.GetSessions(ctx.Request.Context(), &sessions, userID, claims.SessionID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, sessions)
+}
+
+// revokeSession will terminate specified session of the user.
+func (c *tokenController) revokeSession(ctx *gin.Context) {
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sessionID, err := parser.GetUUID("sessionID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.RevokeSession(ctx.Request.Context(), userID, sessionID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, nil)
+}
+
+// revokeAllSessions will revoke all active sessions of the user.
+func (c *tokenController) revokeAllSessions(ctx *gin.Context) {
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.RevokeAllSessions(ctx.Request.Context(), userID)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, nil)
+}
@@ -0,0 +1,215 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// TwoFactorController provides methods to manage two factor authentication.
+type TwoFactorController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	login(ctx *gin.Context)
+	enroll(ctx *gin.Context)
+	confirm(ctx *gin.Context)
+	disable(ctx *gin.Context)
+	regenerateRecoveryCodes(ctx *gin.Context)
+}
+
+// twoFactorController.
+type twoFactorController struct {
+	service service.TwoFactorService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewTwoFactorController create new TwoFactorController
+func NewTwoFactorController(ser service.TwoFactorService, log log.Logger,
+	auth *security.Authentication) TwoFactorController {
+	return &twoFactorController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for two factor controller.
+func (c *twoFactorController) RegisterRoutes(router *gin.RouterGroup) {
+
+	router.POST("/login/two-factor", c.login)
+
+	guarded := router.Group("/users", c.auth.Middleware(), c.auth.AuthorizeUser(), c.auth.RejectImpersonation())
+	guarded.POST("/:userID/two-factor", c.enroll)
+	guarded.POST("/:userID/two-factor/confirm", c.confirm)
+	guarded.DELETE("/:userID/two-factor", c.disable)
+	guarded.POST("/:userID/two-factor/recovery-codes", c.regenerateRecoveryCodes)
+}
+
+// login will issue tokens once two factor code is verified.
+func (c *twoFactorController) login(ctx *gin.Context) {
+	login := userModal.TwoFactorLogin{}
+	auth := userModal.Authentication{
+		UserAgent: ctx.Request.UserAgent(),
+		IPAddress: ctx.ClientIP(),
+	}
+
+	err := web.UnmarshalJSON(ctx.Request, &login)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = login.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.Login(ctx.Request.Context(), &login, &auth)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, auth)
+}
+
+// enroll will generate new TOTP secret for the user.
+func (c *twoFactorController) enroll(ctx *gin.Context) {
+	enrollment := userModal.TwoFactorEnrollment{}
+	parser := web.NewParser(ctx)
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.Enroll(ctx.Request.Context(), userID, &enrollment)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, enrollment)
+}
+
+// confirm will enable two factor authentication and return recovery codes.
+func (c *twoFactorController) confirm(ctx *gin.Context) {
+	code := userModal.TwoFactorCode{}
+	recoveryCodes := userModal.RecoveryCodes{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &code)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = code.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.Confirm(ctx.Request.Context(), userID, &code, &recoveryCodes)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, recoveryCodes)
+}
+
+// disable will turn off two factor authentication.
+func (c *twoFactorController) disable(ctx *gin.Context) {
+	code := userModal.TwoFactorCode{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &code)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = code.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.Disable(ctx.Request.Context(), userID, &code)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, nil)
+}
+
+// regenerateRecoveryCodes will replace recovery codes of the user.
+func (c *twoFactorController) regenerateRecoveryCodes(ctx *gin.Context) {
+	code := userModal.TwoFactorCode{}
+	recoveryCodes := userModal.RecoveryCodes{}
+	parser := web.NewParser(ctx)
+
+	err := web.UnmarshalJSON(ctx.Request, &code)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, err := parser.GetUUID("userID")
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = code.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.RegenerateRecoveryCodes(ctx.Request.Context(), userID, &code, &recoveryCodes)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, recoveryCodes)
+}
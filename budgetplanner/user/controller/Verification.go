@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+)
+
+// VerificationController provides methods to verify email of user.
+type VerificationController interface {
+	RegisterRoutes(router *gin.RouterGroup)
+	verifyEmail(ctx *gin.Context)
+	resendVerificationEmail(ctx *gin.Context)
+}
+
+// verificationController.
+type verificationController struct {
+	service service.VerificationService
+	log     log.Logger
+	auth    *security.Authentication
+}
+
+// NewVerificationController create new VerificationController
+func NewVerificationController(ser service.VerificationService, log log.Logger,
+	auth *security.Authentication) VerificationController {
+	return &verificationController{
+		service: ser,
+		log:     log,
+		auth:    auth,
+	}
+}
+
+// RegisterRoutes will register routes for verification controller.
+func (c *verificationController) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/verify-email", c.verifyEmail)
+	router.POST("/verify-email/resend", c.resendVerificationEmail)
+}
+
+// verifyEmail will mark email of the user as verified.
+func (c *verificationController) verifyEmail(ctx *gin.Context) {
+	verification := userModal.EmailVerification{}
+
+	err := web.UnmarshalJSON(ctx.Request, &verification)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = verification.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.VerifyEmail(ctx.Request.Context(), &verification)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, nil)
+}
+
+// resendVerificationEmail will send verification email again.
+func (c *verificationController) resendVerificationEmail(ctx *gin.Context) {
+	request := userModal.VerificationRequest{}
+
+	err := web.UnmarshalJSON(ctx.Request, &request)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = request.Validate()
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = c.service.ResendVerificationEmail(ctx.Request.Context(), &request)
+	if err != nil {
+		c.log.Error(err)
+		web.RespondErrorMessage(ctx, http.StatusTooManyRequests, err.Error())
+		return
+	}
+
+	web.RespondJSON(ctx, http.StatusOK, nil)
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"gorm.io/gorm"
+)
+
+// apiTokenPrefixLength is number of characters of token stored to help user identify it.
+const apiTokenPrefixLength = 12
+
+// APITokenService consist of all methods APITokenService should implement.
+type APITokenService interface {
+	CreateAPIToken(ctx context.Context, apiToken *userModal.CreatedAPIToken) error
+	GetAPITokens(ctx context.Context, apiTokens *[]userModal.APITokenDTO, userID uuid.UUID) error
+	DeleteAPIToken(ctx context.Context, userID, tokenID uuid.UUID) error
+}
+
+// apiTokenService provides methods to manage personal access tokens of user.
+type apiTokenService struct {
+	db   *gorm.DB
+	repo repository.Repository
+	auth *security.Authentication
+}
+
+// NewAPITokenService returns new instance of APITokenService.
+func NewAPITokenService(db *gorm.DB, repo repository.Repository, auth *security.Authentication) APITokenService {
+	return &apiTokenService{
+		db:   db,
+		repo: repo,
+		auth: auth,
+	}
+}
+
+// CreateAPIToken will create new personal access token for the user. Only hash of the token
+// is stored, token is returned once and can't be fetched again.
+func (ser *apiTokenService) CreateAPIToken(ctx context.Context, apiToken *userModal.CreatedAPIToken) error {
+
+	err := ser.validateUserID(ctx, apiToken.UserID)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	token, _, err := ser.auth.GenerateRandomToken()
+	if err != nil {
+		return err
+	}
+
+	apiToken.Token = userModal.APITokenPrefix + token
+	apiToken.TokenHash = security.HashToken(apiToken.Token)
+	apiToken.Prefix = apiToken.Token[:apiTokenPrefixLength]
+
+	err = ser.repo.Add(uow, &apiToken.APIToken)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// GetAPITokens will fetch all active personal access tokens of the user.
+func (ser *apiTokenService) GetAPITokens(ctx context.Context, apiTokens *[]userModal.APITokenDTO,
+	userID uuid.UUID) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err := ser.repo.GetAllInOrder(uow, apiTokens, "api_tokens.`created_at` DESC",
+		repository.Filter("api_tokens.`user_id` = ? AND api_tokens.`deleted_at` IS NULL", userID))
+	if err != nil {
+		return err
+	}
+
+	for index := range *apiTokens {
+		(*apiTokens)[index].ScopeList = userModal.SplitScopes((*apiTokens)[index].Scopes)
+	}
+
+	return uow.Commit()
+}
+
+// DeleteAPIToken will revoke specified personal access token of the user.
+func (ser *apiTokenService) DeleteAPIToken(ctx context.Context, userID, tokenID uuid.UUID) error {
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), userModal.APIToken{},
+		repository.Filter("api_tokens.`id` = ? AND api_tokens.`user_id` = ? AND api_tokens.`deleted_at` IS NULL",
+			tokenID, userID))
+	if err != nil {
+		return err
+	}
+
+	if !exist {
+		return errors.NewValidationError("Token not found")
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.APIToken{}, map[string]interface{}{
+		"DeletedAt": time.Now(),
+	}, repository.Filter("api_tokens.`id` = ? AND api_tokens.`user_id` = ?", tokenID, userID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// validateUserID will check if user exists.
+func (ser *apiTokenService) validateUserID(ctx context.Context, userID uuid.UUID) error {
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), userModal.User{},
+		repository.Filter("users.`id` = ? AND users.`deleted_at` IS NULL", userID))
+	if err != nil {
+		return err
+	}
+
+	if !exist {
+		return errors.NewValidationError("User not found")
+	}
+	return nil
+}
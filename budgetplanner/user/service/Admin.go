@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	accountModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/account"
+	auditModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/audit"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
+	envelopModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/envelop"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/web"
+	"gorm.io/gorm"
+)
+
+// AdminService consist of all methods AdminService should implement.
+// Every action changing a user is recorded in admin actions.
+type AdminService interface {
+	SearchUsers(ctx context.Context, users *[]userModal.UserDTO, totalCount *int64, parser *web.Parser) error
+	GetUser(ctx context.Context, user *userModal.UserDTO) error
+	DisableUser(ctx context.Context, action *auditModel.AdminAction, status *userModal.UserStatusChange) error
+	EnableUser(ctx context.Context, action *auditModel.AdminAction, status *userModal.UserStatusChange) error
+	ChangeRole(ctx context.Context, action *auditModel.AdminAction, role *userModal.UserRole) error
+	ForcePasswordReset(ctx context.Context, action *auditModel.AdminAction) error
+	Impersonate(ctx context.Context, action *auditModel.AdminAction, auth *userModal.Authentication) error
+	GetActions(ctx context.Context, actions *[]auditModel.AdminActionDTO, totalCount *int64, parser *web.Parser) error
+	GetStats(ctx context.Context, stats *userModal.AdminStats) error
+}
+
+// adminService provides methods for admins to manage users of every tenant.
+type adminService struct {
+	db              *gorm.DB
+	repo            repository.Repository
+	auth            *security.Authentication
+	tokenService    TokenService
+	passwordService PasswordService
+}
+
+// NewAdminService returns new instance of AdminService.
+func NewAdminService(db *gorm.DB, repo repository.Repository, auth *security.Authentication,
+	tokenService TokenService, passwordService PasswordService) AdminService {
+	return &adminService{
+		db:              db,
+		repo:            repo,
+		auth:            auth,
+		tokenService:    tokenService,
+		passwordService: passwordService,
+	}
+}
+
+// SearchUsers will fetch users matching the search query params.
+func (ser *adminService) SearchUsers(ctx context.Context, users *[]userModal.UserDTO, totalCount *int64,
+	parser *web.Parser) error {
+
+	limit, offset := parser.ParseLimitAndOffset()
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err := ser.repo.GetAllInOrder(uow, users, "users.`created_at` DESC",
+		ser.addSearchQueries(parser.Form), repository.Filter("users.`deleted_at` IS NULL"),
+		repository.Paginate(limit, offset, totalCount))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// GetUser will fetch details of the user.
+func (ser *adminService) GetUser(ctx context.Context, user *userModal.UserDTO) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err := ser.repo.GetRecord(uow, user, repository.Filter("users.`id` = ? AND users.`deleted_at` IS NULL", user.ID))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewValidationError("User not found")
+		}
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// DisableUser will block the user from logging in and end all their sessions.
+func (ser *adminService) DisableUser(ctx context.Context, action *auditModel.AdminAction,
+	status *userModal.UserStatusChange) error {
+
+	if action.AdminID == action.TargetUserID {
+		return errors.NewValidationError("You cannot disable yourself")
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user, err := ser.getUser(uow, action.TargetUserID)
+	if err != nil {
+		return err
+	}
+
+	if user.DisabledAt != nil {
+		return errors.NewValidationError("User is already disabled")
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.User{}, map[string]interface{}{
+		"DisabledAt": time.Now(),
+	}, repository.Filter("users.`id` = ?", user.ID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.tokenService.RevokeUserSessions(uow, user.ID)
+	if err != nil {
+		return err
+	}
+
+	err = ser.recordAction(uow, action, auditModel.AdminActionDisable, status.Reason)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// EnableUser will allow disabled user to login again.
+func (ser *adminService) EnableUser(ctx context.Context, action *auditModel.AdminAction,
+	status *userModal.UserStatusChange) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user, err := ser.getUser(uow, action.TargetUserID)
+	if err != nil {
+		return err
+	}
+
+	if user.DisabledAt == nil {
+		return errors.NewValidationError("User is not disabled")
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.User{}, map[string]interface{}{
+		"DisabledAt": nil,
+	}, repository.Filter("users.`id` = ?", user.ID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.recordAction(uow, action, auditModel.AdminActionEnable, status.Reason)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// ChangeRole will change role of the user. Admin can't change their own role,
+// so the system is never left without an admin.
+func (ser *adminService) ChangeRole(ctx context.Context, action *auditModel.AdminAction,
+	role *userModal.UserRole) error {
+
+	if action.AdminID == action.TargetUserID {
+		return errors.NewValidationError("You cannot change your own role")
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user, err := ser.getUser(uow, action.TargetUserID)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == role.Role {
+		return nil
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.User{}, map[string]interface{}{
+		"Role": role.Role,
+	}, repository.Filter("users.`id` = ?", user.ID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.recordAction(uow, action, auditModel.AdminActionChangeRole, user.Role+" -> "+role.Role)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// ForcePasswordReset will remove password of the user, end all their sessions and email them reset link.
+func (ser *adminService) ForcePasswordReset(ctx context.Context, action *auditModel.AdminAction) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err := ser.passwordService.ForcePasswordReset(uow, action.TargetUserID)
+	if err != nil {
+		return err
+	}
+
+	err = ser.recordAction(uow, action, auditModel.AdminActionPasswordReset, "")
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// Impersonate will issue short-lived access token of the user to the admin. Admins and disabled
+// users can't be impersonated. Every request made with the token is logged along with the admin.
+func (ser *adminService) Impersonate(ctx context.Context, action *auditModel.AdminAction,
+	auth *userModal.Authentication) error {
+
+	if action.AdminID == action.TargetUserID {
+		return errors.NewValidationError("You cannot impersonate yourself")
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user, err := ser.getUser(uow, action.TargetUserID)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == userModal.RoleAdmin {
+		return errors.NewValidationError("Admins cannot be impersonated")
+	}
+
+	if user.DisabledAt != nil {
+		return errors.NewValidationError("Disabled users cannot be impersonated")
+	}
+
+	auth.UserID = user.ID
+	auth.Name = user.Name
+	auth.Email = user.Email
+
+	err = ser.auth.GenerateImpersonationToken(auth, action.AdminID)
+	if err != nil {
+		return err
+	}
+
+	err = ser.recordAction(uow, action, auditModel.AdminActionImpersonate, "")
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// GetActions will fetch actions performed by admins, latest first.
+func (ser *adminService) GetActions(ctx context.Context, actions *[]auditModel.AdminActionDTO, totalCount *int64,
+	parser *web.Parser) error {
+
+	limit, offset := parser.ParseLimitAndOffset()
+
+	var queryProcessors []repository.QueryProcessor
+
+	if adminID := parser.Form.Get("adminID"); len(adminID) > 0 {
+		queryProcessors = append(queryProcessors, repository.Filter("admin_actions.`admin_id` = ?", adminID))
+	}
+
+	if targetUserID := parser.Form.Get("targetUserID"); len(targetUserID) > 0 {
+		queryProcessors = append(queryProcessors,
+			repository.Filter("admin_actions.`target_user_id` = ?", targetUserID))
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err := ser.repo.GetAllInOrder(uow, actions, "admin_actions.`created_at` DESC",
+		repository.CombineQueries(queryProcessors), repository.Paginate(limit, offset, totalCount))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// GetStats will fetch system wide overview of users and their data.
+func (ser *adminService) GetStats(ctx context.Context, stats *userModal.AdminStats) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	counts := []struct {
+		model  interface{}
+		count  *int64
+		filter repository.QueryProcessor
+	}{
+		{userModal.Tenant{}, &stats.Tenants, repository.Filter("tenants.`deleted_at` IS NULL")},
+		{userModal.User{}, &stats.Users, repository.Filter("users.`deleted_at` IS NULL")},
+		{userModal.User{}, &stats.VerifiedUsers,
+			repository.Filter("users.`deleted_at` IS NULL AND users.`is_verified` = 1")},
+		{userModal.User{}, &stats.DisabledUsers,
+			repository.Filter("users.`deleted_at` IS NULL AND users.`disabled_at` IS NOT NULL")},
+		{userModal.User{}, &stats.TwoFactorUsers,
+			repository.Filter("users.`deleted_at` IS NULL AND users.`is_two_factor_enabled` = 1")},
+		{userModal.User{}, &stats.NewUsersLastWeek,
+			repository.Filter("users.`deleted_at` IS NULL AND users.`created_at` >= ?", now.AddDate(0, 0, -7))},
+		{userModal.Session{}, &stats.ActiveSessions,
+			repository.Filter("sessions.`revoked_at` IS NULL AND sessions.`expires_at` > ?", now)},
+		{budgetModel.Budget{}, &stats.Budgets, repository.Filter("budgets.`deleted_at` IS NULL")},
+		{envelopModel.Envelop{}, &stats.Envelops, repository.Filter("envelops.`deleted_at` IS NULL")},
+		{accountModel.Account{}, &stats.Accounts, repository.Filter("accounts.`deleted_at` IS NULL")},
+		{envelopModel.Transaction{}, &stats.Transactions, repository.Filter("transactions.`deleted_at` IS NULL")},
+		{envelopModel.Transaction{}, &stats.TransactionsToday,
+			repository.Filter("transactions.`deleted_at` IS NULL AND transactions.`created_at` >= ?", today)},
+	}
+
+	for _, count := range counts {
+		err := ser.repo.GetCount(uow, count.model, count.count, count.filter)
+		if err != nil {
+			return err
+		}
+	}
+
+	return uow.Commit()
+}
+
+// getUser will fetch user on whom admin is acting.
+func (ser *adminService) getUser(uow *repository.UnitOfWork, userID uuid.UUID) (*userModal.User, error) {
+	user := userModal.User{}
+
+	err := ser.repo.GetRecord(uow, &user, repository.Filter("users.`id` = ? AND users.`deleted_at` IS NULL", userID),
+		repository.Select("`id`, `name`, `email`, `role`, `disabled_at`"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewValidationError("User not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// recordAction will add action performed by admin to admin actions.
+func (ser *adminService) recordAction(uow *repository.UnitOfWork, action *auditModel.AdminAction,
+	actionType, details string) error {
+
+	action.Action = actionType
+	action.Details = details
+	return ser.repo.Add(uow, action)
+}
+
+// addSearchQueries will add filters for search query params of users.
+func (ser *adminService) addSearchQueries(requestForm url.Values) repository.QueryProcessor {
+	var queryProcessors []repository.QueryProcessor
+
+	if search := strings.TrimSpace(requestForm.Get("search")); len(search) > 0 {
+		search = "%" + search + "%"
+		queryProcessors = append(queryProcessors, repository.Filter("(users.`name` LIKE ? OR users.`email` LIKE ?"+
+			" OR users.`username` LIKE ?)", search, search, search))
+	}
+
+	if tenantID := requestForm.Get("tenantID"); len(tenantID) > 0 {
+		queryProcessors = append(queryProcessors, repository.Filter("users.`tenant_id` = ?", tenantID))
+	}
+
+	if role := requestForm.Get("role"); len(role) > 0 {
+		queryProcessors = append(queryProcessors, repository.Filter("users.`role` = ?", role))
+	}
+
+	switch requestForm.Get("status") {
+	case "disabled":
+		queryProcessors = append(queryProcessors, repository.Filter("users.`disabled_at` IS NOT NULL"))
+	case "active":
+		queryProcessors = append(queryProcessors, repository.Filter("users.`disabled_at` IS NULL"))
+	}
+
+	return repository.CombineQueries(queryProcessors)
+}
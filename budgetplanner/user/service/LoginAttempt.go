@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Defaults of login lockout, used when not configured.
+const (
+	defaultLoginMaxAttempts       = 5
+	defaultLoginMaxIPAttempts     = 20
+	defaultLoginLockoutMinutes    = 1
+	defaultLoginMaxLockoutMinutes = 60
+	// loginAttemptWindow is the time after which failures are forgotten.
+	loginAttemptWindow = 24 * time.Hour
+)
+
+// LoginLimiter consist of all methods LoginLimiter should implement.
+type LoginLimiter interface {
+	Check(ctx context.Context, username, ipAddress string) error
+	RecordFailure(ctx context.Context, username, ipAddress string) error
+	RecordSuccess(ctx context.Context, username string) error
+	PurgeExpired() error
+}
+
+// loginLimiter tracks failed logins per username and per IP address and locks them out
+// for exponentially increasing time once too many attempts fail.
+type loginLimiter struct {
+	db   *gorm.DB
+	repo repository.Repository
+	conf config.ConfReader
+}
+
+// NewLoginLimiter returns new instance of LoginLimiter.
+func NewLoginLimiter(db *gorm.DB, repo repository.Repository, conf config.ConfReader) LoginLimiter {
+	return &loginLimiter{
+		db:   db,
+		repo: repo,
+		conf: conf,
+	}
+}
+
+// Check will return error if username or IP address is locked out.
+func (ser *loginLimiter) Check(ctx context.Context, username, ipAddress string) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	var totalCount int64
+
+	err := ser.repo.GetCount(uow, userModal.LoginAttempt{}, &totalCount,
+		repository.Filter("login_attempts.`key` IN (?) AND login_attempts.`locked_until` > ?",
+			ser.keys(username, ipAddress), time.Now()))
+	if err != nil {
+		return err
+	}
+
+	if totalCount > 0 {
+		return errors.NewValidationError("Too many failed login attempts. Please try again later.")
+	}
+
+	return uow.Commit()
+}
+
+// RecordFailure will count failed login for username and IP address and lock them out
+// when they exceed allowed attempts.
+func (ser *loginLimiter) RecordFailure(ctx context.Context, username, ipAddress string) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err := ser.recordFailure(uow, usernameKey(username), ser.configInt(config.LoginMaxAttempts,
+		defaultLoginMaxAttempts))
+	if err != nil {
+		return err
+	}
+
+	if len(ipAddress) > 0 {
+		err = ser.recordFailure(uow, ipAddressKey(ipAddress), ser.configInt(config.LoginMaxIPAttempts,
+			defaultLoginMaxIPAttempts))
+		if err != nil {
+			return err
+		}
+	}
+
+	return uow.Commit()
+}
+
+// RecordSuccess will clear failed logins of the username.
+func (ser *loginLimiter) RecordSuccess(ctx context.Context, username string) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err := ser.repo.Delete(uow, &userModal.LoginAttempt{}, "`key` = ?", usernameKey(username))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// PurgeExpired will remove attempts which are neither locked nor recent.
+func (ser *loginLimiter) PurgeExpired() error {
+
+	uow := repository.NewUnitOfWork(ser.db)
+	defer uow.RollBack()
+
+	now := time.Now()
+
+	err := ser.repo.Delete(uow, &userModal.LoginAttempt{},
+		"`last_failure_at` < ? AND (`locked_until` IS NULL OR `locked_until` < ?)", now.Add(-loginAttemptWindow), now)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// recordFailure will increment failures of the key and lock it when failures reach maxAttempts.
+// Every failure after that doubles the lockout time. Failures are counted by the database,
+// so that concurrent failures are all counted.
+func (ser *loginLimiter) recordFailure(uow *repository.UnitOfWork, key string, maxAttempts int) error {
+
+	now := time.Now()
+
+	// failures older than the window are forgotten, so counting starts again.
+	err := ser.repo.Upsert(uow, &userModal.LoginAttempt{
+		Key:           key,
+		Failures:      1,
+		LastFailureAt: now,
+	}, []string{"key"}, []clause.Assignment{
+		{Column: clause.Column{Name: "failures"}, Value: gorm.Expr("CASE WHEN `last_failure_at` < ? THEN 1"+
+			" ELSE `failures` + 1 END", now.Add(-loginAttemptWindow))},
+		{Column: clause.Column{Name: "last_failure_at"}, Value: now},
+	})
+	if err != nil {
+		return err
+	}
+
+	attempt := userModal.LoginAttempt{}
+
+	err = ser.repo.GetRecord(uow, &attempt, repository.Filter("login_attempts.`key` = ?", key))
+	if err != nil {
+		return err
+	}
+
+	return ser.repo.UpdateWithMap(uow, &userModal.LoginAttempt{}, map[string]interface{}{
+		"LockedUntil": ser.lockedUntil(attempt.Failures, maxAttempts),
+	}, repository.Filter("login_attempts.`id` = ?", attempt.ID))
+}
+
+// lockedUntil will return end of lockout for number of failures, nil when not locked.
+func (ser *loginLimiter) lockedUntil(failures, maxAttempts int) *time.Time {
+	if failures < maxAttempts {
+		return nil
+	}
+
+	lockout := time.Duration(ser.configInt(config.LoginLockoutMinutes, defaultLoginLockoutMinutes)) * time.Minute
+	maxLockout := time.Duration(ser.configInt(config.LoginMaxLockoutMinutes, defaultLoginMaxLockoutMinutes)) *
+		time.Minute
+
+	for i := maxAttempts; i < failures && lockout < maxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > maxLockout {
+		lockout = maxLockout
+	}
+
+	lockedUntil := time.Now().Add(lockout)
+	return &lockedUntil
+}
+
+// configInt will return positive value of key from config or defaultValue.
+func (ser *loginLimiter) configInt(key config.EnvKey, defaultValue int) int {
+	if ser.conf.IsSet(key) && ser.conf.GetInt64(key) > 0 {
+		return int(ser.conf.GetInt64(key))
+	}
+	return defaultValue
+}
+
+// keys will return keys of attempts for username and IP address.
+func (ser *loginLimiter) keys(username, ipAddress string) []string {
+	keys := []string{usernameKey(username)}
+	if len(ipAddress) > 0 {
+		keys = append(keys, ipAddressKey(ipAddress))
+	}
+	return keys
+}
+
+// usernameKey is key of attempts of the username. Username is used instead of user ID
+// so that unknown usernames are locked out the same way as registered ones.
+func usernameKey(username string) string {
+	return "user:" + strings.ToLower(username)
+}
+
+// ipAddressKey is key of attempts from the IP address.
+func ipAddressKey(ipAddress string) string {
+	return "ip:" + ipAddress
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/oidc"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"gorm.io/gorm"
+)
+
+// oidcStateTTL is how long user has to login with provider.
+const oidcStateTTL = 10 * time.Minute
+
+// OIDCService consist of all methods OIDCService should implement.
+type OIDCService interface {
+	GetProviders() []string
+	Authorize(ctx context.Context, providerName string, authorization *userModal.OIDCAuthorization) error
+	Callback(ctx context.Context, providerName string, callback *userModal.OIDCCallback,
+		auth *userModal.Authentication) error
+	PurgeExpired() error
+}
+
+// oidcService provides methods to login with OpenID Connect providers using authorization code flow with PKCE.
+type oidcService struct {
+	db           *gorm.DB
+	repo         repository.Repository
+	auth         *security.Authentication
+	providers    *oidc.Registry
+	tokenService TokenService
+}
+
+// NewOIDCService returns new instance of OIDCService.
+func NewOIDCService(db *gorm.DB, repo repository.Repository, auth *security.Authentication,
+	providers *oidc.Registry, tokenService TokenService) OIDCService {
+	return &oidcService{
+		db:           db,
+		repo:         repo,
+		auth:         auth,
+		providers:    providers,
+		tokenService: tokenService,
+	}
+}
+
+// GetProviders will return names of providers with which user can login.
+func (ser *oidcService) GetProviders() []string {
+	return ser.providers.Names()
+}
+
+// Authorize will start login with provider and return url to which user should be sent.
+func (ser *oidcService) Authorize(ctx context.Context, providerName string,
+	authorization *userModal.OIDCAuthorization) error {
+
+	provider, err := ser.providers.Get(providerName)
+	if err != nil {
+		return err
+	}
+
+	state, stateHash, err := ser.auth.GenerateRandomToken()
+	if err != nil {
+		return err
+	}
+
+	nonce, _, err := ser.auth.GenerateRandomToken()
+	if err != nil {
+		return err
+	}
+
+	codeVerifier, _, err := ser.auth.GenerateRandomToken()
+	if err != nil {
+		return err
+	}
+
+	authorization.AuthorizationURL, err = provider.AuthorizationURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.Add(uow, &userModal.OIDCState{
+		Provider:     provider.Name,
+		StateHash:    stateHash,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// Callback will complete login with provider. Identity is linked to existing user having same email
+// when provider has verified the email, else new user is created.
+func (ser *oidcService) Callback(ctx context.Context, providerName string, callback *userModal.OIDCCallback,
+	auth *userModal.Authentication) error {
+
+	provider, err := ser.providers.Get(providerName)
+	if err != nil {
+		return err
+	}
+
+	state, err := ser.useState(ctx, provider.Name, callback.State)
+	if err != nil {
+		return err
+	}
+
+	claims, err := provider.Exchange(ctx, callback.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user, err := ser.getLinkedUser(uow, provider.Name, claims)
+	if err != nil {
+		return err
+	}
+
+	err = completeLogin(uow, ser.auth, ser.tokenService, user, auth)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// PurgeExpired will remove logins with provider which were never completed.
+func (ser *oidcService) PurgeExpired() error {
+
+	uow := repository.NewUnitOfWork(ser.db)
+	defer uow.RollBack()
+
+	err := ser.repo.Delete(uow, &userModal.OIDCState{}, "`expires_at` < ?", time.Now())
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// useState will fetch and remove state of login so that callback can't be replayed.
+func (ser *oidcService) useState(ctx context.Context, providerName, state string) (*userModal.OIDCState, error) {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	oidcState := userModal.OIDCState{}
+
+	err := ser.repo.GetRecord(uow, &oidcState, repository.Filter("oidc_states.`state_hash` = ?"+
+		" AND oidc_states.`provider` = ?", security.HashToken(state), providerName))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewValidationError("Invalid or expired login")
+		}
+		return nil, err
+	}
+
+	err = ser.repo.Delete(uow, &userModal.OIDCState{}, "`id` = ?", oidcState.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	if oidcState.ExpiresAt.Before(time.Now()) {
+		return nil, errors.NewValidationError("Invalid or expired login")
+	}
+	return &oidcState, nil
+}
+
+// getLinkedUser will return user linked with identity at provider, linking or creating the user if required.
+func (ser *oidcService) getLinkedUser(uow *repository.UnitOfWork, providerName string,
+	claims *oidc.IDTokenClaims) (*userModal.User, error) {
+
+	user := userModal.User{}
+
+	err := ser.repo.GetRecord(uow, &user, repository.Join("INNER JOIN user_identities"+
+		" ON user_identities.`user_id` = users.`id` AND user_identities.`deleted_at` IS NULL"),
+		repository.Filter("user_identities.`provider` = ? AND user_identities.`subject` = ?"+
+			" AND users.`deleted_at` IS NULL", providerName, claims.Subject))
+	if err == nil {
+		return &user, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	// email which is not verified by provider could belong to someone else.
+	email := strings.TrimSpace(claims.Email)
+	if len(email) == 0 || !claims.IsEmailVerified() {
+		return nil, errors.NewValidationError("Email of your account is not verified by the provider")
+	}
+
+	err = ser.repo.GetRecord(uow, &user, repository.Filter("users.`email` = ? AND users.`deleted_at` IS NULL", email))
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	if err == gorm.ErrRecordNotFound {
+		user, err = ser.createUser(uow, email, claims)
+		if err != nil {
+			return nil, err
+		}
+	} else if !user.IsVerified {
+		err = ser.claimUnverifiedUser(uow, &user)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = ser.repo.Add(uow, &userModal.UserIdentity{
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  claims.Subject,
+		Email:    email,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// createUser will register new verified user without password for the identity.
+// Password can be set later using forgot password.
+func (ser *oidcService) createUser(uow *repository.UnitOfWork, email string,
+	claims *oidc.IDTokenClaims) (userModal.User, error) {
+
+	name := strings.TrimSpace(claims.Name)
+	if len(name) == 0 {
+		name = strings.Split(email, "@")[0]
+	}
+
+	username := strings.TrimSpace(claims.PreferredUsername)
+	if len(username) == 0 {
+		username = strings.Split(email, "@")[0]
+	}
+
+	// usernames are unique across tenants.
+	exist, err := repository.DoesRecordExist(ser.db, userModal.User{},
+		repository.Filter("users.`username` = ?", username))
+	if err != nil {
+		return userModal.User{}, err
+	}
+
+	if exist {
+		username += "-" + strings.Split(uuid.New().String(), "-")[0]
+	}
+
+	tenantID, err := defaultTenantID(uow, ser.repo)
+	if err != nil {
+		return userModal.User{}, err
+	}
+
+	user := userModal.User{
+		Name:       name,
+		Username:   username,
+		Email:      email,
+		IsVerified: true,
+		TenantID:   tenantID,
+	}
+
+	err = ser.repo.Add(uow, &user)
+	if err != nil {
+		return userModal.User{}, err
+	}
+
+	err = createPersonalBudget(uow, ser.repo, user.ID, user.TenantID)
+	if err != nil {
+		return userModal.User{}, err
+	}
+	return user, nil
+}
+
+// claimUnverifiedUser will verify email of user which is linked to identity. Email of the user was never
+// proven to belong to the owner, so credentials set on it could be of someone else and are removed.
+func (ser *oidcService) claimUnverifiedUser(uow *repository.UnitOfWork, user *userModal.User) error {
+
+	err := ser.repo.UpdateWithMap(uow, &userModal.User{}, map[string]interface{}{
+		"Password":           "",
+		"IsVerified":         true,
+		"IsTwoFactorEnabled": false,
+		"TOTPSecret":         nil,
+		"TOTPLastCounter":    0,
+	}, repository.Filter("users.`id` = ?", user.ID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Delete(uow, &userModal.RecoveryCode{}, "`user_id` = ?", user.ID)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.APIToken{}, map[string]interface{}{
+		"DeletedAt": time.Now(),
+	}, repository.Filter("api_tokens.`user_id` = ? AND api_tokens.`deleted_at` IS NULL", user.ID))
+	if err != nil {
+		return err
+	}
+
+	user.IsVerified = true
+	user.IsTwoFactorEnabled = false
+	return ser.tokenService.RevokeUserSessions(uow, user.ID)
+}
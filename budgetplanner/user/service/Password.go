@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/email"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"gorm.io/gorm"
+)
+
+// passwordResetTTL is how long password reset link is valid.
+const passwordResetTTL = time.Hour
+
+// PasswordService consist of all methods PasswordService should implement.
+type PasswordService interface {
+	ForgotPassword(ctx context.Context, forgot *userModal.ForgotPassword) error
+	ResetPassword(ctx context.Context, reset *userModal.ResetPassword) error
+	ChangePassword(ctx context.Context, userID uuid.UUID, change *userModal.ChangePassword) error
+	ForcePasswordReset(uow *repository.UnitOfWork, userID uuid.UUID) error
+	PurgeExpired() error
+}
+
+// passwordService provides methods to reset and change password of user.
+type passwordService struct {
+	db           *gorm.DB
+	repo         repository.Repository
+	auth         *security.Authentication
+	conf         config.ConfReader
+	sender       email.Sender
+	tokenService TokenService
+}
+
+// NewPasswordService returns new instance of PasswordService.
+func NewPasswordService(db *gorm.DB, repo repository.Repository, auth *security.Authentication,
+	conf config.ConfReader, sender email.Sender, tokenService TokenService) PasswordService {
+	return &passwordService{
+		db:           db,
+		repo:         repo,
+		auth:         auth,
+		conf:         conf,
+		sender:       sender,
+		tokenService: tokenService,
+	}
+}
+
+// ForgotPassword will email single-use password reset link to the user.
+// Unknown emails are ignored so that registered emails are not revealed.
+func (ser *passwordService) ForgotPassword(ctx context.Context, forgot *userModal.ForgotPassword) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user := userModal.User{}
+
+	err := ser.repo.GetRecord(uow, &user, repository.Filter("users.`email` = ? AND users.`deleted_at` IS NULL",
+		forgot.Email), repository.Select("`id`, `name`, `email`"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+
+	err = ser.sendResetLink(uow, &user)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// ResetPassword will set new password using token sent in email and revoke all sessions of the user.
+func (ser *passwordService) ResetPassword(ctx context.Context, reset *userModal.ResetPassword) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	passwordReset := userModal.PasswordReset{}
+
+	err := ser.repo.GetRecord(uow, &passwordReset,
+		repository.Filter("password_resets.`token_hash` = ?", security.HashToken(reset.Token)))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewValidationError("Invalid or expired token")
+		}
+		return err
+	}
+
+	if !passwordReset.IsActive() {
+		return errors.NewValidationError("Invalid or expired token")
+	}
+
+	// token is consumed only if it is still unused, so that only one of concurrent resets succeeds.
+	var consumed int64
+	now := time.Now()
+
+	err = ser.repo.UpdateWithMapCount(uow, &userModal.PasswordReset{}, map[string]interface{}{
+		"UsedAt": now,
+	}, &consumed, repository.Filter("password_resets.`id` = ? AND password_resets.`used_at` IS NULL"+
+		" AND password_resets.`expires_at` > ?", passwordReset.ID, now))
+	if err != nil {
+		return err
+	}
+
+	if consumed != 1 {
+		return errors.NewValidationError("Invalid or expired token")
+	}
+
+	err = ser.updatePassword(uow, passwordReset.UserID, reset.Password)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// ChangePassword will change password of the user after verifying current password
+// and revoke all sessions of the user.
+func (ser *passwordService) ChangePassword(ctx context.Context, userID uuid.UUID,
+	change *userModal.ChangePassword) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user := userModal.User{}
+
+	err := ser.repo.GetRecord(uow, &user, repository.Filter("users.`id` = ? AND users.`deleted_at` IS NULL", userID),
+		repository.Select("`id`, `password`"))
+	if err != nil {
+		return err
+	}
+
+	err = security.ComparePassword(user.Password, change.CurrentPassword)
+	if err != nil {
+		return errors.NewValidationError("Current password is incorrect")
+	}
+
+	err = ser.updatePassword(uow, user.ID, change.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// ForcePasswordReset will remove password of the user, revoke all their sessions and email them
+// password reset link. User can't login with password until it is reset.
+// It must be called with the same unit of work in which the reset is recorded.
+func (ser *passwordService) ForcePasswordReset(uow *repository.UnitOfWork, userID uuid.UUID) error {
+
+	user := userModal.User{}
+
+	err := ser.repo.GetRecord(uow, &user, repository.Filter("users.`id` = ? AND users.`deleted_at` IS NULL", userID),
+		repository.Select("`id`, `name`, `email`"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewValidationError("User not found")
+		}
+		return err
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.User{}, map[string]interface{}{
+		"Password": "",
+	}, repository.Filter("users.`id` = ?", user.ID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.tokenService.RevokeUserSessions(uow, user.ID)
+	if err != nil {
+		return err
+	}
+
+	return ser.sendResetLink(uow, &user)
+}
+
+// PurgeExpired will remove expired password reset tokens.
+func (ser *passwordService) PurgeExpired() error {
+
+	uow := repository.NewUnitOfWork(ser.db)
+	defer uow.RollBack()
+
+	err := ser.repo.Delete(uow, &userModal.PasswordReset{}, "`expires_at` < ?", time.Now())
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// updatePassword will store hash of new password and revoke all sessions of the user.
+func (ser *passwordService) updatePassword(uow *repository.UnitOfWork, userID uuid.UUID, password string) error {
+
+	hashedPassword, err := security.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.User{}, map[string]interface{}{
+		"Password": string(hashedPassword),
+	}, repository.Filter("users.`id` = ?", userID))
+	if err != nil {
+		return err
+	}
+
+	return ser.tokenService.RevokeUserSessions(uow, userID)
+}
+
+// sendResetLink will email single-use password reset link to the user. Earlier links can't be used anymore.
+func (ser *passwordService) sendResetLink(uow *repository.UnitOfWork, user *userModal.User) error {
+
+	err := ser.repo.UpdateWithMap(uow, &userModal.PasswordReset{}, map[string]interface{}{
+		"UsedAt": time.Now(),
+	}, repository.Filter("password_resets.`user_id` = ? AND password_resets.`used_at` IS NULL", user.ID))
+	if err != nil {
+		return err
+	}
+
+	token, tokenHash, err := ser.auth.GenerateRandomToken()
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Add(uow, &userModal.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	return ser.sender.Send(&email.Message{
+		To:      []string{user.Email},
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nYou can reset your password by opening the link below.\n\n%s\n\n"+
+			"The link expires in %d minutes. If you did not ask to reset your password, ignore this email.",
+			user.Name, ser.resetLink(token), int(passwordResetTTL.Minutes())),
+	})
+}
+
+// resetLink will return link which is sent to the user for resetting password.
+func (ser *passwordService) resetLink(token string) string {
+	return strings.TrimRight(ser.conf.GetString(config.AppURL), "/") + "/reset-password?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"gorm.io/gorm"
+)
+
+// PreferenceService consist of all methods PreferenceService should implement.
+type PreferenceService interface {
+	GetPreference(ctx context.Context, userID uuid.UUID) (*userModal.Preference, error)
+	UpdatePreference(ctx context.Context, preference *userModal.Preference) error
+}
+
+// preferenceService provides methods to manage preferences of user.
+type preferenceService struct {
+	db   *gorm.DB
+	repo repository.Repository
+}
+
+// NewPreferenceService returns new instance of PreferenceService.
+func NewPreferenceService(db *gorm.DB, repo repository.Repository) PreferenceService {
+	return &preferenceService{
+		db:   db,
+		repo: repo,
+	}
+}
+
+// GetPreference will fetch preferences of the user, default preferences are returned when user
+// has not set them yet.
+func (ser *preferenceService) GetPreference(ctx context.Context, userID uuid.UUID) (*userModal.Preference, error) {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	preference := userModal.Preference{}
+
+	err := ser.repo.GetRecord(uow, &preference, repository.Filter("user_preferences.`user_id` = ?", userID))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return userModal.DefaultPreference(userID), nil
+		}
+		return nil, err
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
+
+// UpdatePreference will replace preferences of the user, they are created on first update.
+func (ser *preferenceService) UpdatePreference(ctx context.Context, preference *userModal.Preference) error {
+
+	err := ser.validateUserID(ctx, preference.UserID)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	tempPreference := userModal.Preference{}
+
+	err = ser.repo.GetRecord(uow, &tempPreference,
+		repository.Filter("user_preferences.`user_id` = ?", preference.UserID),
+		repository.Select("user_preferences.`id`"))
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	if err == gorm.ErrRecordNotFound {
+		err = ser.repo.Add(uow, preference)
+		if err != nil {
+			return err
+		}
+
+		return uow.Commit()
+	}
+
+	preference.ID = tempPreference.ID
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.Preference{}, map[string]interface{}{
+		"Timezone":       preference.Timezone,
+		"Locale":         preference.Locale,
+		"Currency":       preference.Currency,
+		"FirstDayOfWeek": preference.FirstDayOfWeek,
+		"PeriodStartDay": preference.PeriodStartDay,
+	}, repository.Filter("user_preferences.`id` = ?", preference.ID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// validateUserID will verify if user exist and is not deleted.
+func (ser *preferenceService) validateUserID(ctx context.Context, userID uuid.UUID) error {
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), userModal.User{},
+		repository.Filter("users.`id` = ? AND users.`deleted_at` IS NULL", userID))
+	if err != nil {
+		return err
+	}
+
+	if !exist {
+		return errors.NewValidationError("User not found")
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/imaging"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/storage"
+	"gorm.io/gorm"
+)
+
+// thumbnailContentType is content type of every thumbnail of profile image.
+const thumbnailContentType = "image/jpeg"
+
+// profileImageURL is path from which profile image is downloaded, version changes on every upload.
+const profileImageURL = "/api/v1/budget-planner/users/%s/profile-image?v=%s"
+
+// ProfileImageService consist of all methods ProfileImageService should implement.
+type ProfileImageService interface {
+	UploadProfileImage(ctx context.Context, upload *userModal.ProfileImageUpload) error
+	GetProfileImage(ctx context.Context, file *userModal.ProfileImageFile) error
+	DeleteProfileImage(ctx context.Context, userID uuid.UUID) error
+	MaxUploadSize() int64
+}
+
+// profileImageService provides methods to upload profile image of user along with its thumbnails.
+type profileImageService struct {
+	db    *gorm.DB
+	repo  repository.Repository
+	conf  config.ConfReader
+	store storage.Storage
+}
+
+// NewProfileImageService returns new instance of ProfileImageService.
+func NewProfileImageService(db *gorm.DB, repo repository.Repository, conf config.ConfReader,
+	store storage.Storage) ProfileImageService {
+	return &profileImageService{
+		db:    db,
+		repo:  repo,
+		conf:  conf,
+		store: store,
+	}
+}
+
+// UploadProfileImage will store the image along with its thumbnails and replace current profile image.
+// Every upload is stored under a new folder so that cached images of previous upload are never served.
+func (ser *profileImageService) UploadProfileImage(ctx context.Context, upload *userModal.ProfileImageUpload) error {
+
+	if int64(len(upload.Data)) > ser.MaxUploadSize() {
+		return errors.NewValidationError(fmt.Sprintf("Image must not be larger than %d bytes", ser.MaxUploadSize()))
+	}
+
+	img, contentType, err := imaging.Decode(upload.Data)
+	if err != nil {
+		return err
+	}
+
+	user, err := ser.getUser(ctx, upload.UserID)
+	if err != nil {
+		return err
+	}
+
+	version := uuid.New()
+	folder := fmt.Sprintf("users/%s/profile-image/%s", upload.UserID, version)
+
+	files := map[string][]byte{
+		userModal.ProfileImageOriginal: upload.Data,
+	}
+
+	for variant, size := range userModal.ProfileImageSizes {
+		files[variant], err = imaging.Thumbnail(img, size)
+		if err != nil {
+			return err
+		}
+	}
+
+	uploaded := &userModal.User{ProfileImageKey: &folder}
+
+	for variant, data := range files {
+		fileType := thumbnailContentType
+		if variant == userModal.ProfileImageOriginal {
+			fileType = contentType
+		}
+
+		err = ser.store.Put(ctx, userModal.ProfileImageKey(folder, variant), fileType, data)
+		if err != nil {
+			ser.deleteFiles(uploaded)
+			return err
+		}
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.User{}, map[string]interface{}{
+		"ProfileImage":     fmt.Sprintf(profileImageURL, upload.UserID, version),
+		"ProfileImageKey":  folder,
+		"ProfileImageType": contentType,
+	}, repository.Filter("users.`id` = ?", upload.UserID))
+	if err != nil {
+		ser.deleteFiles(uploaded)
+		return err
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		return err
+	}
+
+	ser.deleteFiles(user)
+	return nil
+}
+
+// GetProfileImage will open variant of profile image of the user.
+func (ser *profileImageService) GetProfileImage(ctx context.Context, file *userModal.ProfileImageFile) error {
+
+	user, err := ser.getUser(ctx, file.UserID)
+	if err != nil {
+		return err
+	}
+
+	if user.ProfileImageKey == nil {
+		return errors.NewValidationError("Profile image not found")
+	}
+
+	file.ContentType = thumbnailContentType
+	if file.Variant == userModal.ProfileImageOriginal && user.ProfileImageType != nil {
+		file.ContentType = *user.ProfileImageType
+	}
+
+	file.Content, err = ser.store.Get(ctx, userModal.ProfileImageKey(*user.ProfileImageKey, file.Variant))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteProfileImage will remove profile image of the user along with its thumbnails.
+func (ser *profileImageService) DeleteProfileImage(ctx context.Context, userID uuid.UUID) error {
+
+	user, err := ser.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.ProfileImageKey == nil {
+		return errors.NewValidationError("Profile image not found")
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.User{}, map[string]interface{}{
+		"ProfileImage":     nil,
+		"ProfileImageKey":  nil,
+		"ProfileImageType": nil,
+	}, repository.Filter("users.`id` = ?", userID))
+	if err != nil {
+		return err
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		return err
+	}
+
+	ser.deleteFiles(user)
+	return nil
+}
+
+// MaxUploadSize will return maximum size of uploaded image in bytes.
+func (ser *profileImageService) MaxUploadSize() int64 {
+	if ser.conf.IsSet(config.ProfileImageMaxBytes) && ser.conf.GetInt64(config.ProfileImageMaxBytes) > 0 {
+		return ser.conf.GetInt64(config.ProfileImageMaxBytes)
+	}
+	return userModal.DefaultProfileImageMaxBytes
+}
+
+// getUser will fetch profile image fields of the user.
+func (ser *profileImageService) getUser(ctx context.Context, userID uuid.UUID) (*userModal.User, error) {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user := userModal.User{}
+
+	err := ser.repo.GetRecord(uow, &user, repository.Filter("users.`id` = ? AND users.`deleted_at` IS NULL", userID),
+		repository.Select("`id`, `profile_image_key`, `profile_image_type`"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// deleteFiles will delete every variant of profile image of the user. Files left behind on failure
+// are only logged as they are no longer referred to.
+func (ser *profileImageService) deleteFiles(user *userModal.User) {
+	for _, key := range user.ProfileImageKeys() {
+		err := ser.store.Delete(context.Background(), key)
+		if err != nil {
+			log.GetLogger().Error(err)
+		}
+	}
+}
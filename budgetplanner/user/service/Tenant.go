@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"gorm.io/gorm"
+)
+
+// TenantService consist of all methods TenantService should implement.
+type TenantService interface {
+	CreateTenant(ctx context.Context, registration *userModal.TenantRegistration, auth *userModal.Authentication) error
+	GetTenant(ctx context.Context, tenant *userModal.TenantDTO) error
+	UpdateTenant(ctx context.Context, tenant *userModal.Tenant) error
+	GetUsers(ctx context.Context, users *[]userModal.UserDTO, tenantID uuid.UUID) error
+	AddUser(ctx context.Context, tenantID uuid.UUID, user *userModal.User) error
+	UpdateUserRole(ctx context.Context, role *userModal.TenantUserRole) error
+	RemoveUser(ctx context.Context, tenantID, adminID, userID uuid.UUID) error
+}
+
+// tenantService provides methods to sign up organizations and to let their admins manage users.
+type tenantService struct {
+	db                  *gorm.DB
+	repo                repository.Repository
+	auth                *security.Authentication
+	tokenService        TokenService
+	verificationService VerificationService
+}
+
+// NewTenantService returns new instance of TenantService.
+func NewTenantService(db *gorm.DB, repo repository.Repository, auth *security.Authentication,
+	tokenService TokenService, verificationService VerificationService) TenantService {
+	return &tenantService{
+		db:                  db,
+		repo:                repo,
+		auth:                auth,
+		tokenService:        tokenService,
+		verificationService: verificationService,
+	}
+}
+
+// CreateTenant will sign up new organization with the registering user as its admin.
+func (ser *tenantService) CreateTenant(ctx context.Context, registration *userModal.TenantRegistration,
+	auth *userModal.Authentication) error {
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), userModal.Tenant{},
+		repository.Filter("tenants.`slug` = ?", registration.Tenant.Slug))
+	if err != nil {
+		return err
+	}
+	if exist {
+		return errors.NewValidationError("Slug is already taken")
+	}
+
+	admin := &registration.Admin
+
+	err = ser.validateUniqueUser(admin)
+	if err != nil {
+		return err
+	}
+
+	password, err := security.HashPassword(admin.Password)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	registration.Tenant.IsActive = true
+
+	err = ser.repo.Add(uow, &registration.Tenant)
+	if err != nil {
+		return err
+	}
+
+	admin.Password = string(password)
+	admin.IsVerified = false
+	admin.VerificationSentAt = nil
+	admin.TenantID = registration.Tenant.ID
+	admin.IsTenantAdmin = true
+
+	err = ser.repo.Add(uow, admin)
+	if err != nil {
+		return err
+	}
+
+	err = createPersonalBudget(uow, ser.repo, admin.ID, admin.TenantID)
+	if err != nil {
+		return err
+	}
+
+	auth.UserID = admin.ID
+	auth.Name = admin.Name
+	auth.Email = admin.Email
+
+	if !ser.verificationService.IsVerificationRequired() {
+		err = ser.tokenService.IssueTokens(uow, auth)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		return err
+	}
+
+	err = ser.verificationService.SendVerificationEmail(ctx, admin.ID)
+	if err != nil {
+		log.GetLogger().Error(err)
+	}
+	return nil
+}
+
+// GetTenant will fetch details of the tenant.
+func (ser *tenantService) GetTenant(ctx context.Context, tenant *userModal.TenantDTO) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err := ser.repo.GetRecord(uow, tenant, repository.Filter("tenants.`id` = ? AND tenants.`deleted_at` IS NULL",
+		tenant.ID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// UpdateTenant will rename the tenant. Slug of tenant can't be changed.
+func (ser *tenantService) UpdateTenant(ctx context.Context, tenant *userModal.Tenant) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err := ser.repo.UpdateWithMap(uow, &userModal.Tenant{}, map[string]interface{}{
+		"Name": tenant.Name,
+	}, repository.Filter("tenants.`id` = ?", tenant.ID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// GetUsers will fetch all users of the tenant.
+func (ser *tenantService) GetUsers(ctx context.Context, users *[]userModal.UserDTO, tenantID uuid.UUID) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err := ser.repo.GetAllInOrder(uow, users, "users.`name`",
+		repository.Filter("users.`tenant_id` = ? AND users.`deleted_at` IS NULL", tenantID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// AddUser will create user in the tenant. User has to verify email before logging in
+// when verification is required.
+func (ser *tenantService) AddUser(ctx context.Context, tenantID uuid.UUID, user *userModal.User) error {
+
+	err := ser.validateUniqueUser(user)
+	if err != nil {
+		return err
+	}
+
+	password, err := security.HashPassword(user.Password)
+	if err != nil {
+		return err
+	}
+
+	user.Password = string(password)
+	user.IsVerified = false
+	user.VerificationSentAt = nil
+	user.TenantID = tenantID
+	user.IsTenantAdmin = false
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.repo.Add(uow, user)
+	if err != nil {
+		return err
+	}
+
+	err = createPersonalBudget(uow, ser.repo, user.ID, user.TenantID)
+	if err != nil {
+		return err
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		return err
+	}
+
+	err = ser.verificationService.SendVerificationEmail(ctx, user.ID)
+	if err != nil {
+		log.GetLogger().Error(err)
+	}
+	return nil
+}
+
+// UpdateUserRole will make user admin of the tenant or revoke it. Tenant must be left with at least one admin.
+func (ser *tenantService) UpdateUserRole(ctx context.Context, role *userModal.TenantUserRole) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user, err := ser.getUser(uow, role.TenantID, role.UserID)
+	if err != nil {
+		return err
+	}
+
+	if user.IsTenantAdmin && !role.IsTenantAdmin {
+		err = ser.validateOtherAdminExists(uow, role.TenantID, role.UserID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.User{}, map[string]interface{}{
+		"IsTenantAdmin": role.IsTenantAdmin,
+	}, repository.Filter("users.`id` = ?", user.ID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// RemoveUser will delete user from the tenant and end their sessions. Admin can't remove themselves.
+func (ser *tenantService) RemoveUser(ctx context.Context, tenantID, adminID, userID uuid.UUID) error {
+
+	if adminID == userID {
+		return errors.NewValidationError("You cannot remove yourself from the tenant")
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	_, err := ser.getUser(uow, tenantID, userID)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.User{}, map[string]interface{}{
+		"DeletedAt": time.Now(),
+	}, repository.Filter("users.`id` = ?", userID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.tokenService.RevokeUserSessions(uow, userID)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// getUser will fetch user of the tenant.
+func (ser *tenantService) getUser(uow *repository.UnitOfWork, tenantID, userID uuid.UUID) (*userModal.User, error) {
+	user := userModal.User{}
+
+	err := ser.repo.GetRecord(uow, &user, repository.Filter("users.`id` = ? AND users.`tenant_id` = ?"+
+		" AND users.`deleted_at` IS NULL", userID, tenantID), repository.Select("`id`, `is_tenant_admin`"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewValidationError("User not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// validateOtherAdminExists will return error if user is the only admin of the tenant.
+func (ser *tenantService) validateOtherAdminExists(uow *repository.UnitOfWork, tenantID, userID uuid.UUID) error {
+	var count int64
+
+	err := ser.repo.GetCount(uow, userModal.User{}, &count, repository.Filter("users.`tenant_id` = ?"+
+		" AND users.`id` != ? AND users.`is_tenant_admin` = 1 AND users.`deleted_at` IS NULL", tenantID, userID))
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return errors.NewValidationError("Tenant must have at least one admin")
+	}
+	return nil
+}
+
+// validateUniqueUser will return error if email or username is already taken in any tenant,
+// so it is not scoped to tenant of the request.
+func (ser *tenantService) validateUniqueUser(user *userModal.User) error {
+	exist, err := repository.DoesRecordExist(ser.db, userModal.User{},
+		repository.Filter("users.`email` = ? OR users.`username` = ?", user.Email, user.Username))
+	if err != nil {
+		return err
+	}
+	if exist {
+		return errors.NewValidationError("Email or Username already exist")
+	}
+	return nil
+}
+
+// defaultTenantID will return ID of tenant which users registering on their own belong to.
+func defaultTenantID(uow *repository.UnitOfWork, repo repository.Repository) (uuid.UUID, error) {
+	tenant := userModal.Tenant{}
+
+	err := repo.GetRecord(uow, &tenant, repository.Filter("tenants.`slug` = ? AND tenants.`deleted_at` IS NULL",
+		userModal.DefaultTenantSlug), repository.Select("`id`"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return uuid.Nil, errors.NewValidationError("Registration is not available")
+		}
+		return uuid.Nil, err
+	}
+	return tenant.ID, nil
+}
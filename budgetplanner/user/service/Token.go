@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/util"
+	"gorm.io/gorm"
+)
+
+// TokenService consist of all methods TokenService should implement.
+type TokenService interface {
+	IssueTokens(uow *repository.UnitOfWork, auth *userModal.Authentication) error
+	RefreshTokens(ctx context.Context, refresh *userModal.Refresh, auth *userModal.Authentication) error
+	Logout(ctx context.Context, claims *userModal.Claims) error
+	GetSessions(ctx context.Context, sessions *[]userModal.SessionDTO, userID, currentSessionID uuid.UUID) error
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	RevokeAllSessions(ctx context.Context, userID uuid.UUID) error
+	RevokeUserSessions(uow *repository.UnitOfWork, userID uuid.UUID) error
+	PurgeExpired() error
+}
+
+// tokenService provides methods to issue, rotate and revoke tokens of user.
+type tokenService struct {
+	db   *gorm.DB
+	repo repository.Repository
+	auth *security.Authentication
+}
+
+// NewTokenService returns new instance of TokenService.
+func NewTokenService(db *gorm.DB, repo repository.Repository, auth *security.Authentication) TokenService {
+	return &tokenService{
+		db:   db,
+		repo: repo,
+		auth: auth,
+	}
+}
+
+// IssueTokens will create access token and refresh token for session of the user.
+// New session is started when auth does not contain a session.
+// It must be called with the same unit of work in which login is performed.
+func (ser *tokenService) IssueTokens(uow *repository.UnitOfWork, auth *userModal.Authentication) error {
+
+	err := ser.saveSession(uow, auth)
+	if err != nil {
+		return err
+	}
+
+	err = ser.auth.GenerateLoginToken(auth)
+	if err != nil {
+		return err
+	}
+
+	token, tokenHash, err := ser.auth.GenerateRandomToken()
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Add(uow, &userModal.RefreshToken{
+		UserID:    auth.UserID,
+		SessionID: auth.SessionID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(ser.auth.RefreshTokenTTL()),
+	})
+	if err != nil {
+		return err
+	}
+
+	auth.RefreshToken = token
+	return nil
+}
+
+// RefreshTokens will exchange refresh token for new access token and refresh token.
+// Refresh token can be used only once, reusing it revokes the whole session.
+func (ser *tokenService) RefreshTokens(ctx context.Context, refresh *userModal.Refresh,
+	auth *userModal.Authentication) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	refreshToken := userModal.RefreshToken{}
+
+	err := ser.repo.GetRecord(uow, &refreshToken,
+		repository.Filter("refresh_tokens.`token_hash` = ?", security.HashToken(refresh.RefreshToken)))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewValidationError("Invalid refresh token")
+		}
+		return err
+	}
+
+	if refreshToken.UsedAt != nil && refreshToken.RevokedAt == nil {
+		// token was already rotated, it has probably been stolen.
+		err = ser.revokeSession(uow, refreshToken.SessionID)
+		if err != nil {
+			return err
+		}
+
+		err = uow.Commit()
+		if err != nil {
+			return err
+		}
+		return errors.NewValidationError("Invalid refresh token")
+	}
+
+	if !refreshToken.IsActive() {
+		return errors.NewValidationError("Invalid refresh token")
+	}
+
+	// token is consumed only if it is still unused, so that only one of concurrent refreshes succeeds.
+	var consumed int64
+
+	err = ser.repo.UpdateWithMapCount(uow, &userModal.RefreshToken{}, map[string]interface{}{
+		"UsedAt": time.Now(),
+	}, &consumed, repository.Filter("refresh_tokens.`id` = ? AND refresh_tokens.`used_at` IS NULL"+
+		" AND refresh_tokens.`revoked_at` IS NULL", refreshToken.ID))
+	if err != nil {
+		return err
+	}
+
+	if consumed != 1 {
+		// token was rotated by another request in the meantime.
+		err = ser.revokeSession(uow, refreshToken.SessionID)
+		if err != nil {
+			return err
+		}
+
+		err = uow.Commit()
+		if err != nil {
+			return err
+		}
+		return errors.NewValidationError("Invalid refresh token")
+	}
+
+	user := userModal.User{}
+
+	err = ser.repo.GetRecord(uow, &user, repository.Filter("users.`id` = ? AND users.`deleted_at` IS NULL"+
+		" AND users.`disabled_at` IS NULL", refreshToken.UserID), repository.Select("`id`, `name`, `email`"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewValidationError("Invalid refresh token")
+		}
+		return err
+	}
+
+	auth.UserID = user.ID
+	auth.Name = user.Name
+	auth.Email = user.Email
+	auth.SessionID = refreshToken.SessionID
+
+	err = ser.IssueTokens(uow, auth)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// Logout will revoke the access token and session of the claims.
+func (ser *tokenService) Logout(ctx context.Context, claims *userModal.Claims) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	tokenID, err := uuid.Parse(claims.ID)
+	if err == nil {
+		err = ser.repo.Add(uow, &userModal.RevokedToken{
+			TokenID:   tokenID,
+			ExpiresAt: claims.ExpiresAt.Time,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if claims.SessionID != uuid.Nil {
+		err = ser.revokeSession(uow, claims.SessionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return uow.Commit()
+}
+
+// GetSessions will fetch active sessions of the user. Session of the current request is flagged.
+func (ser *tokenService) GetSessions(ctx context.Context, sessions *[]userModal.SessionDTO,
+	userID, currentSessionID uuid.UUID) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err := ser.repo.GetAllInOrder(uow, sessions, "sessions.`last_seen_at` DESC",
+		repository.Filter("sessions.`user_id` = ? AND sessions.`revoked_at` IS NULL AND sessions.`expires_at` > ?",
+			userID, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	for index := range *sessions {
+		(*sessions)[index].IsCurrent = (*sessions)[index].ID == currentSessionID
+	}
+
+	return uow.Commit()
+}
+
+// RevokeSession will terminate specified session of the user.
+func (ser *tokenService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), userModal.Session{},
+		repository.Filter("sessions.`id` = ? AND sessions.`user_id` = ? AND sessions.`revoked_at` IS NULL",
+			sessionID, userID))
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return errors.NewValidationError("Session not found")
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err = ser.revokeSession(uow, sessionID)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// RevokeAllSessions will revoke all active sessions of the user.
+func (ser *tokenService) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	err := ser.RevokeUserSessions(uow, userID)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// RevokeUserSessions will revoke all active sessions of the user within the given unit of work.
+func (ser *tokenService) RevokeUserSessions(uow *repository.UnitOfWork, userID uuid.UUID) error {
+
+	var sessionIDs []uuid.UUID
+
+	err := ser.repo.Scan(uow, &sessionIDs, repository.Model(&userModal.Session{}),
+		repository.Select("sessions.`id`"),
+		repository.Filter("sessions.`user_id` = ? AND sessions.`revoked_at` IS NULL AND sessions.`expires_at` > ?",
+			userID, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	for _, sessionID := range sessionIDs {
+		err = ser.revokeSession(uow, sessionID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurgeExpired will remove expired sessions, refresh tokens and revocation entries.
+func (ser *tokenService) PurgeExpired() error {
+
+	uow := repository.NewUnitOfWork(ser.db)
+	defer uow.RollBack()
+
+	now := time.Now()
+
+	err := ser.repo.Delete(uow, &userModal.RefreshToken{}, "`expires_at` < ?", now)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Delete(uow, &userModal.Session{}, "`expires_at` < ?", now)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Delete(uow, &userModal.RevokedToken{}, "`expires_at` < ?", now)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// saveSession will start new session for auth or extend its existing session.
+func (ser *tokenService) saveSession(uow *repository.UnitOfWork, auth *userModal.Authentication) error {
+
+	now := time.Now()
+	expiresAt := now.Add(ser.auth.RefreshTokenTTL())
+
+	// user agent is sent by client and can be longer than the column.
+	userAgent := util.Truncate(auth.UserAgent, userModal.MaxUserAgentLength)
+
+	if auth.SessionID == uuid.Nil {
+		session := userModal.Session{
+			UserID:     auth.UserID,
+			UserAgent:  userAgent,
+			IPAddress:  auth.IPAddress,
+			LastSeenAt: now,
+			ExpiresAt:  expiresAt,
+		}
+
+		err := ser.repo.Add(uow, &session)
+		if err != nil {
+			return err
+		}
+
+		auth.SessionID = session.ID
+		return nil
+	}
+
+	values := map[string]interface{}{
+		"LastSeenAt": now,
+		"ExpiresAt":  expiresAt,
+	}
+	if len(userAgent) > 0 {
+		values["UserAgent"] = userAgent
+	}
+	if len(auth.IPAddress) > 0 {
+		values["IPAddress"] = auth.IPAddress
+	}
+
+	return ser.repo.UpdateWithMap(uow, &userModal.Session{}, values,
+		repository.Filter("sessions.`id` = ?", auth.SessionID))
+}
+
+// revokeSession will revoke the session with its refresh tokens and add session to revocation list,
+// so that access tokens already issued for it are rejected.
+func (ser *tokenService) revokeSession(uow *repository.UnitOfWork, sessionID uuid.UUID) error {
+
+	err := ser.repo.UpdateWithMap(uow, &userModal.Session{}, map[string]interface{}{
+		"RevokedAt": time.Now(),
+	}, repository.Filter("sessions.`id` = ? AND sessions.`revoked_at` IS NULL", sessionID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.RefreshToken{}, map[string]interface{}{
+		"RevokedAt": time.Now(),
+	}, repository.Filter("refresh_tokens.`session_id` = ? AND refresh_tokens.`revoked_at` IS NULL", sessionID))
+	if err != nil {
+		return err
+	}
+
+	return ser.repo.Add(uow, &userModal.RevokedToken{
+		TokenID:   sessionID,
+		ExpiresAt: time.Now().Add(ser.auth.AccessTokenTTL()),
+	})
+}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/encryption"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"gorm.io/gorm"
+)
+
+// twoFactorChallengeTTL is how long user has to submit code after password is verified.
+const twoFactorChallengeTTL = 5 * time.Minute
+
+// TwoFactorService consist of all methods TwoFactorService should implement.
+type TwoFactorService interface {
+	Enroll(ctx context.Context, userID uuid.UUID, enrollment *userModal.TwoFactorEnrollment) error
+	Confirm(ctx context.Context, userID uuid.UUID, code *userModal.TwoFactorCode, recoveryCodes *userModal.RecoveryCodes) error
+	Disable(ctx context.Context, userID uuid.UUID, code *userModal.TwoFactorCode) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code *userModal.TwoFactorCode,
+		recoveryCodes *userModal.RecoveryCodes) error
+	Login(ctx context.Context, login *userModal.TwoFactorLogin, auth *userModal.Authentication) error
+}
+
+// twoFactorService provides methods to manage TOTP two factor authentication of user.
+type twoFactorService struct {
+	db           *gorm.DB
+	repo         repository.Repository
+	auth         *security.Authentication
+	tokenService TokenService
+	loginLimiter LoginLimiter
+}
+
+// NewTwoFactorService returns new instance of TwoFactorService.
+func NewTwoFactorService(db *gorm.DB, repo repository.Repository, auth *security.Authentication,
+	tokenService TokenService, loginLimiter LoginLimiter) TwoFactorService {
+	return &twoFactorService{
+		db:           db,
+		repo:         repo,
+		auth:         auth,
+		tokenService: tokenService,
+		loginLimiter: loginLimiter,
+	}
+}
+
+// Enroll will generate new TOTP secret for the user. Two factor authentication is enabled
+// only after the secret is confirmed with a code.
+func (ser *twoFactorService) Enroll(ctx context.Context, userID uuid.UUID,
+	enrollment *userModal.TwoFactorEnrollment) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user, err := ser.getUser(uow, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.IsTwoFactorEnabled {
+		return errors.NewValidationError("Two factor authentication is already enabled")
+	}
+
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.User{}, map[string]interface{}{
+		"TOTPSecret":      encryption.String(secret),
+		"TOTPLastCounter": 0,
+	}, repository.Filter("users.`id` = ?", user.ID))
+	if err != nil {
+		return err
+	}
+
+	enrollment.Secret = secret
+	enrollment.URI = security.TOTPURI(user.Email, secret)
+
+	return uow.Commit()
+}
+
+// Confirm will enable two factor authentication once code of the enrolled secret is verified.
+// Recovery codes are generated on confirmation.
+func (ser *twoFactorService) Confirm(ctx context.Context, userID uuid.UUID, code *userModal.TwoFactorCode,
+	recoveryCodes *userModal.RecoveryCodes) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user, err := ser.getUser(uow, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.IsTwoFactorEnabled {
+		return errors.NewValidationError("Two factor authentication is already enabled")
+	}
+
+	if user.TOTPSecret == nil {
+		return errors.NewValidationError("Two factor authentication is not enrolled")
+	}
+
+	counter, ok := security.ValidateTOTP(string(*user.TOTPSecret), code.Code, user.TOTPLastCounter)
+	if !ok {
+		return errors.NewValidationError("Invalid code")
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.User{}, map[string]interface{}{
+		"IsTwoFactorEnabled": true,
+		"TOTPLastCounter":    counter,
+	}, repository.Filter("users.`id` = ?", user.ID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.generateRecoveryCodes(uow, user.ID, recoveryCodes)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// Disable will turn off two factor authentication after verifying a code.
+func (ser *twoFactorService) Disable(ctx context.Context, userID uuid.UUID, code *userModal.TwoFactorCode) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user, err := ser.getUser(uow, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsTwoFactorEnabled {
+		return errors.NewValidationError("Two factor authentication is not enabled")
+	}
+
+	err = ser.verifyCode(uow, user, code.Code)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.User{}, map[string]interface{}{
+		"IsTwoFactorEnabled": false,
+		"TOTPSecret":         nil,
+		"TOTPLastCounter":    0,
+	}, repository.Filter("users.`id` = ?", user.ID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.Delete(uow, &userModal.RecoveryCode{}, "`user_id` = ?", user.ID)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// RegenerateRecoveryCodes will replace all recovery codes of the user after verifying a code.
+func (ser *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID,
+	code *userModal.TwoFactorCode, recoveryCodes *userModal.RecoveryCodes) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user, err := ser.getUser(uow, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsTwoFactorEnabled {
+		return errors.NewValidationError("Two factor authentication is not enabled")
+	}
+
+	err = ser.verifyCode(uow, user, code.Code)
+	if err != nil {
+		return err
+	}
+
+	err = ser.generateRecoveryCodes(uow, user.ID, recoveryCodes)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// Login will issue tokens once challenge token from login and TOTP or recovery code are verified.
+func (ser *twoFactorService) Login(ctx context.Context, login *userModal.TwoFactorLogin,
+	auth *userModal.Authentication) error {
+
+	claims := userModal.Claims{}
+
+	err := ser.auth.ParsePurposeToken(login.ChallengeToken, userModal.TokenPurposeTwoFactor, &claims)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user, err := ser.getUser(uow, claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	// codes are guessable, they are limited same as passwords.
+	err = ser.loginLimiter.Check(ctx, user.Username, auth.IPAddress)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsTwoFactorEnabled {
+		return errors.NewValidationError("Two factor authentication is not enabled")
+	}
+
+	err = ser.verifyCode(uow, user, login.Code)
+	if err != nil {
+		failureErr := ser.loginLimiter.RecordFailure(ctx, user.Username, auth.IPAddress)
+		if failureErr != nil {
+			return failureErr
+		}
+		return err
+	}
+
+	err = ser.loginLimiter.RecordSuccess(ctx, user.Username)
+	if err != nil {
+		return err
+	}
+
+	auth.UserID = user.ID
+	auth.Name = user.Name
+	auth.Email = user.Email
+
+	err = ser.tokenService.IssueTokens(uow, auth)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// verifyCode will accept TOTP code or unused recovery code of the user. Used code can't be used again.
+func (ser *twoFactorService) verifyCode(uow *repository.UnitOfWork, user *userModal.User, code string) error {
+
+	if user.TOTPSecret != nil {
+		counter, ok := security.ValidateTOTP(string(*user.TOTPSecret), code, user.TOTPLastCounter)
+		if ok {
+			return useTOTPCounter(uow, ser.repo, user.ID, counter)
+		}
+	}
+
+	recoveryCode := userModal.RecoveryCode{}
+
+	err := ser.repo.GetRecord(uow, &recoveryCode, repository.Filter("recovery_codes.`user_id` = ?"+
+		" AND recovery_codes.`code_hash` = ? AND recovery_codes.`used_at` IS NULL",
+		user.ID, security.HashToken(security.NormalizeRecoveryCode(code))))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewValidationError("Invalid code")
+		}
+		return err
+	}
+
+	// code is consumed only if it is still unused, so that only one of concurrent requests accepts it.
+	var consumed int64
+
+	err = ser.repo.UpdateWithMapCount(uow, &userModal.RecoveryCode{}, map[string]interface{}{
+		"UsedAt": time.Now(),
+	}, &consumed, repository.Filter("recovery_codes.`id` = ? AND recovery_codes.`used_at` IS NULL", recoveryCode.ID))
+	if err != nil {
+		return err
+	}
+
+	if consumed != 1 {
+		return errors.NewValidationError("Invalid code")
+	}
+	return nil
+}
+
+// useTOTPCounter will store counter of the accepted TOTP code so that it can't be used again. Counter is
+// stored only if it is later than the stored one, so that only one of concurrent requests accepts the code.
+func useTOTPCounter(uow *repository.UnitOfWork, repo repository.Repository, userID uuid.UUID, counter int64) error {
+	var updated int64
+
+	err := repo.UpdateWithMapCount(uow, &userModal.User{}, map[string]interface{}{
+		"TOTPLastCounter": counter,
+	}, &updated, repository.Filter("users.`id` = ? AND users.`totp_last_counter` < ?", userID, counter))
+	if err != nil {
+		return err
+	}
+
+	if updated != 1 {
+		return errors.NewValidationError("Invalid code")
+	}
+	return nil
+}
+
+// generateRecoveryCodes will replace recovery codes of the user with new ones.
+func (ser *twoFactorService) generateRecoveryCodes(uow *repository.UnitOfWork, userID uuid.UUID,
+	recoveryCodes *userModal.RecoveryCodes) error {
+
+	err := ser.repo.Delete(uow, &userModal.RecoveryCode{}, "`user_id` = ?", userID)
+	if err != nil {
+		return err
+	}
+
+	recoveryCodes.Codes = make([]string, 0, userModal.RecoveryCodeCount)
+
+	for i := 0; i < userModal.RecoveryCodeCount; i++ {
+		code, err := security.GenerateRecoveryCode()
+		if err != nil {
+			return err
+		}
+
+		err = ser.repo.Add(uow, &userModal.RecoveryCode{
+			UserID:   userID,
+			CodeHash: security.HashToken(code),
+		})
+		if err != nil {
+			return err
+		}
+
+		recoveryCodes.Codes = append(recoveryCodes.Codes, code)
+	}
+	return nil
+}
+
+// getUser will fetch user with two factor details.
+func (ser *twoFactorService) getUser(uow *repository.UnitOfWork, userID uuid.UUID) (*userModal.User, error) {
+	user := userModal.User{}
+
+	err := ser.repo.GetRecord(uow, &user, repository.Filter("users.`id` = ? AND users.`deleted_at` IS NULL", userID))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewValidationError("User not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
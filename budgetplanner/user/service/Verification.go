@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/email"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"gorm.io/gorm"
+)
+
+const (
+	// verificationTokenTTL is how long verification link is valid.
+	verificationTokenTTL = 24 * time.Hour
+	// verificationResendInterval is minimum time between two verification emails.
+	verificationResendInterval = 2 * time.Minute
+)
+
+// errVerificationThrottled is returned when verification email was sent within verificationResendInterval.
+var errVerificationThrottled = errors.NewValidationError("Verification email was sent recently. Please try again later")
+
+// VerificationService consist of all methods VerificationService should implement.
+type VerificationService interface {
+	SendVerificationEmail(ctx context.Context, userID uuid.UUID) error
+	ResendVerificationEmail(ctx context.Context, request *userModal.VerificationRequest) error
+	VerifyEmail(ctx context.Context, verification *userModal.EmailVerification) error
+	IsVerificationRequired() bool
+}
+
+// verificationService provides methods to verify email of user.
+type verificationService struct {
+	db     *gorm.DB
+	repo   repository.Repository
+	auth   *security.Authentication
+	conf   config.ConfReader
+	sender email.Sender
+}
+
+// NewVerificationService returns new instance of VerificationService.
+func NewVerificationService(db *gorm.DB, repo repository.Repository, auth *security.Authentication,
+	conf config.ConfReader, sender email.Sender) VerificationService {
+	return &verificationService{
+		db:     db,
+		repo:   repo,
+		auth:   auth,
+		conf:   conf,
+		sender: sender,
+	}
+}
+
+// SendVerificationEmail will email a signed verification link to the user.
+// Emails are throttled, only one email is sent in verificationResendInterval.
+func (ser *verificationService) SendVerificationEmail(ctx context.Context, userID uuid.UUID) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user := userModal.User{}
+
+	err := ser.repo.GetRecord(uow, &user, repository.Filter("users.`id` = ? AND users.`deleted_at` IS NULL", userID))
+	if err != nil {
+		return err
+	}
+
+	if user.IsVerified {
+		return errors.NewValidationError("Email is already verified")
+	}
+
+	if user.VerificationSentAt != nil && time.Since(*user.VerificationSentAt) < verificationResendInterval {
+		return errVerificationThrottled
+	}
+
+	token, err := ser.auth.GeneratePurposeToken(&userModal.Claims{
+		UserID:  user.ID,
+		Email:   user.Email,
+		Purpose: userModal.TokenPurposeEmailVerification,
+	}, verificationTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.User{}, map[string]interface{}{
+		"VerificationSentAt": time.Now(),
+	}, repository.Filter("users.`id` = ?", user.ID))
+	if err != nil {
+		return err
+	}
+
+	err = ser.sender.Send(&email.Message{
+		To:      []string{user.Email},
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease verify your email by opening the link below.\n\n%s\n\n"+
+			"The link expires in %d hours.", user.Name, ser.verificationLink(token), int(verificationTokenTTL.Hours())),
+	})
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// ResendVerificationEmail will send verification email again to the user with specified email.
+// Unknown and already verified emails are ignored so that registered emails are not revealed.
+func (ser *verificationService) ResendVerificationEmail(ctx context.Context,
+	request *userModal.VerificationRequest) error {
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user := userModal.User{}
+
+	err := ser.repo.GetRecord(uow, &user, repository.Filter("users.`email` = ? AND users.`deleted_at` IS NULL",
+		request.Email), repository.Select("`id`, `is_verified`"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+
+	err = uow.Commit()
+	if err != nil {
+		return err
+	}
+
+	if user.IsVerified {
+		return nil
+	}
+
+	err = ser.SendVerificationEmail(ctx, user.ID)
+	if err == errVerificationThrottled {
+		// unknown emails get no error, so throttled email must not get one either.
+		log.GetLogger().Info(err)
+		return nil
+	}
+	return err
+}
+
+// VerifyEmail will mark email of the user as verified.
+func (ser *verificationService) VerifyEmail(ctx context.Context, verification *userModal.EmailVerification) error {
+
+	claims := userModal.Claims{}
+
+	err := ser.auth.ParsePurposeToken(verification.Token, userModal.TokenPurposeEmailVerification, &claims)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
+	defer uow.RollBack()
+
+	user := userModal.User{}
+
+	err = ser.repo.GetRecord(uow, &user, repository.Filter("users.`id` = ? AND users.`deleted_at` IS NULL",
+		claims.UserID), repository.Select("`id`, `email`, `is_verified`"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewValidationError("Invalid or expired token")
+		}
+		return err
+	}
+
+	// email was changed after the token was issued.
+	if !strings.EqualFold(user.Email, claims.Email) {
+		return errors.NewValidationError("Invalid or expired token")
+	}
+
+	if user.IsVerified {
+		return nil
+	}
+
+	err = ser.repo.UpdateWithMap(uow, &userModal.User{}, map[string]interface{}{
+		"IsVerified": true,
+	}, repository.Filter("users.`id` = ?", user.ID))
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// IsVerificationRequired will return true if unverified users are not allowed to login.
+func (ser *verificationService) IsVerificationRequired() bool {
+	return ser.conf.GetBool(config.RequireVerifiedEmail)
+}
+
+// verificationLink will return link which is sent to the user for verifying email.
+func (ser *verificationService) verificationLink(token string) string {
+	return strings.TrimRight(ser.conf.GetString(config.AppURL), "/") + "/verify-email?token=" + url.QueryEscape(token)
+}
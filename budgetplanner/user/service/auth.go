@@ -1,8 +1,13 @@
 package service
 
 import (
+	"context"
+	"strings"
+
 	"github.com/google/uuid"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/errors"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
 	userModal "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
@@ -11,30 +16,37 @@ import (
 
 // AuthenticationService consist of all methods AuthenticationService should implement.
 type AuthenticationService interface {
-	Register(user *userModal.User, auth *userModal.Authentication) error
-	Login(login *userModal.Login, auth *userModal.Authentication) error
-	UpdateUser(user *userModal.User) error
-	GetUser(user *userModal.UserDTO) error
+	Register(ctx context.Context, user *userModal.User, auth *userModal.Authentication) error
+	Login(ctx context.Context, login *userModal.Login, auth *userModal.Authentication) error
+	UpdateUser(ctx context.Context, user *userModal.User) error
+	GetUser(ctx context.Context, user *userModal.UserDTO) error
 }
 
 // AuthenticationService service provides methods to update, delete, add, get method for AuthenticationService.
 type authenticationService struct {
-	db   *gorm.DB
-	repo repository.Repository
-	auth *security.Authentication
+	db                  *gorm.DB
+	repo                repository.Repository
+	auth                *security.Authentication
+	tokenService        TokenService
+	verificationService VerificationService
+	loginLimiter        LoginLimiter
 }
 
 // NewAuthenticationService create new AuthenticationService
-func NewAuthenticationService(db *gorm.DB, repo repository.Repository, auth *security.Authentication) AuthenticationService {
+func NewAuthenticationService(db *gorm.DB, repo repository.Repository, auth *security.Authentication,
+	tokenService TokenService, verificationService VerificationService, loginLimiter LoginLimiter) AuthenticationService {
 	return &authenticationService{
-		db:   db,
-		repo: repo,
-		auth: auth,
+		db:                  db,
+		repo:                repo,
+		auth:                auth,
+		tokenService:        tokenService,
+		verificationService: verificationService,
+		loginLimiter:        loginLimiter,
 	}
 }
 
 // Register will register new user in the system.
-func (ser *authenticationService) Register(user *userModal.User, auth *userModal.Authentication) error {
+func (ser *authenticationService) Register(ctx context.Context, user *userModal.User, auth *userModal.Authentication) error {
 
 	err := ser.validateUser(user)
 	if err != nil {
@@ -47,67 +59,138 @@ func (ser *authenticationService) Register(user *userModal.User, auth *userModal
 	}
 
 	user.Password = string(password)
+	user.IsVerified = false
+	user.VerificationSentAt = nil
+	user.IsTenantAdmin = false
 
-	uow := repository.NewUnitOfWork(ser.db)
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
 	defer uow.RollBack()
 
+	// users registering on their own belong to the default tenant.
+	user.TenantID, err = defaultTenantID(uow, ser.repo)
+	if err != nil {
+		return err
+	}
+
 	err = ser.repo.Add(uow, user)
 	if err != nil {
 		return err
 	}
 
+	err = createPersonalBudget(uow, ser.repo, user.ID, user.TenantID)
+	if err != nil {
+		return err
+	}
+
 	auth.UserID = user.ID
 	auth.Name = user.Name
 	auth.Email = user.Email
 
-	err = ser.auth.GenerateLoginToken(auth)
+	// unverified users get tokens only after verifying email when verification is required.
+	if !ser.verificationService.IsVerificationRequired() {
+		err = ser.tokenService.IssueTokens(uow, auth)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = uow.Commit()
 	if err != nil {
 		return err
 	}
 
-	uow.Commit()
+	// registration is not undone if email could not be sent, user can ask to resend it.
+	err = ser.verificationService.SendVerificationEmail(ctx, user.ID)
+	if err != nil {
+		log.GetLogger().Error(err)
+	}
 	return nil
 }
 
 // Login will verify user details and login into the system
-func (ser *authenticationService) Login(login *userModal.Login, auth *userModal.Authentication) error {
+func (ser *authenticationService) Login(ctx context.Context, login *userModal.Login, auth *userModal.Authentication) error {
 
-	uow := repository.NewUnitOfWork(ser.db)
+	err := ser.loginLimiter.Check(ctx, login.Username, auth.IPAddress)
+	if err != nil {
+		return err
+	}
+
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
 	defer uow.RollBack()
 
 	user := userModal.User{}
-	err := ser.repo.GetRecord(uow, &user, repository.Filter("users.`username` = ?", login.Username))
-	if err != nil {
+	err = ser.repo.GetRecord(uow, &user, repository.Filter("users.`username` = ? AND users.`deleted_at` IS NULL",
+		login.Username))
+	if err != nil && err != gorm.ErrRecordNotFound {
 		return err
 	}
 
-	err = security.ComparePassword(user.Password, login.Password)
+	// unknown username takes same time and gets same response as wrong password.
+	if err == gorm.ErrRecordNotFound {
+		security.ComparePasswordWithDummy(login.Password)
+	} else {
+		err = security.ComparePassword(user.Password, login.Password)
+	}
 	if err != nil {
+		failureErr := ser.loginLimiter.RecordFailure(ctx, login.Username, auth.IPAddress)
+		if failureErr != nil {
+			return failureErr
+		}
 		return errors.NewValidationError("Invalid username or password.")
 	}
 
+	err = ser.loginLimiter.RecordSuccess(ctx, login.Username)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsVerified && ser.verificationService.IsVerificationRequired() {
+		return errors.NewValidationError("Please verify your email before logging in.")
+	}
+
+	err = completeLogin(uow, ser.auth, ser.tokenService, &user, auth)
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// completeLogin will issue tokens to the user whose identity is verified. When two factor
+// authentication is enabled only challenge token is issued, tokens are issued by two factor login.
+func completeLogin(uow *repository.UnitOfWork, jwtAuth *security.Authentication, tokenService TokenService,
+	user *userModal.User, auth *userModal.Authentication) error {
+
+	if user.DisabledAt != nil {
+		return errors.NewValidationError("Your account has been disabled.")
+	}
+
 	auth.UserID = user.ID
 	auth.Name = user.Name
 	auth.Email = user.Email
 
-	err = ser.auth.GenerateLoginToken(auth)
-	if err != nil {
+	if user.IsTwoFactorEnabled {
+		var err error
+		auth.TwoFactorRequired = true
+		auth.ChallengeToken, err = jwtAuth.GeneratePurposeToken(&userModal.Claims{
+			UserID:  user.ID,
+			Purpose: userModal.TokenPurposeTwoFactor,
+		}, twoFactorChallengeTTL)
 		return err
 	}
 
-	uow.Commit()
-	return nil
+	return tokenService.IssueTokens(uow, auth)
 }
 
 // GetUser will fetch specified user details.
-func (ser *authenticationService) GetUser(user *userModal.UserDTO) error {
+func (ser *authenticationService) GetUser(ctx context.Context, user *userModal.UserDTO) error {
 
-	err := ser.validateUserID(user.ID)
+	err := ser.validateUserID(ctx, user.ID)
 	if err != nil {
 		return err
 	}
 
-	uow := repository.NewUnitOfWork(ser.db)
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
 	defer uow.RollBack()
 
 	err = ser.repo.GetRecord(uow, user, repository.Filter("users.`id` = ?", user.ID))
@@ -115,14 +198,13 @@ func (ser *authenticationService) GetUser(user *userModal.UserDTO) error {
 		return err
 	}
 
-	uow.Commit()
-	return nil
+	return uow.Commit()
 }
 
 // UpdateUser will update user details.
-func (ser *authenticationService) UpdateUser(user *userModal.User) error {
+func (ser *authenticationService) UpdateUser(ctx context.Context, user *userModal.User) error {
 
-	err := ser.validateUserID(user.ID)
+	err := ser.validateUserID(ctx, user.ID)
 	if err != nil {
 		return err
 	}
@@ -132,33 +214,86 @@ func (ser *authenticationService) UpdateUser(user *userModal.User) error {
 		return err
 	}
 
-	uow := repository.NewUnitOfWork(ser.db)
+	uow := repository.NewUnitOfWork(ser.db.WithContext(ctx))
 	defer uow.RollBack()
 
 	tempUser := userModal.User{}
 
 	err = ser.repo.GetRecord(uow, &tempUser, repository.Filter("users.`id` = ?", user.ID),
-		repository.Select("`created_at`, `password`"))
+		repository.Select("`created_at`, `password`, `email`, `is_verified`, `verification_sent_at`,"+
+			" `totp_secret`, `totp_last_counter`, `is_two_factor_enabled`, `is_tenant_admin`, `role`, `disabled_at`,"+
+			" `profile_image`, `profile_image_key`, `profile_image_type`"))
 	if err != nil {
 		return err
 	}
 
-	// user.CreatedBy = tempUser.CreatedBy
+	user.CreatedAt = tempUser.CreatedAt
 	user.Password = tempUser.Password
+	user.IsVerified = tempUser.IsVerified
+	user.VerificationSentAt = tempUser.VerificationSentAt
+	user.TOTPSecret = tempUser.TOTPSecret
+	user.TOTPLastCounter = tempUser.TOTPLastCounter
+	user.IsTwoFactorEnabled = tempUser.IsTwoFactorEnabled
+	user.IsTenantAdmin = tempUser.IsTenantAdmin
+	user.Role = tempUser.Role
+	user.DisabledAt = tempUser.DisabledAt
+
+	// profile image can only be changed by uploading it.
+	user.ProfileImage = tempUser.ProfileImage
+	user.ProfileImageKey = tempUser.ProfileImageKey
+	user.ProfileImageType = tempUser.ProfileImageType
+
+	// changed email has to be verified again.
+	emailChanged := !strings.EqualFold(user.Email, tempUser.Email)
+	if emailChanged {
+		user.IsVerified = false
+		user.VerificationSentAt = nil
+	}
+
+	err = ser.repo.Save(uow, user)
+	if err != nil {
+		return err
+	}
 
-	err = ser.repo.Save(uow, &user)
+	err = uow.Commit()
 	if err != nil {
 		return err
 	}
 
-	uow.Commit()
+	if emailChanged {
+		err = ser.verificationService.SendVerificationEmail(ctx, user.ID)
+		if err != nil {
+			log.GetLogger().Error(err)
+		}
+	}
 	return nil
 }
 
+// createPersonalBudget will create personal budget of new user with user as its owner.
+func createPersonalBudget(uow *repository.UnitOfWork, repo repository.Repository, userID, tenantID uuid.UUID) error {
+
+	budget := budgetModel.Budget{
+		Name:       budgetModel.PersonalBudgetName,
+		IsPersonal: true,
+		TenantID:   tenantID,
+	}
+
+	err := repo.Add(uow, &budget)
+	if err != nil {
+		return err
+	}
+
+	return repo.Add(uow, &budgetModel.BudgetMember{
+		BudgetID: budget.ID,
+		UserID:   userID,
+		Role:     budgetModel.RoleOwner,
+	})
+}
+
 // validateUserID will verify if userID exist or not.
-func (ser *authenticationService) validateUserID(userID uuid.UUID) error {
+func (ser *authenticationService) validateUserID(ctx context.Context, userID uuid.UUID) error {
 
-	exist, err := repository.DoesRecordExist(ser.db, userModal.User{},
+	exist, err := repository.DoesRecordExist(ser.db.WithContext(ctx), userModal.User{},
 		repository.Filter("users.`id` = ?", userID))
 	if err != nil {
 		return err
@@ -169,7 +304,8 @@ func (ser *authenticationService) validateUserID(userID uuid.UUID) error {
 	return nil
 }
 
-// validateUer will check if it is unique user.
+// validateUer will check if it is unique user. Email and username are unique across tenants,
+// so it is not scoped to tenant of the request.
 func (ser *authenticationService) validateUser(user *userModal.User) error {
 	exist, err := repository.DoesRecordExist(ser.db, userModal.User{},
 		repository.Filter("users.`id` != ? AND users.`email` = ?"+
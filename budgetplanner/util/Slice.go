@@ -1,5 +1,7 @@
 package util
 
+import "github.com/google/uuid"
+
 // AddToSlice adds values to their respective slices,
 // eg:- columnName will be added to columnNames
 func AddToSlice(columnName string, condition string, operator string, value interface{},
@@ -12,3 +14,17 @@ func AddToSlice(columnName string, condition string, operator string, value inte
 	*conditions = append(*conditions, condition)
 	*values = append(*values, value)
 }
+
+// UniqueUUIDs returns ids without duplicates, keeping the order of their first occurrence.
+func UniqueUUIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
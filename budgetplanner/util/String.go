@@ -0,0 +1,16 @@
+package util
+
+import "unicode/utf8"
+
+// Truncate will shorten value to at most maxBytes bytes without splitting a character.
+func Truncate(value string, maxBytes int) string {
+	if len(value) <= maxBytes {
+		return value
+	}
+
+	// cut is moved back to start of the character it falls in.
+	for maxBytes > 0 && !utf8.RuneStart(value[maxBytes]) {
+		maxBytes--
+	}
+	return value[:maxBytes]
+}
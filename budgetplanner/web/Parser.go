@@ -33,6 +33,20 @@ func (p *Parser) GetUUID(paramName string) (uuid.UUID, error) {
 	return uuid.Parse(param)
 }
 
+// GetInt will get int from the given paramName in URL params.
+func (p *Parser) GetInt(paramName string) (int, error) {
+	param, ok := p.Params.Get(paramName)
+	if !ok {
+		return 0, errors.NewValidationError(paramName + " not found")
+	}
+
+	value, err := strconv.Atoi(param)
+	if err != nil {
+		return 0, errors.NewValidationError(paramName + " must be a number")
+	}
+	return value, nil
+}
+
 // GetParameter will get parameter from the given paramName in URL params.
 func (p *Parser) GetParameter(paramName string) string {
 	paramString, ok := p.Params.Get(paramName)
@@ -7,9 +7,13 @@ import (
 	"sync"
 	"syscall"
 
+	// timezones of users are loaded from embedded database so the image does not need zoneinfo.
+	_ "time/tzdata"
+
 	"github.com/shaileshhb/budget-planner-go/budgetplanner"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/db"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/encryption"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
@@ -30,6 +34,12 @@ func main() {
 	// creates new instance of Config
 	envconfig := config.NewConfig(isAppInProduction)
 
+	// loads keys used to encrypt sensitive columns
+	err := encryption.Configure(envconfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Create New Instace of DB
 	db := db.NewDBConnection(log, envconfig)
 	if db == nil {
@@ -56,7 +66,16 @@ func main() {
 	module.CreateRouterInstance(app, repository)
 	module.Configure(app)
 
-	err := app.Start()
+	// re-encrypts sensitive columns with the active key and exits when run as "budget-planner reencrypt".
+	if len(os.Args) > 1 && os.Args[1] == "reencrypt" {
+		err = module.ReEncrypt(app)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	err = app.Start()
 	if err != nil {
 		log.Fatal(err)
 		stopApp(app)
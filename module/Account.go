@@ -0,0 +1,29 @@
+package module
+
+import (
+	"github.com/shaileshhb/budget-planner-go/budgetplanner"
+	accountcontroller "github.com/shaileshhb/budget-planner-go/budgetplanner/account/controller"
+	accountservice "github.com/shaileshhb/budget-planner-go/budgetplanner/account/service"
+	auditservice "github.com/shaileshhb/budget-planner-go/budgetplanner/audit/service"
+	budgetservice "github.com/shaileshhb/budget-planner-go/budgetplanner/budget/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	userservice "github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
+)
+
+// registerAccountRoutes will register all routes of accounts.
+func registerAccountRoutes(app *budgetplanner.App, repo repository.Repository) {
+	defer app.WG.Done()
+
+	changeLogger := auditservice.NewChangeLogger(app.DB, repo)
+	membershipService := budgetservice.NewMembershipService(app.DB, repo)
+	preferenceService := userservice.NewPreferenceService(app.DB, repo)
+
+	accountService := accountservice.NewAccountService(app.DB, repo, app.Auth, changeLogger, membershipService)
+	accountController := accountcontroller.NewAccountController(accountService, app.Log, app.Auth)
+
+	reconciliationService := accountservice.NewReconciliationService(app.DB, repo, app.Auth, changeLogger,
+		membershipService, preferenceService)
+	reconciliationController := accountcontroller.NewReconciliationController(reconciliationService, app.Log, app.Auth)
+
+	app.RegisterControllerRoutes([]budgetplanner.Controller{accountController, reconciliationController})
+}
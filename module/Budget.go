@@ -0,0 +1,31 @@
+package module
+
+import (
+	"time"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner"
+	budgetcontroller "github.com/shaileshhb/budget-planner-go/budgetplanner/budget/controller"
+	budgetservice "github.com/shaileshhb/budget-planner-go/budgetplanner/budget/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/email"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+)
+
+// registerBudgetRoutes will register all routes of budgets.
+func registerBudgetRoutes(app *budgetplanner.App, repo repository.Repository) {
+	defer app.WG.Done()
+
+	membershipService := budgetservice.NewMembershipService(app.DB, repo)
+
+	budgetService := budgetservice.NewBudgetService(app.DB, repo, app.Auth, membershipService)
+	budgetController := budgetcontroller.NewBudgetController(budgetService, app.Log, app.Auth)
+
+	inviteService := budgetservice.NewInviteService(app.DB, repo, app.Auth, app.Config, email.NewSender(app.Config),
+		membershipService)
+	inviteController := budgetcontroller.NewInviteController(inviteService, app.Log, app.Auth)
+
+	app.RegisterControllerRoutes([]budgetplanner.Controller{budgetController, inviteController})
+
+	app.ScheduleJobs([]budgetplanner.Job{
+		{Name: "Budget invites cleanup", Interval: time.Hour, Run: inviteService.PurgeExpired},
+	})
+}
@@ -1,15 +1,30 @@
 package module
 
 import (
+	"strings"
+
 	"github.com/shaileshhb/budget-planner-go/budgetplanner"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/account"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/audit"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/envelop"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/privacy"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
 )
 
 // Configure will migrate all the tables.
 func Configure(app *budgetplanner.App) {
 	userModule := user.NewUserModuleConfig(app.DB)
+	budgetModule := budget.NewBudgetModuleConfig(app.DB)
+	accountModule := account.NewAccountModuleConfig(app.DB)
 	envelopModule := envelop.NewEnvelopModuleConfig(app.DB)
+	auditModule := audit.NewAuditModuleConfig(app.DB)
+	privacyModule := privacy.NewPrivacyModuleConfig(app.DB)
+	personalBudgetMigration := budget.NewPersonalBudgetMigration(app.DB)
+	defaultTenantMigration := user.NewDefaultTenantMigration(app.DB)
+	adminMigration := user.NewAdminMigration(app.DB, strings.Split(app.Config.GetString(config.AdminEmails), ","))
 
-	app.MigrateTables([]budgetplanner.ModuleConfig{userModule, envelopModule})
+	app.MigrateTables([]budgetplanner.ModuleConfig{userModule, budgetModule, accountModule, envelopModule,
+		auditModule, privacyModule, personalBudgetMigration, defaultTenantMigration, adminMigration})
 }
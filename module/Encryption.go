@@ -0,0 +1,26 @@
+package module
+
+import (
+	"github.com/shaileshhb/budget-planner-go/budgetplanner"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/encryption"
+)
+
+// encryptedTables contains every column which is stored as encryption.String.
+var encryptedTables = []encryption.Table{
+	{Name: "users", Columns: []string{"date_of_birth", "contact", "totp_secret"}},
+	{Name: "accounts", Columns: []string{"number"}},
+	{Name: "transactions", Columns: []string{"description"}},
+	{Name: "change_logs", Columns: []string{"changes", "snapshot"}},
+}
+
+// ReEncrypt will encrypt all sensitive columns with the active encryption key.
+// It is run after a new key is added and before the old key is removed.
+func ReEncrypt(app *budgetplanner.App) error {
+	count, err := encryption.ReEncrypt(app.DB, encryptedTables)
+	if err != nil {
+		return err
+	}
+
+	app.Log.Infof("Re-encryption completed, %d rows updated", count)
+	return nil
+}
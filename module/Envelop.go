@@ -1,21 +1,49 @@
 package module
 
 import (
+	"time"
+
 	"github.com/shaileshhb/budget-planner-go/budgetplanner"
+	auditservice "github.com/shaileshhb/budget-planner-go/budgetplanner/audit/service"
+	budgetservice "github.com/shaileshhb/budget-planner-go/budgetplanner/budget/service"
 	envelopcontroller "github.com/shaileshhb/budget-planner-go/budgetplanner/envelop/controller"
 	envelopservice "github.com/shaileshhb/budget-planner-go/budgetplanner/envelop/service"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/storage"
+	userservice "github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
 )
 
 // registerEnvelopRoutes will register all routes of envelops.
 func registerEnvelopRoutes(app *budgetplanner.App, repo repository.Repository) {
 	defer app.WG.Done()
 
-	envelopService := envelopservice.NewEnvelopService(app.DB, repo, app.Auth)
+	changeLogger := auditservice.NewChangeLogger(app.DB, repo)
+	membershipService := budgetservice.NewMembershipService(app.DB, repo)
+	preferenceService := userservice.NewPreferenceService(app.DB, repo)
+	store := storage.NewStorage(app.Config)
+
+	envelopService := envelopservice.NewEnvelopService(app.DB, repo, app.Auth, changeLogger, membershipService,
+		preferenceService)
 	enevlopController := envelopcontroller.NewEnvelopController(envelopService, app.Log, app.Auth)
 
-	transactionService := envelopservice.NewTransactionService(app.DB, repo, app.Auth)
+	transactionService := envelopservice.NewTransactionService(app.DB, repo, app.Auth, changeLogger, membershipService,
+		preferenceService)
 	transactionController := envelopcontroller.NewTransactionController(transactionService, app.Log, app.Auth)
 
-	app.RegisterControllerRoutes([]budgetplanner.Controller{enevlopController, transactionController})
+	goalService := envelopservice.NewGoalService(app.DB, repo, membershipService, preferenceService)
+	goalController := envelopcontroller.NewGoalController(goalService, app.Log, app.Auth)
+
+	attachmentService := envelopservice.NewAttachmentService(app.DB, repo, app.Config, store, membershipService)
+	attachmentController := envelopcontroller.NewAttachmentController(attachmentService, app.Log, app.Auth)
+
+	trashService := envelopservice.NewTrashService(app.DB, repo, app.Auth, app.Config, store, changeLogger,
+		membershipService)
+	trashController := envelopcontroller.NewTrashController(trashService, app.Log, app.Auth)
+
+	app.RegisterControllerRoutes([]budgetplanner.Controller{enevlopController, transactionController,
+		goalController, attachmentController, trashController})
+
+	app.ScheduleJobs([]budgetplanner.Job{
+		{Name: "Trash retention", Interval: time.Hour, Run: trashService.PurgeExpired},
+	})
 }
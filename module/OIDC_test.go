@@ -0,0 +1,325 @@
+package module
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+)
+
+const (
+	mockClientID    = "budget-planner"
+	mockRedirectURL = "http://localhost/oidc/mock/callback"
+	mockKeyID       = "mock-key"
+)
+
+// mockIssuer is a local OIDC provider which issues id tokens signed by sign for codes it has handed out.
+type mockIssuer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mutex sync.Mutex
+	codes map[string]mockCode
+}
+
+// mockCode is an authorization code along with PKCE challenge it was issued for and its id token.
+type mockCode struct {
+	challenge string
+	idToken   string
+}
+
+// newMockIssuer will start mock OIDC provider serving discovery, JWKS and token endpoints.
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuer := &mockIssuer{key: key, codes: map[string]mockCode{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 issuer.URL,
+			"authorization_endpoint": issuer.URL + "/authorize",
+			"token_endpoint":         issuer.URL + "/token",
+			"jwks_uri":               issuer.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": mockKeyID,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", issuer.token)
+
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+// token will exchange code for its id token after verifying client and PKCE code verifier.
+func (issuer *mockIssuer) token(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil || r.Method != http.MethodPost || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, _, ok := r.BasicAuth()
+	if !ok || clientID != mockClientID || r.PostForm.Get("redirect_uri") != mockRedirectURL {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	issuer.mutex.Lock()
+	code, ok := issuer.codes[r.PostForm.Get("code")]
+	delete(issuer.codes, r.PostForm.Get("code"))
+	issuer.mutex.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(verifier[:]) != code.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"id_token": code.idToken, "token_type": "Bearer"})
+}
+
+// issueCode will hand out code for the PKCE challenge which is exchanged for idToken.
+func (issuer *mockIssuer) issueCode(challenge, idToken string) string {
+	code := uuid.NewString()
+
+	issuer.mutex.Lock()
+	issuer.codes[code] = mockCode{challenge: challenge, idToken: idToken}
+	issuer.mutex.Unlock()
+	return code
+}
+
+// claims will return valid id token claims of the subject for the nonce.
+func (issuer *mockIssuer) claims(subject, email, nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            issuer.URL,
+		"sub":            subject,
+		"aud":            mockClientID,
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          nonce,
+		"email":          email,
+		"email_verified": true,
+		"name":           subject,
+	}
+}
+
+// sign will sign claims with the key published in JWKS.
+func (issuer *mockIssuer) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = mockKeyID
+
+	signed, err := token.SignedString(issuer.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// writeJSON will write value as json response.
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// newOIDCTestApp will create app with the mock issuer configured as provider named mock.
+func newOIDCTestApp(t *testing.T, issuer *mockIssuer) *budgetplanner.App {
+	t.Helper()
+
+	t.Setenv("OIDC_PROVIDERS", "mock")
+	t.Setenv("OIDC_MOCK_ISSUER", issuer.URL)
+	t.Setenv("OIDC_MOCK_CLIENT_ID", mockClientID)
+	t.Setenv("OIDC_MOCK_CLIENT_SECRET", "mock-secret")
+	t.Setenv("OIDC_MOCK_REDIRECT_URL", mockRedirectURL)
+
+	return newTestApp(t)
+}
+
+// loginWithProvider will start login with the mock provider, let sign build id token for the nonce
+// sent to provider and complete login with the issued code. It returns status of the callback.
+func loginWithProvider(t *testing.T, app *budgetplanner.App, issuer *mockIssuer,
+	sign func(nonce string) string, auth *userModel.Authentication) int {
+	t.Helper()
+
+	authorization := userModel.OIDCAuthorization{}
+	status := request(t, app, http.MethodGet, "/oidc/mock/authorize", "", nil, &authorization)
+	if status != http.StatusOK {
+		t.Fatalf("authorize: status %d", status)
+	}
+
+	authorizationURL, err := url.Parse(authorization.AuthorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := authorizationURL.Query()
+	if authorizationURL.Path != "/authorize" || query.Get("client_id") != mockClientID ||
+		query.Get("redirect_uri") != mockRedirectURL || query.Get("response_type") != "code" ||
+		query.Get("code_challenge_method") != "S256" || len(query.Get("code_challenge")) == 0 {
+		t.Fatalf("unexpected authorization url %s", authorization.AuthorizationURL)
+	}
+
+	code := issuer.issueCode(query.Get("code_challenge"), sign(query.Get("nonce")))
+
+	var out interface{}
+	if auth != nil {
+		out = auth
+	}
+
+	return request(t, app, http.MethodPost, "/oidc/mock/callback", "", map[string]string{
+		"code":  code,
+		"state": query.Get("state"),
+	}, out)
+}
+
+// TestOIDCLogin verifies login with a mock OIDC provider, along with the id tokens which must be rejected.
+func TestOIDCLogin(t *testing.T) {
+	issuer := newMockIssuer(t)
+	app := newOIDCTestApp(t, issuer)
+
+	auth := userModel.Authentication{}
+	status := loginWithProvider(t, app, issuer, func(nonce string) string {
+		return issuer.sign(t, issuer.claims("carol-subject", "carol@example.com", nonce))
+	}, &auth)
+	if status != http.StatusOK || len(auth.Token) == 0 {
+		t.Fatalf("login of new user: status %d", status)
+	}
+
+	user := snapshot(t, app, "users", auth.UserID)
+	if user["email"] != "carol@example.com" || user["is_verified"] != int64(1) {
+		t.Errorf("user created for identity is %v", user)
+	}
+
+	// identity already linked logs in the same user.
+	again := userModel.Authentication{}
+	status = loginWithProvider(t, app, issuer, func(nonce string) string {
+		return issuer.sign(t, issuer.claims("carol-subject", "carol@example.com", nonce))
+	}, &again)
+	if status != http.StatusOK || again.UserID != auth.UserID {
+		t.Errorf("login of linked user: status %d, user %s instead of %s", status, again.UserID, auth.UserID)
+	}
+
+	tests := map[string]func(nonce string) string{
+		"bad nonce": func(nonce string) string {
+			return issuer.sign(t, issuer.claims("dave-subject", "dave@example.com", "other-nonce"))
+		},
+		"wrong audience": func(nonce string) string {
+			claims := issuer.claims("dave-subject", "dave@example.com", nonce)
+			claims["aud"] = "another-client"
+			return issuer.sign(t, claims)
+		},
+		"wrong issuer": func(nonce string) string {
+			claims := issuer.claims("dave-subject", "dave@example.com", nonce)
+			claims["iss"] = "https://attacker.example.com"
+			return issuer.sign(t, claims)
+		},
+		"expired": func(nonce string) string {
+			claims := issuer.claims("dave-subject", "dave@example.com", nonce)
+			claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			return issuer.sign(t, claims)
+		},
+		"HMAC signature": func(nonce string) string {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, issuer.claims("dave-subject", "dave@example.com", nonce))
+			signed, err := token.SignedString([]byte("mock-secret"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return signed
+		},
+		"none signature": func(nonce string) string {
+			token := jwt.NewWithClaims(jwt.SigningMethodNone, issuer.claims("dave-subject", "dave@example.com", nonce))
+			signed, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return signed
+		},
+		"unverified email": func(nonce string) string {
+			claims := issuer.claims("dave-subject", "dave@example.com", nonce)
+			claims["email_verified"] = false
+			return issuer.sign(t, claims)
+		},
+	}
+
+	for name, sign := range tests {
+		status := loginWithProvider(t, app, issuer, sign, nil)
+		if status != http.StatusUnauthorized {
+			t.Errorf("%s: expected status %d, got %d", name, http.StatusUnauthorized, status)
+		}
+	}
+
+	var count int64
+	app.DB.Table("users").Where("`email` = ?", "dave@example.com").Count(&count)
+	if count != 0 {
+		t.Errorf("user was created for rejected id tokens")
+	}
+}
+
+// TestOIDCLinkByVerifiedEmail verifies that identity with verified email is linked to the user having that email,
+// and that unverified email of provider doesn't give access to the user.
+func TestOIDCLinkByVerifiedEmail(t *testing.T) {
+	issuer := newMockIssuer(t)
+	app := newOIDCTestApp(t, issuer)
+
+	userA := register(t, app, "alice")
+
+	status := loginWithProvider(t, app, issuer, func(nonce string) string {
+		claims := issuer.claims("mallory-subject", "alice@example.com", nonce)
+		claims["email_verified"] = "false"
+		return issuer.sign(t, claims)
+	}, nil)
+	if status != http.StatusUnauthorized {
+		t.Errorf("unverified email: expected status %d, got %d", http.StatusUnauthorized, status)
+	}
+
+	auth := userModel.Authentication{}
+	status = loginWithProvider(t, app, issuer, func(nonce string) string {
+		return issuer.sign(t, issuer.claims("alice-subject", "alice@example.com", nonce))
+	}, &auth)
+	if status != http.StatusOK || auth.UserID != userA.ID {
+		t.Fatalf("verified email: status %d, user %s instead of %s", status, auth.UserID, userA.ID)
+	}
+
+	identities := []map[string]interface{}{}
+	app.DB.Table("user_identities").Where("`user_id` = ?", userA.ID).Find(&identities)
+	if len(identities) != 1 || identities[0]["subject"] != "alice-subject" || identities[0]["provider"] != "mock" {
+		t.Errorf("identities of alice are %v", identities)
+	}
+
+	status = request(t, app, http.MethodGet, fmt.Sprintf("/users/%s", userA.ID), auth.Token, nil, nil)
+	if status != http.StatusOK {
+		t.Errorf("token issued for linked user: status %d", status)
+	}
+}
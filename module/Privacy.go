@@ -0,0 +1,30 @@
+package module
+
+import (
+	"time"
+
+	"github.com/shaileshhb/budget-planner-go/budgetplanner"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/email"
+	privacycontroller "github.com/shaileshhb/budget-planner-go/budgetplanner/privacy/controller"
+	privacyservice "github.com/shaileshhb/budget-planner-go/budgetplanner/privacy/service"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/storage"
+)
+
+// registerPrivacyRoutes will register all routes of account deletion.
+func registerPrivacyRoutes(app *budgetplanner.App, repo repository.Repository) {
+	defer app.WG.Done()
+
+	sender := email.NewSender(app.Config)
+	store := storage.NewStorage(app.Config)
+
+	accountDeletionService := privacyservice.NewAccountDeletionService(app.DB, repo, app.Config, sender, store)
+	accountDeletionController := privacycontroller.NewAccountDeletionController(accountDeletionService,
+		app.Log, app.Auth)
+
+	app.RegisterControllerRoutes([]budgetplanner.Controller{accountDeletionController})
+
+	app.ScheduleJobs([]budgetplanner.Job{
+		{Name: "Account erasure", Interval: time.Hour, Run: accountDeletionService.EraseDue},
+	})
+}
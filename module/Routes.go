@@ -14,10 +14,13 @@ func CreateRouterInstance(app *budgetplanner.App, repository repository.Reposito
 
 	app.InitializeRouter()
 
-	app.WG.Add(2)
+	app.WG.Add(5)
 
 	go registerUserRoutes(app, repository)
+	go registerBudgetRoutes(app, repository)
 	go registerEnvelopRoutes(app, repository)
+	go registerAccountRoutes(app, repository)
+	go registerPrivacyRoutes(app, repository)
 
 	app.WG.Wait()
 }
@@ -0,0 +1,416 @@
+package module
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/config"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/encryption"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/log"
+	accountModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/account"
+	auditModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/audit"
+	budgetModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/budget"
+	envelopModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/envelop"
+	userModel "github.com/shaileshhb/budget-planner-go/budgetplanner/models/user"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/security"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const apiPrefix = "/api/v1/budget-planner"
+
+// testUser is a registered user along with the token issued to them.
+type testUser struct {
+	ID       uuid.UUID
+	Token    string
+	BudgetID uuid.UUID
+}
+
+// newTestApp will create app with every route registered, backed by a fresh sqlite database.
+func newTestApp(t *testing.T) *budgetplanner.App {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	t.Setenv(string(config.JWTKey), "test-jwt-key")
+	t.Setenv(string(config.ENCRYPTION_KEY), "test-encryption-key")
+	t.Setenv(string(config.StorageLocalDir), t.TempDir())
+
+	conf := config.NewConfig(false)
+
+	err := encryption.Configure(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)" +
+		"&_pragma=journal_mode(WAL)"
+
+	db, err := gorm.Open(testDialector{&sqlite.Dialector{DSN: dsn}}, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefixIndexNames(db)
+	t.Cleanup(func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	repo := repository.NewGormRepository()
+	app := budgetplanner.NewApp("test", db, log.GetLogger(), conf, &sync.WaitGroup{},
+		security.NewAuthentication(db, conf), false, repo)
+
+	CreateRouterInstance(app, repo)
+	Configure(app)
+	return app
+}
+
+// testDialector is sqlite dialector whose migrator only creates missing tables, as altering tables
+// created for MySQL is not supported by sqlite.
+type testDialector struct {
+	*sqlite.Dialector
+}
+
+// Migrator returns migrator which creates missing tables.
+func (dialector testDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return testMigrator{dialector.Dialector.Migrator(db)}
+}
+
+// testMigrator creates tables which don't exist and leaves existing tables untouched.
+type testMigrator struct {
+	gorm.Migrator
+}
+
+// AutoMigrate will create tables of values which don't exist.
+func (m testMigrator) AutoMigrate(values ...interface{}) error {
+	for _, value := range values {
+		if m.HasTable(value) {
+			continue
+		}
+
+		err := m.CreateTable(value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prefixIndexNames will prefix names of indexes with their table when they are created,
+// as index names are unique per table in MySQL but unique per database in sqlite.
+func prefixIndexNames(db *gorm.DB) {
+	createIndex := regexp.MustCompile("^CREATE (UNIQUE )?INDEX `([^`]+)` ON `([^`]+)`")
+
+	db.Callback().Raw().Before("gorm:raw").Register("test:prefix_index", func(tx *gorm.DB) {
+		sql := tx.Statement.SQL.String()
+		if match := createIndex.FindStringSubmatch(sql); match != nil {
+			tx.Statement.SQL.Reset()
+			tx.Statement.SQL.WriteString(strings.Replace(sql, "`"+match[2]+"`", "`"+match[3]+"_"+match[2]+"`", 1))
+		}
+	})
+}
+
+// register will register user through the API and return their token and personal budget.
+func register(t *testing.T, app *budgetplanner.App, name string) testUser {
+	t.Helper()
+
+	auth := userModel.Authentication{}
+	status := request(t, app, http.MethodPost, "/register", "", map[string]interface{}{
+		"name":     name,
+		"username": name,
+		"email":    name + "@example.com",
+		"password": "Secret@1234",
+	}, &auth)
+	if status != http.StatusOK {
+		t.Fatalf("register %s: status %d", name, status)
+	}
+
+	var budgets []budgetModel.BudgetDTO
+	status = request(t, app, http.MethodGet, fmt.Sprintf("/users/%s/budgets", auth.UserID), auth.Token, nil, &budgets)
+	if status != http.StatusOK || len(budgets) != 1 {
+		t.Fatalf("budgets of %s: status %d, %d budgets", name, status, len(budgets))
+	}
+
+	return testUser{ID: auth.UserID, Token: auth.Token, BudgetID: budgets[0].ID}
+}
+
+// request will send request with token to the app and decode response into out when it is not nil.
+func request(t *testing.T, app *budgetplanner.App, method, path, token string, body, out interface{}) int {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		err := json.NewEncoder(&payload).Encode(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(method, apiPrefix+path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	recorder := httptest.NewRecorder()
+	app.Engine.ServeHTTP(recorder, req)
+
+	if out != nil && recorder.Code == http.StatusOK {
+		err := json.Unmarshal(recorder.Body.Bytes(), out)
+		if err != nil {
+			t.Fatalf("%s %s: %s", method, path, err)
+		}
+	}
+	return recorder.Code
+}
+
+// snapshot will read row of table as it is stored, deleted rows included.
+func snapshot(t *testing.T, app *budgetplanner.App, table string, id uuid.UUID) map[string]interface{} {
+	t.Helper()
+
+	row := map[string]interface{}{}
+	err := app.DB.Table(table).Where("`id` = ?", id).Take(&row).Error
+	if err != nil {
+		t.Fatalf("%s %s: %s", table, id, err)
+	}
+	return row
+}
+
+// TestCrossUserAccess verifies that token of one user can't be used to read or change
+// envelops, transactions and accounts of another user.
+func TestCrossUserAccess(t *testing.T) {
+	app := newTestApp(t)
+
+	userA := register(t, app, "alice")
+	userB := register(t, app, "bob")
+
+	budgetPathA := fmt.Sprintf("/users/%s/budgets/%s", userA.ID, userA.BudgetID)
+
+	status := request(t, app, http.MethodPost, budgetPathA+"/envelops", userA.Token, map[string]interface{}{
+		"name": "Groceries", "amount": 500,
+	}, nil)
+	if status != http.StatusCreated {
+		t.Fatalf("add envelop: status %d", status)
+	}
+
+	status = request(t, app, http.MethodPost, budgetPathA+"/accounts", userA.Token, map[string]interface{}{
+		"name": "Savings", "amount": 1000,
+	}, nil)
+	if status != http.StatusCreated {
+		t.Fatalf("add account: status %d", status)
+	}
+
+	var envelops []envelopModel.EnvelopDTO
+	request(t, app, http.MethodGet, budgetPathA+"/envelops", userA.Token, nil, &envelops)
+	var accounts []accountModel.AccountDTO
+	request(t, app, http.MethodGet, budgetPathA+"/accounts", userA.Token, nil, &accounts)
+	if len(envelops) != 1 || len(accounts) != 1 {
+		t.Fatalf("alice has %d envelops and %d accounts", len(envelops), len(accounts))
+	}
+
+	status = request(t, app, http.MethodPost, budgetPathA+"/transactions", userA.Token, map[string]interface{}{
+		"envelopID": envelops[0].ID, "accountID": accounts[0].ID, "payee": "Store", "amount": 50,
+		"date": "2026-10-01", "transactionType": envelopModel.TransactionTypeDebit,
+	}, nil)
+	if status != http.StatusCreated {
+		t.Fatalf("add transaction: status %d", status)
+	}
+
+	var transactions []envelopModel.TransactionDTO
+	request(t, app, http.MethodGet, budgetPathA+"/transactions", userA.Token, nil, &transactions)
+	if len(transactions) != 1 {
+		t.Fatalf("alice has %d transactions", len(transactions))
+	}
+
+	rows := map[string]uuid.UUID{
+		"envelops":     envelops[0].ID,
+		"accounts":     accounts[0].ID,
+		"transactions": transactions[0].ID,
+	}
+	before := map[string]map[string]interface{}{}
+	for table, id := range rows {
+		before[table] = snapshot(t, app, table, id)
+	}
+
+	changes := map[string]map[string]interface{}{
+		"envelops": {"name": "Stolen", "amount": 1},
+		"accounts": {"name": "Stolen", "amount": 1},
+		"transactions": {"envelopID": envelops[0].ID, "payee": "Stolen", "amount": 1, "date": "2026-10-02",
+			"transactionType": envelopModel.TransactionTypeCredit},
+	}
+
+	// bob's own path with alice's budget, bob's own budget with alice's records and alice's path.
+	paths := map[string]string{
+		"other budget":  fmt.Sprintf("/users/%s/budgets/%s", userB.ID, userA.BudgetID),
+		"own budget":    fmt.Sprintf("/users/%s/budgets/%s", userB.ID, userB.BudgetID),
+		"other user ID": budgetPathA,
+	}
+
+	for name, path := range paths {
+		for table, id := range rows {
+			attempts := []struct {
+				method string
+				path   string
+				body   interface{}
+			}{
+				{http.MethodGet, path + "/" + table, nil},
+				{http.MethodGet, fmt.Sprintf("%s/%s/%s/history", path, table, id), nil},
+				{http.MethodPut, fmt.Sprintf("%s/%s/%s", path, table, id), changes[table]},
+				{http.MethodDelete, fmt.Sprintf("%s/%s/%s?transactions=cascade", path, table, id), nil},
+			}
+
+			for _, attempt := range attempts {
+				var out []map[string]interface{}
+				status := request(t, app, attempt.method, attempt.path, userB.Token, attempt.body, &out)
+
+				switch {
+				case name == "other user ID" && status != http.StatusForbidden:
+					t.Errorf("%s %s: expected status %d, got %d", attempt.method, attempt.path, http.StatusForbidden, status)
+				case name == "own budget" && attempt.method == http.MethodGet && status == http.StatusOK:
+					if len(out) > 0 {
+						t.Errorf("%s %s: bob got %d records of alice", attempt.method, attempt.path, len(out))
+					}
+				case status < http.StatusBadRequest:
+					t.Errorf("%s %s: expected request to be rejected, got status %d", attempt.method, attempt.path, status)
+				}
+			}
+		}
+	}
+
+	for table, id := range rows {
+		if after := snapshot(t, app, table, id); !reflect.DeepEqual(before[table], after) {
+			t.Errorf("%s of alice changed from %v to %v", table, before[table], after)
+		}
+	}
+}
+
+// TestUserPathMismatch verifies that routes with :userID are rejected when it is not the subject of the token.
+func TestUserPathMismatch(t *testing.T) {
+	app := newTestApp(t)
+
+	userA := register(t, app, "alice")
+	userB := register(t, app, "bob")
+
+	before := snapshot(t, app, "users", userA.ID)
+
+	tests := []struct {
+		method string
+		path   string
+		body   interface{}
+		status int
+	}{
+		{http.MethodGet, fmt.Sprintf("/users/%s", userA.ID), nil, http.StatusForbidden},
+		{http.MethodPut, fmt.Sprintf("/users/%s", userA.ID), map[string]interface{}{"name": "Stolen"}, http.StatusForbidden},
+		{http.MethodGet, fmt.Sprintf("/users/%s/budgets", userA.ID), nil, http.StatusForbidden},
+		{http.MethodGet, "/users/not-a-uuid/budgets", nil, http.StatusForbidden},
+		{http.MethodGet, fmt.Sprintf("/users/%s/budgets", userB.ID), nil, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		status := request(t, app, test.method, test.path, userB.Token, test.body, nil)
+		if status != test.status {
+			t.Errorf("%s %s: expected status %d, got %d", test.method, test.path, test.status, status)
+		}
+	}
+
+	if after := snapshot(t, app, "users", userA.ID); !reflect.DeepEqual(before, after) {
+		t.Errorf("alice changed from %v to %v", before, after)
+	}
+}
+
+// TestImpersonationRestricted verifies that impersonation token can't be used to take over account of the user.
+func TestImpersonationRestricted(t *testing.T) {
+	app := newTestApp(t)
+
+	admin := register(t, app, "admin")
+	userA := register(t, app, "alice")
+
+	err := app.DB.Table("users").Where("`id` = ?", admin.ID).Update("role", userModel.RoleAdmin).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth := userModel.Authentication{}
+	status := request(t, app, http.MethodPost, fmt.Sprintf("/admin/users/%s/impersonate", userA.ID), admin.Token,
+		nil, &auth)
+	if status != http.StatusOK {
+		t.Fatalf("impersonate alice: status %d", status)
+	}
+
+	tenantID := snapshot(t, app, "users", userA.ID)["tenant_id"]
+	before := snapshot(t, app, "users", userA.ID)
+
+	tests := []struct {
+		method string
+		path   string
+		body   interface{}
+	}{
+		{http.MethodPut, fmt.Sprintf("/users/%s", userA.ID), map[string]interface{}{"email": "admin@example.com"}},
+		{http.MethodPost, fmt.Sprintf("/users/%s/api-tokens", userA.ID), map[string]interface{}{"name": "backdoor"}},
+		{http.MethodGet, fmt.Sprintf("/users/%s/api-tokens", userA.ID), nil},
+		{http.MethodPut, fmt.Sprintf("/users/%s/password", userA.ID), map[string]interface{}{"password": "Secret@5678"}},
+		{http.MethodPost, fmt.Sprintf("/users/%s/two-factor", userA.ID), nil},
+		{http.MethodGet, fmt.Sprintf("/users/%s/sessions", userA.ID), nil},
+		{http.MethodDelete, fmt.Sprintf("/users/%s/sessions", userA.ID), nil},
+		{http.MethodPost, fmt.Sprintf("/users/%s/deletion", userA.ID), nil},
+		{http.MethodGet, fmt.Sprintf("/tenants/%s", tenantID), nil},
+	}
+
+	for _, test := range tests {
+		status := request(t, app, test.method, test.path, auth.Token, test.body, nil)
+		if status != http.StatusForbidden {
+			t.Errorf("%s %s: expected status %d, got %d", test.method, test.path, http.StatusForbidden, status)
+		}
+	}
+
+	status = request(t, app, http.MethodPost, fmt.Sprintf("/users/%s/budgets/%s/envelops", userA.ID, userA.BudgetID),
+		auth.Token, map[string]interface{}{"name": "Groceries", "amount": 500}, nil)
+	if status != http.StatusCreated {
+		t.Fatalf("add envelop while impersonating: status %d", status)
+	}
+
+	changeLog := map[string]interface{}{}
+	err = app.DB.Table("change_logs").Where("`entity_type` = ?", auditModel.EntityEnvelop).Take(&changeLog).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changeLog["actor_id"] != userA.ID.String() || changeLog["impersonator_id"] != admin.ID.String() {
+		t.Errorf("change log of envelop names actor %v and impersonator %v", changeLog["actor_id"],
+			changeLog["impersonator_id"])
+	}
+
+	if after := snapshot(t, app, "users", userA.ID); !reflect.DeepEqual(before, after) {
+		t.Errorf("alice changed from %v to %v", before, after)
+	}
+}
+
+// TestResendVerificationEmail verifies that resending verification email does not reveal registered emails.
+func TestResendVerificationEmail(t *testing.T) {
+	app := newTestApp(t)
+
+	register(t, app, "alice")
+
+	// verification email was just sent to alice on registration, so resending it is throttled.
+	for _, address := range []string{"alice@example.com", "nobody@example.com"} {
+		status := request(t, app, http.MethodPost, "/verify-email/resend", "",
+			map[string]interface{}{"email": address}, nil)
+		if status != http.StatusOK {
+			t.Errorf("resend to %s: expected status %d, got %d", address, http.StatusOK, status)
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package module
 
 import (
+	"time"
+
 	"github.com/shaileshhb/budget-planner-go/budgetplanner"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/email"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/oidc"
 	"github.com/shaileshhb/budget-planner-go/budgetplanner/repository"
+	"github.com/shaileshhb/budget-planner-go/budgetplanner/storage"
 	usercontroller "github.com/shaileshhb/budget-planner-go/budgetplanner/user/controller"
 	userservice "github.com/shaileshhb/budget-planner-go/budgetplanner/user/service"
 )
@@ -10,8 +15,57 @@ import (
 func registerUserRoutes(app *budgetplanner.App, repo repository.Repository) {
 	defer app.WG.Done()
 
-	authService := userservice.NewAuthenticationService(app.DB, repo, app.Auth)
+	sender := email.NewSender(app.Config)
+	store := storage.NewStorage(app.Config)
+
+	tokenService := userservice.NewTokenService(app.DB, repo, app.Auth)
+	tokenController := usercontroller.NewTokenController(tokenService, app.Log, app.Auth)
+
+	verificationService := userservice.NewVerificationService(app.DB, repo, app.Auth, app.Config, sender)
+	verificationController := usercontroller.NewVerificationController(verificationService, app.Log, app.Auth)
+
+	passwordService := userservice.NewPasswordService(app.DB, repo, app.Auth, app.Config, sender, tokenService)
+	passwordController := usercontroller.NewPasswordController(passwordService, app.Log, app.Auth)
+
+	loginLimiter := userservice.NewLoginLimiter(app.DB, repo, app.Config)
+
+	authService := userservice.NewAuthenticationService(app.DB, repo, app.Auth, tokenService, verificationService,
+		loginLimiter)
 	authController := usercontroller.NewAuthenticationController(authService, app.Log, app.Auth)
 
-	app.RegisterControllerRoutes([]budgetplanner.Controller{authController})
+	twoFactorService := userservice.NewTwoFactorService(app.DB, repo, app.Auth, tokenService, loginLimiter)
+	twoFactorController := usercontroller.NewTwoFactorController(twoFactorService, app.Log, app.Auth)
+
+	apiTokenService := userservice.NewAPITokenService(app.DB, repo, app.Auth)
+	apiTokenController := usercontroller.NewAPITokenController(apiTokenService, app.Log, app.Auth)
+
+	oidcService := userservice.NewOIDCService(app.DB, repo, app.Auth, oidc.NewRegistry(app.Config), tokenService)
+	oidcController := usercontroller.NewOIDCController(oidcService, app.Log, app.Auth)
+
+	tenantService := userservice.NewTenantService(app.DB, repo, app.Auth, tokenService, verificationService)
+	tenantController := usercontroller.NewTenantController(tenantService, app.Log, app.Auth)
+
+	adminService := userservice.NewAdminService(app.DB, repo, app.Auth, tokenService, passwordService)
+	adminController := usercontroller.NewAdminController(adminService, app.Log, app.Auth)
+
+	profileImageService := userservice.NewProfileImageService(app.DB, repo, app.Config, store)
+	profileImageController := usercontroller.NewProfileImageController(profileImageService, app.Log, app.Auth)
+
+	preferenceService := userservice.NewPreferenceService(app.DB, repo)
+	preferenceController := usercontroller.NewPreferenceController(preferenceService, app.Log, app.Auth)
+
+	app.RegisterControllerRoutes([]budgetplanner.Controller{authController, tokenController,
+		verificationController, passwordController, twoFactorController, apiTokenController, oidcController,
+		tenantController, adminController, profileImageController, preferenceController})
+
+	keySetController := usercontroller.NewKeySetController(app.Log, app.Auth)
+	app.RegisterWellKnownRoutes([]budgetplanner.Controller{keySetController})
+
+	app.ScheduleJobs([]budgetplanner.Job{
+		{Name: "Token cleanup", Interval: time.Hour, Run: tokenService.PurgeExpired},
+		{Name: "Password reset cleanup", Interval: time.Hour, Run: passwordService.PurgeExpired},
+		{Name: "Login attempts cleanup", Interval: time.Hour, Run: loginLimiter.PurgeExpired},
+		{Name: "OIDC login cleanup", Interval: time.Hour, Run: oidcService.PurgeExpired},
+		{Name: "Signing keys reload", Interval: 5 * time.Minute, Run: app.Auth.ReloadSigningKeys},
+	})
 }